@@ -5,11 +5,13 @@ import "server/data/enum"
 type Attr struct {
 	Type enum.AttrType
 	Val  int64
-	Rate int64
+	Rate int64 // 百分比加成（万分比），由 AttrSet 计入最终值
 }
 
+// Attrs 为实体的初始属性列表，运行时属性见 AttrSet。
 type Attrs []*Attr
 
+// GetValue 获取属性的基础值（不含 Rate）。
 func (ss *Attrs) GetValue(ty enum.AttrType) int64 {
 	for _, attr := range *ss {
 		if attr.Type == ty {
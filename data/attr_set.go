@@ -0,0 +1,235 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"server/data/enum"
+	"server/lib/uid"
+)
+
+// AttrRatePrecision 为百分比类属性与修正值的精度（万分比）。
+const AttrRatePrecision = 10000
+
+// IAttrs 为只读的属性查询接口，*Attrs 与 *AttrSet 均实现该接口。
+type IAttrs interface {
+	GetValue(ty enum.AttrType) int64
+}
+
+var (
+	_ IAttrs = (*Attrs)(nil)
+	_ IAttrs = (*AttrSet)(nil)
+)
+
+// AttrModType 为属性修正的计算方式（与配表 BuffEffect.ModType 一致）。
+type AttrModType int32
+
+const (
+	AttrModType_Invalid  AttrModType = 0
+	AttrModType_Flat     AttrModType = 1 // 固定值加成
+	AttrModType_PctAdd   AttrModType = 2 // 百分比加成（万分比），同类之间相加
+	AttrModType_PctMul   AttrModType = 3 // 百分比乘算（万分比），同类之间相乘
+	AttrModType_Override AttrModType = 4 // 覆盖最终值，多个时取最后添加的
+	AttrModType_Max      AttrModType = 5
+)
+
+var attrModTypeNames = map[AttrModType]string{
+	AttrModType_Invalid:  "Invalid",
+	AttrModType_Flat:     "Flat",
+	AttrModType_PctAdd:   "PctAdd",
+	AttrModType_PctMul:   "PctMul",
+	AttrModType_Override: "Override",
+}
+
+func (t AttrModType) String() string {
+	if name, ok := attrModTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("AttrModType(%d)", int32(t))
+}
+
+// AttrModifier 为一条属性修正，Source 标记修正来源（如 Buff 实例ID），用于按来源移除。
+type AttrModifier struct {
+	Source  uid.Uid
+	Type    enum.AttrType
+	ModType AttrModType
+	Value   int64
+}
+
+// AttrDerive 为一条主属性到次级属性的转换：To 额外获得 From 最终值 × Ratio 的固定加成。
+type AttrDerive struct {
+	From  enum.AttrType
+	To    enum.AttrType
+	Ratio float64
+}
+
+// AttrSet 为实体的运行时属性：基础值加上按来源记录的修正，最终值按需计算并缓存。
+//
+//	最终值 = (基础 + Σ转换 + Σ固定) × (1 + Σ百分比加成) × Π(1 + 百分比乘算)
+//
+// 存在覆盖修正时最终值直接取覆盖值。基础属性的 Attr.Rate 视为来源为 uid.Zero 的百分比加成。
+// 主属性变化时由其转换得到的属性随之失效并重新计算（转换关系不能成环）。
+//
+// 注意：非线程安全，只在区域逻辑线程内使用。
+type AttrSet struct {
+	base    map[enum.AttrType]int64
+	mods    map[enum.AttrType][]AttrModifier
+	derives []AttrDerive
+	cache   map[enum.AttrType]int64
+
+	// OnChange 属性最终值变化后的回调
+	OnChange func(ty enum.AttrType, old, new int64)
+}
+
+// NewAttrSet 以 attrs 为基础属性创建属性集，attrs 可以为 nil。
+func NewAttrSet(attrs *Attrs) *AttrSet {
+	s := &AttrSet{
+		base:  make(map[enum.AttrType]int64),
+		mods:  make(map[enum.AttrType][]AttrModifier),
+		cache: make(map[enum.AttrType]int64),
+	}
+	if attrs == nil {
+		return s
+	}
+	for _, a := range *attrs {
+		s.base[a.Type] += a.Val
+		if a.Rate != 0 {
+			s.mods[a.Type] = append(s.mods[a.Type], AttrModifier{Type: a.Type, ModType: AttrModType_PctAdd, Value: a.Rate})
+		}
+	}
+	return s
+}
+
+// GetValue 获取属性最终值。
+func (s *AttrSet) GetValue(ty enum.AttrType) int64 {
+	if v, ok := s.cache[ty]; ok {
+		return v
+	}
+	v := s.calc(ty)
+	s.cache[ty] = v
+	return v
+}
+
+// GetBase 获取属性基础值。
+func (s *AttrSet) GetBase(ty enum.AttrType) int64 {
+	return s.base[ty]
+}
+
+// SetBase 设置属性基础值。
+func (s *AttrSet) SetBase(ty enum.AttrType, val int64) {
+	if s.base[ty] == val {
+		return
+	}
+	s.update(ty, func() { s.base[ty] = val })
+}
+
+// AddModifier 添加一条属性修正。
+func (s *AttrSet) AddModifier(mod AttrModifier) {
+	if mod.ModType <= AttrModType_Invalid || mod.ModType >= AttrModType_Max {
+		return
+	}
+	s.update(mod.Type, func() { s.mods[mod.Type] = append(s.mods[mod.Type], mod) })
+}
+
+// RemoveModifiers 移除指定来源的所有属性修正，返回移除数量。
+func (s *AttrSet) RemoveModifiers(source uid.Uid) int {
+	n := 0
+	for ty, mods := range s.mods {
+		if !slices.ContainsFunc(mods, func(m AttrModifier) bool { return m.Source == source }) {
+			continue
+		}
+		s.update(ty, func() {
+			kept := slices.DeleteFunc(mods, func(m AttrModifier) bool { return m.Source == source })
+			n += len(mods) - len(kept)
+			s.mods[ty] = kept
+		})
+	}
+	return n
+}
+
+// SetDerives 设置主属性到次级属性的转换（替换原有转换）。
+func (s *AttrSet) SetDerives(derives []AttrDerive) {
+	var types []enum.AttrType
+	for _, d := range slices.Concat(s.derives, derives) {
+		if !slices.Contains(types, d.To) {
+			types = append(types, d.To)
+		}
+	}
+	s.updateAll(types, func() { s.derives = slices.Clone(derives) })
+}
+
+// GetModifiers 获取指定属性的所有修正（按添加顺序）。
+func (s *AttrSet) GetModifiers(ty enum.AttrType) []AttrModifier {
+	return slices.Clone(s.mods[ty])
+}
+
+// update 执行修改并使属性及由其转换得到的属性缓存失效，最终值变化时触发 OnChange。
+func (s *AttrSet) update(ty enum.AttrType, change func()) {
+	s.updateAll([]enum.AttrType{ty}, change)
+}
+
+func (s *AttrSet) updateAll(types []enum.AttrType, change func()) {
+	types = s.dependents(types)
+	if s.OnChange == nil {
+		change()
+		s.invalidate(types)
+		return
+	}
+	old := make([]int64, len(types))
+	for i, ty := range types {
+		old[i] = s.GetValue(ty)
+	}
+	change()
+	s.invalidate(types)
+	for i, ty := range types {
+		if v := s.GetValue(ty); v != old[i] {
+			s.OnChange(ty, old[i], v)
+		}
+	}
+}
+
+// dependents 获取 types 及所有直接或间接由其转换得到的属性。
+func (s *AttrSet) dependents(types []enum.AttrType) []enum.AttrType {
+	for i := 0; i < len(types); i++ {
+		for _, d := range s.derives {
+			if d.From == types[i] && !slices.Contains(types, d.To) {
+				types = append(types, d.To)
+			}
+		}
+	}
+	return types
+}
+
+func (s *AttrSet) invalidate(types []enum.AttrType) {
+	for _, ty := range types {
+		delete(s.cache, ty)
+	}
+}
+
+// calc 计算属性最终值。
+func (s *AttrSet) calc(ty enum.AttrType) int64 {
+	mods := s.mods[ty]
+	flat, pctAdd, pctMul := float64(s.base[ty]), 1.0, 1.0
+	for i := len(mods) - 1; i >= 0; i-- {
+		if mods[i].ModType == AttrModType_Override {
+			return mods[i].Value
+		}
+	}
+	for _, d := range s.derives {
+		if d.To == ty {
+			flat += float64(s.GetValue(d.From)) * d.Ratio
+		}
+	}
+	for _, m := range mods {
+		switch m.ModType {
+		case AttrModType_Flat:
+			flat += float64(m.Value)
+		case AttrModType_PctAdd:
+			pctAdd += float64(m.Value) / AttrRatePrecision
+		case AttrModType_PctMul:
+			pctMul *= 1 + float64(m.Value)/AttrRatePrecision
+		}
+	}
+	return int64(math.Round(flat * max(pctAdd, 0) * max(pctMul, 0)))
+}
@@ -0,0 +1,117 @@
+package data
+
+import (
+	"slices"
+	"testing"
+
+	"server/data/enum"
+	"server/lib/uid"
+)
+
+func TestAttrSet_Layers(t *testing.T) {
+	attrs := Attrs{{Type: enum.AttrType_PhyAttack, Val: 100, Rate: 1000}}
+	s := NewAttrSet(&attrs)
+	// Rate 计入百分比加成：100 × 1.1
+	if v := s.GetValue(enum.AttrType_PhyAttack); v != 110 {
+		t.Fatalf("Expected 110 with base rate, got %d", v)
+	}
+
+	buff, gear := uid.Uid(1), uid.Uid(2)
+	s.AddModifier(AttrModifier{Source: gear, Type: enum.AttrType_PhyAttack, ModType: AttrModType_Flat, Value: 100})
+	s.AddModifier(AttrModifier{Source: buff, Type: enum.AttrType_PhyAttack, ModType: AttrModType_PctAdd, Value: 1000})
+	s.AddModifier(AttrModifier{Source: buff, Type: enum.AttrType_PhyAttack, ModType: AttrModType_PctMul, Value: 5000})
+	s.AddModifier(AttrModifier{Source: gear, Type: enum.AttrType_PhyAttack, ModType: AttrModType_PctMul, Value: -2000})
+	// (100 + 100) × (1 + 0.1 + 0.1) × 1.5 × 0.8
+	if v := s.GetValue(enum.AttrType_PhyAttack); v != 288 {
+		t.Errorf("Expected 288, got %d", v)
+	}
+
+	// 覆盖优先，多个覆盖取最后添加的
+	s.AddModifier(AttrModifier{Source: buff, Type: enum.AttrType_PhyAttack, ModType: AttrModType_Override, Value: 1})
+	s.AddModifier(AttrModifier{Source: gear, Type: enum.AttrType_PhyAttack, ModType: AttrModType_Override, Value: 2})
+	if v := s.GetValue(enum.AttrType_PhyAttack); v != 2 {
+		t.Errorf("Expected last override 2, got %d", v)
+	}
+
+	// 按来源移除
+	if n := s.RemoveModifiers(gear); n != 3 {
+		t.Errorf("Expected 3 gear modifiers removed, got %d", n)
+	}
+	if v := s.GetValue(enum.AttrType_PhyAttack); v != 1 {
+		t.Errorf("Expected buff override 1, got %d", v)
+	}
+	s.RemoveModifiers(buff)
+	if v := s.GetValue(enum.AttrType_PhyAttack); v != 110 || len(s.GetModifiers(enum.AttrType_PhyAttack)) != 1 {
+		t.Errorf("Expected only base rate left, got %d", v)
+	}
+
+	// 无效的修正类型被忽略，百分比减到负数时最终值为 0
+	s.AddModifier(AttrModifier{Type: enum.AttrType_PhyAttack, Value: 100})
+	s.AddModifier(AttrModifier{Source: buff, Type: enum.AttrType_PhyAttack, ModType: AttrModType_PctAdd, Value: -20000})
+	if v := s.GetValue(enum.AttrType_PhyAttack); v != 0 {
+		t.Errorf("Expected 0, got %d", v)
+	}
+}
+
+func TestAttrSet_OnChange(t *testing.T) {
+	s := NewAttrSet(nil)
+	type change struct{ old, new int64 }
+	var changes []change
+	s.OnChange = func(ty enum.AttrType, old, new int64) {
+		if ty != enum.AttrType_MaxHp {
+			t.Errorf("Unexpected change of %d", ty)
+		}
+		changes = append(changes, change{old, new})
+	}
+
+	s.SetBase(enum.AttrType_MaxHp, 1000)
+	s.SetBase(enum.AttrType_MaxHp, 1000)
+	src := uid.Uid(1)
+	s.AddModifier(AttrModifier{Source: src, Type: enum.AttrType_MaxHp, ModType: AttrModType_PctAdd, Value: 2000})
+	// 最终值不变时不通知
+	s.AddModifier(AttrModifier{Source: src, Type: enum.AttrType_MaxHp, ModType: AttrModType_Flat, Value: 0})
+	s.RemoveModifiers(src)
+	s.RemoveModifiers(src)
+
+	want := []change{{0, 1000}, {1000, 1200}, {1200, 1000}}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want[i], changes[i])
+		}
+	}
+	if s.GetBase(enum.AttrType_MaxHp) != 1000 {
+		t.Errorf("Expected base 1000, got %d", s.GetBase(enum.AttrType_MaxHp))
+	}
+}
+
+func TestAttrSet_Derives(t *testing.T) {
+	attrs := Attrs{{Type: enum.AttrType_Constitution, Val: 10}, {Type: enum.AttrType_MaxHp, Val: 100}}
+	s := NewAttrSet(&attrs)
+	var changed []enum.AttrType
+	s.OnChange = func(ty enum.AttrType, old, new int64) { changed = append(changed, ty) }
+
+	s.SetDerives([]AttrDerive{{From: enum.AttrType_Constitution, To: enum.AttrType_MaxHp, Ratio: 10}})
+	if v := s.GetValue(enum.AttrType_MaxHp); v != 200 {
+		t.Errorf("Expected derived max hp 200, got %d", v)
+	}
+
+	// 主属性的修正传递到次级属性，次级属性的百分比修正作用于转换值
+	src := uid.Uid(1)
+	s.AddModifier(AttrModifier{Source: src, Type: enum.AttrType_Constitution, ModType: AttrModType_Flat, Value: 5})
+	s.AddModifier(AttrModifier{Source: src, Type: enum.AttrType_MaxHp, ModType: AttrModType_PctAdd, Value: 5000})
+	if v := s.GetValue(enum.AttrType_MaxHp); v != 375 {
+		t.Errorf("Expected max hp 375, got %d", v)
+	}
+	want := []enum.AttrType{enum.AttrType_MaxHp, enum.AttrType_Constitution, enum.AttrType_MaxHp, enum.AttrType_MaxHp}
+	if !slices.Equal(changed, want) {
+		t.Errorf("Expected changes %v, got %v", want, changed)
+	}
+
+	s.SetDerives(nil)
+	if v := s.GetValue(enum.AttrType_MaxHp); v != 150 {
+		t.Errorf("Expected max hp 150 without derives, got %d", v)
+	}
+}
@@ -0,0 +1,94 @@
+package conf
+
+import (
+	"strings"
+
+	config "server/data/xls"
+)
+
+// CCType 为控制类型（与配表 BuffEffect.CCType 一致）。
+type CCType int32
+
+const (
+	CCType_None    CCType = 0
+	CCType_Stun    CCType = 1 // 眩晕：无法移动、施法
+	CCType_Silence CCType = 2 // 沉默：无法施法
+	CCType_Root    CCType = 3 // 定身：无法移动
+	CCType_Max     CCType = 4
+)
+
+// BuffEffectType 为 Buff 效果类型（与配表 BuffEffect.EffectType 一致）。
+type BuffEffectType int32
+
+const (
+	BuffEffectType_None      BuffEffectType = 0
+	BuffEffectType_Damage    BuffEffectType = 1  // 周期伤害（DamageFormulaID）
+	BuffEffectType_Heal      BuffEffectType = 2  // 周期治疗（HealFormulaID）
+	BuffEffectType_Attribute BuffEffectType = 3  // 属性修改（AttributeType/ModType/ModValue）
+	BuffEffectType_Shield    BuffEffectType = 4  // 护盾吸收（HealFormulaID 为护盾公式，未配置时取 ShieldAmount；P1 为可吸收的系别掩码）
+	BuffEffectType_Control   BuffEffectType = 5  // 控制（CCType）
+	BuffEffectType_MoveSpeed BuffEffectType = 6  // 移动速度（MoveSpeedPct）
+	BuffEffectType_Haste     BuffEffectType = 7  // 攻击/施法速度（AttackSpeedPct/CastSpeedPct）
+	BuffEffectType_Immunity  BuffEffectType = 13 // 免疫
+)
+
+// BuffTrigger 为 Buff 效果触发方式（与配表 BuffEffect.TriggerType 一致）。
+type BuffTrigger int32
+
+const (
+	BuffTrigger_None    BuffTrigger = 0
+	BuffTrigger_Tick    BuffTrigger = 1 // 周期触发：每 TickIntervalMs 触发一次，最多 MaxTicks 次（0 为不限）
+	BuffTrigger_Event   BuffTrigger = 2 // 事件触发（EventType）
+	BuffTrigger_Passive BuffTrigger = 3 // 常驻：施加时生效，移除时还原
+	BuffTrigger_Max     BuffTrigger = 4
+)
+
+// StackRule 为同一 Buff 再次施加时的叠加规则（与配表 Buff.StackRule 一致）。
+type StackRule int32
+
+const (
+	StackRule_None      StackRule = 0
+	StackRule_Refresh   StackRule = 1 // 刷新：已存在时只刷新持续时间，层数不变
+	StackRule_AddStack  StackRule = 2 // 叠层：已存在时叠加层数（上限 MaxStacks）并刷新持续时间
+	StackRule_PerCaster StackRule = 3 // 按施加者独立：每个施加者一个实例，同一施加者再次施加时按叠层处理
+	StackRule_Replace   StackRule = 4 // 替换：同组已有 Buff 优先级不高于新 Buff 时被移除并施加新实例，否则拒绝
+	StackRule_Reject    StackRule = 5 // 拒绝：同组已有更高优先级的 Buff 时拒绝，否则与同组不强于它的 Buff 共存（同 ID 时刷新）
+	StackRule_Max       StackRule = 6
+)
+
+// Stackable 判断规则是否允许多层（MaxStacks > 1 只对这些规则有意义）。
+func (r StackRule) Stackable() bool {
+	return r == StackRule_AddStack || r == StackRule_PerCaster
+}
+
+// BuffTag 为 Buff 配置 Tags 字段中的标签，多个标签以 '|' 分隔。
+const (
+	BuffTag_KeepOnDeath = "KeepOnDeath" // 死亡时不移除
+	BuffTag_GroupPrefix = "Group:"      // 互斥组，如 "Group:Armor"；未配置时每个 BuffID 自成一组
+)
+
+// BuffGroup 获取 Buff 的互斥组名（Group: 标签的值），未配置时返回空字符串。
+func BuffGroup(b *config.Buff) string {
+	if b == nil || b.Tags == "" {
+		return ""
+	}
+	for t := range strings.SplitSeq(b.Tags, "|") {
+		if g, ok := strings.CutPrefix(strings.TrimSpace(t), BuffTag_GroupPrefix); ok {
+			return g
+		}
+	}
+	return ""
+}
+
+// BuffHasTag 判断 Buff 是否带有指定标签。
+func BuffHasTag(b *config.Buff, tag string) bool {
+	if b == nil || b.Tags == "" {
+		return false
+	}
+	for t := range strings.SplitSeq(b.Tags, "|") {
+		if strings.TrimSpace(t) == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package conf
+
+import (
+	"testing"
+
+	config "server/data/xls"
+)
+
+func TestBuffHasTag(t *testing.T) {
+	b := &config.Buff{Tags: "Buff|Holy| KeepOnDeath"}
+	if !BuffHasTag(b, BuffTag_KeepOnDeath) {
+		t.Error("Expected KeepOnDeath tag")
+	}
+	if BuffHasTag(b, "Debuff") {
+		t.Error("Expected no Debuff tag")
+	}
+	if BuffHasTag(&config.Buff{}, "Buff") || BuffHasTag(nil, "Buff") {
+		t.Error("Expected no tags on empty buff")
+	}
+}
+
+func TestBuffGroup(t *testing.T) {
+	if g := BuffGroup(&config.Buff{Tags: "Buff| Group:Armor|Holy"}); g != "Armor" {
+		t.Errorf("Expected group Armor, got %q", g)
+	}
+	if g := BuffGroup(&config.Buff{Tags: "Buff|Holy"}); g != "" {
+		t.Errorf("Expected no group, got %q", g)
+	}
+	if BuffGroup(nil) != "" {
+		t.Error("Expected no group on nil buff")
+	}
+}
@@ -16,13 +16,18 @@ type CSkill struct {
 	GcdStartAt         TimingPoint // GCD 起算时机（默认 CastStart）
 	CooldownStartAt    TimingPoint // CD 起算时机（默认 CastStart）
 
+	CanCastWhileStunned bool // 被眩晕时是否仍可施放（如解控技能）
+	InterruptibleByCC   bool // 吟唱/引导中是否会被眩晕、沉默打断
+
 	HitOnCastFinish bool  // 是否在 CastFinish 后自动触发一次 OnHit（常用于“瞬发即命中”的技能）
 	HitDelayMs      int32 // HitOnCastFinish 为 true 时生效：CastFinish 到 Hit 的延迟（毫秒）
 
 	Charges    int32 // 充能数量（0/1 表示无充能机制）
 	RechargeMs int32 // 充能恢复时间（毫秒）
 
-	CostMp int64 // 消耗MP
+	CostType       ResourceType // 消耗的资源类型，None 表示无消耗
+	Cost           int64        // 资源消耗量，开始施法时扣除
+	RefundOnCancel bool         // 吟唱被取消/打断时是否返还消耗（配表暂无对应列，从配表构建时为 false）
 
 	RangeMin float32 // 最小施法距离
 	RangeMax float32 // 最大施法距离
@@ -35,6 +40,17 @@ type CSkill struct {
 	Effects SkillEffects // 分阶段效果列表（Effect）
 }
 
+// ResourceType 为技能消耗的资源类型（与配表 Skill.ResourceType 一致）。
+type ResourceType int32
+
+const (
+	ResourceType_None   ResourceType = 0 // 无消耗
+	ResourceType_Mp     ResourceType = 1 // 法力
+	ResourceType_Rage   ResourceType = 2 // 怒气
+	ResourceType_Energy ResourceType = 3 // 能量
+	ResourceType_Max    ResourceType = 4
+)
+
 // TimingPoint 表示“某个规则从技能哪个阶段开始起算”。
 type TimingPoint int32
 
@@ -62,6 +78,7 @@ const (
 	TargetRelation_Self    TargetRelation = 1
 	TargetRelation_Ally    TargetRelation = 2
 	TargetRelation_Enemy   TargetRelation = 3
+	TargetRelation_All     TargetRelation = 4
 )
 
 // TargetMode 表示技能的目标模式（如何选目标）。
@@ -86,16 +103,46 @@ const (
 	ShapeType_Ring    ShapeType = 5
 )
 
+// TargetSort 表示选择器的目标排序方式。
+type TargetSort int32
+
+const (
+	TargetSort_None          TargetSort = 0 // 不排序（保持选取顺序）
+	TargetSort_Nearest       TargetSort = 1 // 距离选取中心最近
+	TargetSort_Farthest      TargetSort = 2 // 距离选取中心最远
+	TargetSort_LowestHp      TargetSort = 3 // 当前生命最低
+	TargetSort_HighestHp     TargetSort = 4 // 当前生命最高
+	TargetSort_LowestHpPct   TargetSort = 5 // 生命百分比最低
+	TargetSort_Random        TargetSort = 6 // 随机
+	TargetSort_HighestThreat TargetSort = 7 // 施法者仇恨表中仇恨最高
+	TargetSort_Max           TargetSort = 8
+)
+
 // TargetCfg 描述技能目标与范围参数。
+// 区域内选出的实体依次经过 过滤（关系/存活/生命/Buff）→ 排序 → 截断（MaxCount）。
 type TargetCfg struct {
 	Relation TargetRelation // 目标关系（自/友/敌）
 	Mode     TargetMode     // 目标模式（单位/点/无目标）
 	Shape    ShapeType      // 区域形状
 
-	Radius float32 // 圆/环半径
-	Angle  float32 // 扇形角度
-	Width  float32 // 矩形宽
-	Length float32 // 矩形长/扇形长度
+	Radius      float32 // 圆/环半径
+	InnerRadius float32 // 环内半径
+	Angle       float32 // 扇形角度
+	Width       float32 // 矩形宽
+	Length      float32 // 矩形长/扇形长度
+
+	MinHp         int64   // 当前生命下限（0 表示不限制）
+	MaxHp         int64   // 当前生命上限（0 表示不限制）
+	MinHpPct      float64 // 生命百分比下限（0~1，0 表示不限制）
+	MaxHpPct      float64 // 生命百分比上限（0~1，0 表示不限制）
+	RequireBuffId int64   // 目标身上必须有该 Buff（0 表示不限制）
+	ExcludeBuffId int64   // 目标身上不能有该 Buff（0 表示不限制）
+
+	Sort     TargetSort // 排序方式
+	MaxCount int32      // 最大目标数（0 表示不限制）
+
+	IncludeCaster bool // 区域选取时是否包含施法者自身
+	IncludeDead   bool // 是否可选中已死亡的目标
 }
 
 // EffectType 表示瞬时结算的效果类型（Effect）。
@@ -119,8 +166,10 @@ const (
 // EffectCfg 为单个效果配置。
 // Times/IntervalMs 用于多段结算：同一个 Effect 可重复执行多次，间隔 IntervalMs。
 type EffectCfg struct {
+	Id   int64      // 效果配置ID（由配表加载时填充，手写配置可为 0）
 	Type EffectType // 效果类型
 
+	DelayMs    int32 // 相对阶段触发时刻的延迟（毫秒）
 	Times      int32 // 执行次数（<=1 视为 1）
 	IntervalMs int32 // 多段间隔（毫秒）
 
@@ -0,0 +1,31 @@
+package conf
+
+// DamageType 表示伤害类型（对应配表 DamageFormula.DamageType）。
+type DamageType int32
+
+const (
+	DamageType_Invalid  DamageType = 0
+	DamageType_Physical DamageType = 1 // 物理伤害
+	DamageType_Magic    DamageType = 2 // 法术伤害
+	DamageType_True     DamageType = 3 // 真实伤害
+)
+
+// DamageSchool 表示伤害系别（位掩码，对应配表 DamageFormula.School / Skill.SchoolMask）。
+type DamageSchool int32
+
+const (
+	DamageSchool_None     DamageSchool = 0
+	DamageSchool_Physical DamageSchool = 1 << 0 // 物理
+	DamageSchool_Fire     DamageSchool = 1 << 1 // 火焰
+	DamageSchool_Frost    DamageSchool = 1 << 2 // 冰霜
+	DamageSchool_Nature   DamageSchool = 1 << 3 // 自然
+	DamageSchool_Shadow   DamageSchool = 1 << 4 // 暗影
+	DamageSchool_Holy     DamageSchool = 1 << 5 // 神圣
+	DamageSchool_Arcane   DamageSchool = 1 << 6 // 奥术
+	DamageSchool_All      DamageSchool = 1<<7 - 1
+)
+
+// Has 判断是否包含指定系别。
+func (s DamageSchool) Has(other DamageSchool) bool {
+	return s&other != 0
+}
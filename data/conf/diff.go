@@ -0,0 +1,109 @@
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	config "server/data/xls"
+)
+
+// TableDiff 为单张表在两份配置之间的差异（ID 升序）。
+type TableDiff struct {
+	Table   string
+	Added   []int64
+	Removed []int64
+	Changed []int64
+}
+
+// Empty 判断该表是否无变化。
+func (d *TableDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// TablesDiff 为两份配置快照的差异摘要，用于热更日志。
+type TablesDiff struct {
+	Tables []*TableDiff
+}
+
+// Empty 判断两份配置是否完全一致。
+func (d *TablesDiff) Empty() bool {
+	for _, td := range d.Tables {
+		if !td.Empty() {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *TablesDiff) String() string {
+	if d.Empty() {
+		return "no changes"
+	}
+	var sb strings.Builder
+	for _, td := range d.Tables {
+		if td.Empty() {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("; ")
+		}
+		fmt.Fprintf(&sb, "%s: +%v -%v ~%v", td.Table, td.Added, td.Removed, td.Changed)
+	}
+	return sb.String()
+}
+
+// Diff 对比新旧配置快照，按表给出新增/删除/修改的ID。prev 为 nil 时视为全部新增。
+func Diff(prev, next *Tables) *TablesDiff {
+	if prev == nil {
+		prev = &Tables{}
+	}
+	if next == nil {
+		next = &Tables{}
+	}
+	return &TablesDiff{
+		Tables: []*TableDiff{
+			diffTable("skills", rawSkills(prev), rawSkills(next)),
+			diffTable("skillEffects", prev.SkillEffects, next.SkillEffects),
+			diffTable("selectors", prev.Selectors, next.Selectors),
+			diffTable("buffs", prev.Buffs, next.Buffs),
+			diffTable("buffEffects", prev.BuffEffects, next.BuffEffects),
+			diffTable("damageFormulas", prev.DamageFormulas, next.DamageFormulas),
+			diffTable("attrDerives", prev.AttrDerives, next.AttrDerives),
+		},
+	}
+}
+
+// rawSkills 按 ID 索引原始技能行（Tables.Skills 为转换后的结构，对比原始行更直观）。
+func rawSkills(t *Tables) map[int64]*config.Skill {
+	if t.Raw == nil {
+		return nil
+	}
+	m := make(map[int64]*config.Skill, len(t.Raw.Skills))
+	for i := range t.Raw.Skills {
+		m[int64(t.Raw.Skills[i].ID)] = &t.Raw.Skills[i]
+	}
+	return m
+}
+
+func diffTable[V any](table string, prev, next map[int64]*V) *TableDiff {
+	d := &TableDiff{Table: table}
+	for id, nv := range next {
+		ov, ok := prev[id]
+		if !ok {
+			d.Added = append(d.Added, id)
+		} else if !reflect.DeepEqual(ov, nv) {
+			d.Changed = append(d.Changed, id)
+		}
+	}
+	for id := range prev {
+		if _, ok := next[id]; !ok {
+			d.Removed = append(d.Removed, id)
+		}
+	}
+	slices.Sort(d.Added)
+	slices.Sort(d.Removed)
+	slices.Sort(d.Changed)
+	return d
+}
@@ -0,0 +1,48 @@
+package conf
+
+import (
+	"slices"
+	"testing"
+
+	config "server/data/xls"
+)
+
+func TestDiff(t *testing.T) {
+	prev, err := NewTables(&AllConfig{AllConfig: config.AllConfig{
+		Buffs:       []config.Buff{{ID: 1, BuffType: 1, StackRule: 1, MaxStacks: 1, EffectIDs: []int{10}}, {ID: 2, BuffType: 1, StackRule: 1, MaxStacks: 1, EffectIDs: []int{10}}},
+		BuffEffects: []config.BuffEffect{{ID: 10, EffectType: int(BuffEffectType_Damage), TriggerType: 3}},
+	}})
+	if err != nil {
+		t.Fatalf("NewTables failed: %v", err)
+	}
+	next, err := NewTables(&AllConfig{AllConfig: config.AllConfig{
+		Buffs:       []config.Buff{{ID: 1, BuffType: 1, StackRule: 2, MaxStacks: 3, EffectIDs: []int{10}}, {ID: 3, BuffType: 1, StackRule: 1, MaxStacks: 1, EffectIDs: []int{10}}},
+		BuffEffects: []config.BuffEffect{{ID: 10, EffectType: int(BuffEffectType_Damage), TriggerType: 3}},
+	}})
+	if err != nil {
+		t.Fatalf("NewTables failed: %v", err)
+	}
+
+	d := Diff(prev, next)
+	if d.Empty() {
+		t.Fatal("Expected non-empty diff")
+	}
+	for _, td := range d.Tables {
+		if td.Table != "buffs" {
+			if !td.Empty() {
+				t.Errorf("Expected no changes in %s, got %+v", td.Table, td)
+			}
+			continue
+		}
+		if !slices.Equal(td.Added, []int64{3}) || !slices.Equal(td.Removed, []int64{2}) || !slices.Equal(td.Changed, []int64{1}) {
+			t.Errorf("Unexpected buffs diff: %+v", td)
+		}
+	}
+
+	if !Diff(prev, prev).Empty() {
+		t.Error("Expected empty diff for identical tables")
+	}
+	if got := Diff(nil, prev).Tables[3].Added; len(got) != 2 {
+		t.Errorf("Expected all buffs added when prev is nil, got %v", got)
+	}
+}
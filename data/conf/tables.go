@@ -0,0 +1,372 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"server/data"
+	"server/data/enum"
+	config "server/data/xls"
+)
+
+// 配表中 Selector.Shape 的取值（与 ShapeType 不同，配表不区分“单体”，由 Mode/Radius 推断）。
+const (
+	selectorShape_Circle = 1 // 圆形
+	selectorShape_Cone   = 2 // 扇形
+	selectorShape_Rect   = 3 // 矩形
+	selectorShape_Ring   = 4 // 环形
+)
+
+// 配表中 SkillEffect.Stage 的取值（与 skill.Stage 一致）。
+const (
+	effectStage_CastStart   = 1
+	effectStage_CastFinish  = 2
+	effectStage_ChannelTick = 3
+	effectStage_Hit         = 4
+	effectStage_Cancel      = 5
+)
+
+// AttrDeriveFile 为属性转换表文件名，与 all.json 放在同一目录。
+// 属性转换表尚未加入 xls 源，由手工维护，导表工具重新生成 all.json 时不会覆盖。
+const AttrDeriveFile = "attr_derive.json"
+
+// AllConfig 为一份完整的原始配表：导表工具生成的 all.json（config.AllConfig）加上手工维护的属性转换表。
+type AllConfig struct {
+	config.AllConfig
+	AttrDerives []AttrDerive `json:"attrDerives"` // 来自 AttrDeriveFile
+}
+
+// AttrDerive 为属性转换表的一行：ClassID 职业的 TargetAttr 额外获得 SourceAttr 最终值 × Ratio。
+type AttrDerive struct {
+	ID         int     `json:"ID"`
+	ClassID    int     `json:"ClassID"`
+	SourceAttr int     `json:"SourceAttr"`
+	TargetAttr int     `json:"TargetAttr"`
+	Ratio      float64 `json:"Ratio"`
+}
+
+// Tables 为一份完整的配置快照（只读），由 all.json 与 AttrDeriveFile 加载并解析为运行时结构。
+// 原始配表行按 ID 建立索引；技能额外转换为 CSkill，可直接交给 SkillManager.AddSkill 使用。
+type Tables struct {
+	Raw *AllConfig // 原始配表数据
+
+	Skills         map[int64]*CSkill               // 技能ID -> 运行时技能配置
+	SkillEffects   map[int64]*config.SkillEffect   // 技能效果ID -> 配表行
+	Selectors      map[int64]*config.Selector      // 选择器ID -> 配表行
+	Buffs          map[int64]*config.Buff          // BuffID -> 配表行
+	BuffEffects    map[int64]*config.BuffEffect    // Buff效果ID -> 配表行
+	DamageFormulas map[int64]*config.DamageFormula // 伤害/治疗公式ID -> 配表行
+	AttrDerives    map[int64]*AttrDerive           // 属性转换ID -> 配表行
+
+	classDerives map[int32][]data.AttrDerive // 职业ID -> 主属性到次级属性的转换
+}
+
+// LoadTables 从 all.json 及同目录下的 AttrDeriveFile 加载配置并构建 Tables。
+func LoadTables(path string) (*Tables, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config %s: %w", path, err)
+	}
+	derivePath := filepath.Join(filepath.Dir(path), AttrDeriveFile)
+	deriveRaw, err := os.ReadFile(derivePath)
+	if err != nil {
+		return nil, fmt.Errorf("read config %s: %w", derivePath, err)
+	}
+	return ParseTables(raw, deriveRaw)
+}
+
+// ParseTables 从 JSON 内容解析配置并构建 Tables：raw 为 all.json，deriveRaw 为属性转换表。
+func ParseTables(raw, deriveRaw []byte) (*Tables, error) {
+	all := &AllConfig{}
+	if err := json.Unmarshal(raw, &all.AllConfig); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	if err := json.Unmarshal(deriveRaw, &all.AttrDerives); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", AttrDeriveFile, err)
+	}
+	return NewTables(all)
+}
+
+// NewTables 校验原始配表并构建索引，将技能配置解析为 CSkill。
+// 校验失败时返回 ValidationErrors，包含全部问题。
+func NewTables(all *AllConfig) (*Tables, error) {
+	if errs := Validate(all); errs != nil {
+		return nil, errs
+	}
+
+	t := &Tables{
+		Raw:            all,
+		Skills:         make(map[int64]*CSkill, len(all.Skills)),
+		SkillEffects:   make(map[int64]*config.SkillEffect, len(all.SkillEffects)),
+		Selectors:      make(map[int64]*config.Selector, len(all.Selectors)),
+		Buffs:          make(map[int64]*config.Buff, len(all.Buffs)),
+		BuffEffects:    make(map[int64]*config.BuffEffect, len(all.BuffEffects)),
+		DamageFormulas: make(map[int64]*config.DamageFormula, len(all.DamageFormulas)),
+		AttrDerives:    make(map[int64]*AttrDerive, len(all.AttrDerives)),
+		classDerives:   make(map[int32][]data.AttrDerive),
+	}
+
+	for i := range all.SkillEffects {
+		if err := indexRow(t.SkillEffects, "skillEffects", all.SkillEffects[i].ID, &all.SkillEffects[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := range all.Selectors {
+		if err := indexRow(t.Selectors, "selectors", all.Selectors[i].ID, &all.Selectors[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := range all.Buffs {
+		if err := indexRow(t.Buffs, "buffs", all.Buffs[i].ID, &all.Buffs[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := range all.BuffEffects {
+		if err := indexRow(t.BuffEffects, "buffEffects", all.BuffEffects[i].ID, &all.BuffEffects[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := range all.DamageFormulas {
+		if err := indexRow(t.DamageFormulas, "damageFormulas", all.DamageFormulas[i].ID, &all.DamageFormulas[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range all.AttrDerives {
+		row := &all.AttrDerives[i]
+		if err := indexRow(t.AttrDerives, "attrDerives", row.ID, row); err != nil {
+			return nil, err
+		}
+		t.classDerives[int32(row.ClassID)] = append(t.classDerives[int32(row.ClassID)], data.AttrDerive{
+			From:  enum.AttrType(row.SourceAttr),
+			To:    enum.AttrType(row.TargetAttr),
+			Ratio: row.Ratio,
+		})
+	}
+
+	for i := range all.Skills {
+		row := &all.Skills[i]
+		if _, exists := t.Skills[int64(row.ID)]; exists {
+			return nil, fmt.Errorf("skills: duplicate ID %d", row.ID)
+		}
+		cs, err := t.buildSkill(row)
+		if err != nil {
+			return nil, err
+		}
+		t.Skills[cs.Cid] = cs
+	}
+
+	return t, nil
+}
+
+// GetSkill 获取技能运行时配置。
+func (t *Tables) GetSkill(id int64) *CSkill {
+	return t.Skills[id]
+}
+
+// GetSkillEffect 获取技能效果配表行。
+func (t *Tables) GetSkillEffect(id int64) *config.SkillEffect {
+	return t.SkillEffects[id]
+}
+
+// GetSelector 获取选择器配表行。
+func (t *Tables) GetSelector(id int64) *config.Selector {
+	return t.Selectors[id]
+}
+
+// GetTargetCfg 获取选择器转换后的目标配置，选择器不存在时返回 nil。
+func (t *Tables) GetTargetCfg(id int64) *TargetCfg {
+	sel := t.Selectors[id]
+	if sel == nil {
+		return nil
+	}
+	tc := buildTarget(sel)
+	return &tc
+}
+
+// GetBuff 获取 Buff 配表行。
+func (t *Tables) GetBuff(id int64) *config.Buff {
+	return t.Buffs[id]
+}
+
+// GetBuffEffect 获取 Buff 效果配表行。
+func (t *Tables) GetBuffEffect(id int64) *config.BuffEffect {
+	return t.BuffEffects[id]
+}
+
+// GetDamageFormula 获取伤害/治疗公式配表行。
+func (t *Tables) GetDamageFormula(id int64) *config.DamageFormula {
+	return t.DamageFormulas[id]
+}
+
+// GetClassDerives 获取职业的主属性到次级属性转换，未配置时返回 nil。
+func (t *Tables) GetClassDerives(classId int32) []data.AttrDerive {
+	return t.classDerives[classId]
+}
+
+func indexRow[V any](m map[int64]*V, table string, id int, row *V) error {
+	if _, exists := m[int64(id)]; exists {
+		return fmt.Errorf("%s: duplicate ID %d", table, id)
+	}
+	m[int64(id)] = row
+	return nil
+}
+
+// buildSkill 将配表技能行转换为 CSkill：解析选择器与按阶段分组的效果列表。
+func (t *Tables) buildSkill(row *config.Skill) (*CSkill, error) {
+	cs := &CSkill{
+		Cid:             int64(row.ID),
+		Name:            row.Name,
+		CastTimeMs:      int32(row.CastTimeMs),
+		ChannelTimeMs:   int32(row.ChannelTimeMs),
+		ChannelTickMs:   int32(row.ChannelTickMs),
+		GcdMs:           int32(row.GcdMs),
+		CooldownMs:      int32(row.CooldownMs),
+		GcdStartAt:      TimingPoint(row.GcdStartStage),
+		CooldownStartAt: TimingPoint(row.CooldownStartStage),
+		RangeMax:        float32(row.Range),
+
+		CanCastWhileStunned: row.CanCastWhileStunned,
+		InterruptibleByCC:   row.InterruptibleByCC,
+	}
+	if row.ResourceType != int(ResourceType_None) && row.ResourceCost > 0 {
+		cs.CostType = ResourceType(row.ResourceType)
+		cs.Cost = int64(row.ResourceCost)
+	}
+
+	if row.TargetSelectorID != 0 {
+		sel := t.Selectors[int64(row.TargetSelectorID)]
+		if sel == nil {
+			return nil, fmt.Errorf("skills[%d]: TargetSelectorID %d not found", row.ID, row.TargetSelectorID)
+		}
+		cs.Target = buildTarget(sel)
+	}
+
+	for _, effId := range row.EffectIDs {
+		se := t.SkillEffects[int64(effId)]
+		if se == nil {
+			return nil, fmt.Errorf("skills[%d]: EffectIDs %d not found", row.ID, effId)
+		}
+		eff := buildEffect(se)
+		switch se.Stage {
+		case effectStage_CastStart:
+			cs.Effects.OnCastStart = append(cs.Effects.OnCastStart, eff)
+		case effectStage_CastFinish:
+			cs.Effects.OnCastFinish = append(cs.Effects.OnCastFinish, eff)
+		case effectStage_ChannelTick:
+			cs.Effects.OnChannelTick = append(cs.Effects.OnChannelTick, eff)
+		case effectStage_Hit:
+			cs.Effects.OnHit = append(cs.Effects.OnHit, eff)
+			// 配表没有弹道系统，命中阶段的效果由 CastFinish 自动触发（延迟由 EffectCfg.DelayMs 表达）
+			cs.HitOnCastFinish = true
+		case effectStage_Cancel:
+			cs.Effects.OnCancel = append(cs.Effects.OnCancel, eff)
+		default:
+			return nil, fmt.Errorf("skillEffects[%d]: invalid Stage %d", se.ID, se.Stage)
+		}
+	}
+
+	return cs, nil
+}
+
+// buildTarget 将配表选择器转换为 TargetCfg。
+func buildTarget(sel *config.Selector) TargetCfg {
+	tc := TargetCfg{
+		Relation:    TargetRelation(sel.Relation),
+		Mode:        TargetMode(sel.Mode),
+		Radius:      float32(sel.Radius),
+		InnerRadius: float32(sel.InnerRadius),
+		Angle:       float32(sel.Angle),
+		Width:       float32(sel.Width),
+		Length:      float32(sel.Length),
+
+		MinHp:         int64(sel.MinHP),
+		MaxHp:         int64(sel.MaxHP),
+		MinHpPct:      sel.MinHPPct,
+		MaxHpPct:      sel.MaxHPPct,
+		RequireBuffId: int64(sel.RequireBuffID),
+		ExcludeBuffId: int64(sel.ExcludeBuffID),
+
+		Sort:     TargetSort(sel.Sort),
+		MaxCount: int32(sel.MaxCount),
+
+		IncludeCaster: sel.IncludeCaster,
+		IncludeDead:   sel.IncludeDead,
+	}
+
+	switch sel.Shape {
+	case selectorShape_Circle:
+		// 单体选择器配置了半径与排序时（如“血量最低友方”）为施法者周围的智能选取，否则为锁定目标
+		if tc.Radius <= 0 || (tc.Mode == TargetMode_Unit && tc.Sort == TargetSort_None) {
+			tc.Shape = ShapeType_Single
+		} else {
+			tc.Shape = ShapeType_Circle
+		}
+	case selectorShape_Cone:
+		tc.Shape = ShapeType_Cone
+		if tc.Length <= 0 {
+			tc.Length = tc.Radius
+		}
+	case selectorShape_Rect:
+		tc.Shape = ShapeType_Rect
+	case selectorShape_Ring:
+		tc.Shape = ShapeType_Ring
+	default:
+		tc.Shape = ShapeType_Invalid
+	}
+
+	return tc
+}
+
+// buildEffect 将配表技能效果转换为 EffectCfg。
+// 各类型专属字段按以下约定写入 RefId/P1~P4，配表的 P1~P4 原样放入 Args：
+//   - Damage:    RefId=DamageFormulaID, P1=ThreatValue
+//   - Heal:      RefId=HealFormulaID, P1=ThreatValue
+//   - ApplyAura: RefId=BuffID, P1=BuffStacks, P2=BuffDurationMs
+//   - Dispel/Steal: RefId=DispelType, P1=DispelCount
+//   - Move:      RefId=MoveType, P1=MoveDistance（厘米）
+//   - Summon:    RefId=SummonID
+//   - Threat:    P1=ThreatValue
+//   - SpawnArea: RefId=AreaID
+func buildEffect(se *config.SkillEffect) EffectCfg {
+	eff := EffectCfg{
+		Id:         int64(se.ID),
+		Type:       EffectType(se.EffectType),
+		DelayMs:    int32(se.DelayMs),
+		Times:      int32(se.Times),
+		IntervalMs: int32(se.IntervalMs),
+	}
+
+	switch eff.Type {
+	case EffectType_Damage:
+		eff.RefId = int64(se.DamageFormulaID)
+		eff.P1 = int64(se.ThreatValue)
+	case EffectType_Heal:
+		eff.RefId = int64(se.HealFormulaID)
+		eff.P1 = int64(se.ThreatValue)
+	case EffectType_ApplyAura:
+		eff.RefId = int64(se.BuffID)
+		eff.P1 = int64(se.BuffStacks)
+		eff.P2 = int64(se.BuffDurationMs)
+	case EffectType_Dispel, EffectType_Steal:
+		eff.RefId = int64(se.DispelType)
+		eff.P1 = int64(se.DispelCount)
+	case EffectType_Move:
+		eff.RefId = int64(se.MoveType)
+		eff.P1 = int64(se.MoveDistance * 100)
+	case EffectType_Summon:
+		eff.RefId = int64(se.SummonID)
+	case EffectType_Threat:
+		eff.P1 = int64(se.ThreatValue)
+	case EffectType_SpawnArea:
+		eff.RefId = int64(se.AreaID)
+	}
+
+	if se.P1 != 0 || se.P2 != 0 || se.P3 != 0 || se.P4 != 0 {
+		eff.Args = []int64{int64(se.P1), int64(se.P2), int64(se.P3), int64(se.P4)}
+	}
+
+	return eff
+}
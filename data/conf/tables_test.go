@@ -0,0 +1,171 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"server/data/enum"
+	config "server/data/xls"
+)
+
+func TestLoadTables_AllJson(t *testing.T) {
+	tables, err := LoadTables("../../conf/all.json")
+	if err != nil {
+		t.Fatalf("LoadTables failed: %v", err)
+	}
+
+	if len(tables.Skills) != len(tables.Raw.Skills) {
+		t.Errorf("Expected %d skills, got %d", len(tables.Raw.Skills), len(tables.Skills))
+	}
+
+	// 职业属性转换按职业分组
+	if derives := tables.GetClassDerives(1); len(derives) != 6 || derives[0].From != enum.AttrType_Constitution || derives[0].To != enum.AttrType_MaxHp {
+		t.Errorf("Unexpected class 1 derives: %+v", derives)
+	}
+	if tables.GetClassDerives(99) != nil {
+		t.Error("Expected no derives for unknown class")
+	}
+
+	// 火球术：吟唱 2 秒，单体敌方，命中阶段伤害 + 灼烧
+	fireball := tables.GetSkill(1001)
+	if fireball == nil {
+		t.Fatal("Expected skill 1001")
+	}
+	if fireball.CastTimeMs != 2000 || fireball.CooldownMs != 8000 || fireball.GcdMs != 1500 {
+		t.Errorf("Unexpected fireball timings: %+v", fireball)
+	}
+	if fireball.CooldownStartAt != TimingPoint_CastFinish || fireball.GcdStartAt != TimingPoint_CastStart {
+		t.Errorf("Unexpected fireball timing points: cd=%d gcd=%d", fireball.CooldownStartAt, fireball.GcdStartAt)
+	}
+	if fireball.CostType != ResourceType_Mp || fireball.Cost != 150 || fireball.RefundOnCancel || fireball.RangeMax != 30 {
+		t.Errorf("Expected Mp cost 150 without refund and RangeMax=30, got %d %d %v %v", fireball.CostType, fireball.Cost, fireball.RefundOnCancel, fireball.RangeMax)
+	}
+	if !fireball.InterruptibleByCC || fireball.CanCastWhileStunned {
+		t.Errorf("Expected fireball interruptible by CC, got %v %v", fireball.InterruptibleByCC, fireball.CanCastWhileStunned)
+	}
+	if fireball.Target.Mode != TargetMode_Unit || fireball.Target.Shape != ShapeType_Single || fireball.Target.Relation != TargetRelation_Enemy {
+		t.Errorf("Unexpected fireball target: %+v", fireball.Target)
+	}
+	if !fireball.HitOnCastFinish {
+		t.Error("Expected HitOnCastFinish for skill with OnHit effects")
+	}
+	if len(fireball.Effects.OnHit) != 2 {
+		t.Fatalf("Expected 2 OnHit effects, got %d", len(fireball.Effects.OnHit))
+	}
+	dmg := fireball.Effects.OnHit[0]
+	if dmg.Type != EffectType_Damage || dmg.RefId != 1001 || dmg.DelayMs != 500 {
+		t.Errorf("Unexpected damage effect: %+v", dmg)
+	}
+	aura := fireball.Effects.OnHit[1]
+	if aura.Type != EffectType_ApplyAura || aura.RefId != 2001 || aura.P1 != 1 || aura.P2 != 6000 {
+		t.Errorf("Unexpected aura effect: %+v", aura)
+	}
+
+	// 暴风雪：引导技能，效果落在 OnChannelTick，圆形范围
+	blizzard := tables.GetSkill(1003)
+	if blizzard == nil {
+		t.Fatal("Expected skill 1003")
+	}
+	if len(blizzard.Effects.OnChannelTick) != 2 {
+		t.Errorf("Expected 2 OnChannelTick effects, got %d", len(blizzard.Effects.OnChannelTick))
+	}
+	if blizzard.Target.Shape != ShapeType_Circle || blizzard.Target.Radius != 5 {
+		t.Errorf("Unexpected blizzard target: %+v", blizzard.Target)
+	}
+
+	if tables.GetBuff(2001) == nil || tables.GetBuffEffect(20001) == nil || tables.GetDamageFormula(1001) == nil {
+		t.Error("Expected buff/buffEffect/damageFormula lookups to succeed")
+	}
+	if tables.GetSkill(9999) != nil {
+		t.Error("Expected nil for unknown skill")
+	}
+}
+
+func TestNewTables_Errors(t *testing.T) {
+	dup := &AllConfig{AllConfig: config.AllConfig{
+		Buffs: []config.Buff{{ID: 1}, {ID: 1}},
+	}}
+	if _, err := NewTables(dup); err == nil {
+		t.Error("Expected error for duplicate buff ID")
+	}
+
+	missing := &AllConfig{AllConfig: config.AllConfig{
+		Skills: []config.Skill{{ID: 1, EffectIDs: []int{42}}},
+	}}
+	if _, err := NewTables(missing); err == nil {
+		t.Error("Expected error for missing skill effect")
+	}
+}
+
+func TestBuildTarget_Shape(t *testing.T) {
+	cases := []struct {
+		sel   config.Selector
+		shape ShapeType
+	}{
+		{config.Selector{Mode: int(TargetMode_Unit), Shape: selectorShape_Circle}, ShapeType_Single},
+		{config.Selector{Mode: int(TargetMode_Unit), Shape: selectorShape_Circle, Radius: 40}, ShapeType_Single},
+		{config.Selector{Mode: int(TargetMode_Unit), Shape: selectorShape_Circle, Radius: 40, Sort: int(TargetSort_LowestHp)}, ShapeType_Circle},
+		{config.Selector{Mode: int(TargetMode_Point), Shape: selectorShape_Circle, Radius: 5}, ShapeType_Circle},
+		{config.Selector{Mode: int(TargetMode_Point), Shape: selectorShape_Cone, Radius: 10}, ShapeType_Cone},
+		{config.Selector{Mode: int(TargetMode_Point), Shape: selectorShape_Rect}, ShapeType_Rect},
+		{config.Selector{Mode: int(TargetMode_Point), Shape: selectorShape_Ring}, ShapeType_Ring},
+		{config.Selector{Mode: int(TargetMode_Point), Shape: 99}, ShapeType_Invalid},
+	}
+
+	for i, c := range cases {
+		tc := buildTarget(&c.sel)
+		if tc.Shape != c.shape {
+			t.Errorf("case %d: expected shape %d, got %d", i, c.shape, tc.Shape)
+		}
+	}
+
+	cone := buildTarget(&config.Selector{Shape: selectorShape_Cone, Radius: 10})
+	if cone.Length != 10 {
+		t.Errorf("Expected cone length to default to radius, got %v", cone.Length)
+	}
+}
+
+func TestParseTables_AttrDerives(t *testing.T) {
+	// 属性转换只读自 AttrDeriveFile，all.json 中残留的同名表被忽略
+	raw := []byte(`{"attrDerives": [{"ID": 9, "ClassID": 2, "SourceAttr": 2, "TargetAttr": 16, "Ratio": 1}]}`)
+	deriveRaw := []byte(`[{"ID": 1, "ClassID": 2, "SourceAttr": 1, "TargetAttr": 13, "Ratio": 5}]`)
+	tables, err := ParseTables(raw, deriveRaw)
+	if err != nil {
+		t.Fatalf("ParseTables failed: %v", err)
+	}
+	if derives := tables.GetClassDerives(2); len(derives) != 1 || derives[0].To != enum.AttrType_MaxHp || derives[0].Ratio != 5 {
+		t.Errorf("Expected derives from %s only, got %+v", AttrDeriveFile, derives)
+	}
+
+	// 缺少属性转换表时加载失败
+	dir := t.TempDir()
+	path := filepath.Join(dir, "all.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTables(path); err == nil {
+		t.Errorf("Expected error without %s", AttrDeriveFile)
+	}
+}
+
+func TestTables_GetTargetCfg(t *testing.T) {
+	tables, err := LoadTables("../../conf/all.json")
+	if err != nil {
+		t.Fatalf("LoadTables failed: %v", err)
+	}
+
+	// 斩杀目标：单体敌方，生命低于 20%
+	execute := tables.GetTargetCfg(112)
+	if execute == nil || execute.Shape != ShapeType_Single || execute.MaxHpPct != 0.2 || execute.MaxCount != 1 {
+		t.Errorf("Unexpected execute selector: %+v", execute)
+	}
+	// 血量最低友方：施法者周围 40 码智能选取
+	lowest := tables.GetTargetCfg(110)
+	if lowest == nil || lowest.Shape != ShapeType_Circle || lowest.Sort != TargetSort_LowestHp || lowest.Radius != 40 {
+		t.Errorf("Unexpected lowest hp selector: %+v", lowest)
+	}
+	if tables.GetTargetCfg(424242) != nil {
+		t.Error("Expected nil for unknown selector")
+	}
+}
@@ -0,0 +1,392 @@
+package conf
+
+import (
+	"fmt"
+	"strings"
+
+	"server/data"
+	"server/data/enum"
+	config "server/data/xls"
+)
+
+// 配表枚举字段的合法取值范围（闭区间）。
+const (
+	skillEffectType_Min = int(EffectType_Damage)
+	skillEffectType_Max = int(EffectType_SpawnArea)
+	effectStage_Min     = effectStage_CastStart
+	effectStage_Max     = effectStage_Cancel
+	timingPoint_Max     = int(TimingPoint_CastFinish)
+	selectorMode_Min    = int(TargetMode_Unit)
+	selectorMode_Max    = int(TargetMode_NoTarget)
+	selectorRel_Min     = int(TargetRelation_Self)
+	selectorRel_Max     = int(TargetRelation_All)
+	selectorSort_Max    = int(TargetSort_Max) - 1
+	resourceType_Max    = int(ResourceType_Max) - 1
+	damageType_Min      = int(DamageType_Physical)
+	damageType_Max      = int(DamageType_True)
+	buffType_Min        = 1
+	buffType_Max        = 2
+	dispelType_Max      = 5
+	buffEffectType_Min  = int(BuffEffectType_Damage)
+	buffEffectType_Max  = int(BuffEffectType_Immunity)
+	triggerType_Min     = int(BuffTrigger_Tick)
+	triggerType_Max     = int(BuffTrigger_Max) - 1
+)
+
+// ValidationError 描述配表中的单个问题（表/行/字段）。
+type ValidationError struct {
+	Table string // 表名（与 all.json 中的 key 一致）
+	ID    int    // 行ID
+	Field string // 字段名
+	Msg   string // 问题描述
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s[%d].%s: %s", e.Table, e.ID, e.Field, e.Msg)
+}
+
+// ValidationErrors 为一次校验发现的全部问题。
+type ValidationErrors []*ValidationError
+
+func (es ValidationErrors) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "config validation failed with %d error(s):", len(es))
+	for _, e := range es {
+		sb.WriteString("\n\t")
+		sb.WriteString(e.Error())
+	}
+	return sb.String()
+}
+
+// Validate 校验整份配表：跨表ID引用、枚举范围与数值约束。
+// 返回全部问题而非遇到第一个就停止；没有问题时返回 nil。
+func Validate(all *AllConfig) ValidationErrors {
+	if all == nil {
+		return ValidationErrors{{Table: "all", Field: "-", Msg: "nil config"}}
+	}
+
+	v := &validator{
+		skillEffects:   idSet(all.SkillEffects, func(r *config.SkillEffect) int { return r.ID }),
+		selectors:      idSet(all.Selectors, func(r *config.Selector) int { return r.ID }),
+		buffs:          idSet(all.Buffs, func(r *config.Buff) int { return r.ID }),
+		buffEffects:    idSet(all.BuffEffects, func(r *config.BuffEffect) int { return r.ID }),
+		damageFormulas: idSet(all.DamageFormulas, func(r *config.DamageFormula) int { return r.ID }),
+	}
+
+	checkDuplicates(v, "skills", all.Skills, func(r *config.Skill) int { return r.ID })
+	checkDuplicates(v, "skillEffects", all.SkillEffects, func(r *config.SkillEffect) int { return r.ID })
+	checkDuplicates(v, "selectors", all.Selectors, func(r *config.Selector) int { return r.ID })
+	checkDuplicates(v, "buffs", all.Buffs, func(r *config.Buff) int { return r.ID })
+	checkDuplicates(v, "buffEffects", all.BuffEffects, func(r *config.BuffEffect) int { return r.ID })
+	checkDuplicates(v, "damageFormulas", all.DamageFormulas, func(r *config.DamageFormula) int { return r.ID })
+	checkDuplicates(v, "attrDerives", all.AttrDerives, func(r *AttrDerive) int { return r.ID })
+
+	for i := range all.Skills {
+		v.skill(&all.Skills[i])
+	}
+	for i := range all.SkillEffects {
+		v.skillEffect(&all.SkillEffects[i])
+	}
+	for i := range all.Selectors {
+		v.selector(&all.Selectors[i])
+	}
+	for i := range all.Buffs {
+		v.buff(&all.Buffs[i])
+	}
+	for i := range all.BuffEffects {
+		v.buffEffect(&all.BuffEffects[i])
+	}
+	for i := range all.DamageFormulas {
+		v.damageFormula(&all.DamageFormulas[i])
+	}
+	for i := range all.AttrDerives {
+		v.attrDerive(&all.AttrDerives[i])
+	}
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+type validator struct {
+	skillEffects   map[int]bool
+	selectors      map[int]bool
+	buffs          map[int]bool
+	buffEffects    map[int]bool
+	damageFormulas map[int]bool
+
+	errs ValidationErrors
+}
+
+func idSet[V any](rows []V, id func(*V) int) map[int]bool {
+	set := make(map[int]bool, len(rows))
+	for i := range rows {
+		set[id(&rows[i])] = true
+	}
+	return set
+}
+
+// checkDuplicates 校验表内ID为正且不重复。
+func checkDuplicates[V any](v *validator, table string, rows []V, id func(*V) int) {
+	seen := make(map[int]bool, len(rows))
+	for i := range rows {
+		rid := id(&rows[i])
+		if rid <= 0 {
+			v.addf(table, rid, "ID", "must be positive")
+		}
+		if seen[rid] {
+			v.addf(table, rid, "ID", "duplicate ID")
+		}
+		seen[rid] = true
+	}
+}
+
+func (v *validator) addf(table string, id int, field string, format string, args ...any) {
+	v.errs = append(v.errs, &ValidationError{Table: table, ID: id, Field: field, Msg: fmt.Sprintf(format, args...)})
+}
+
+// ref 校验可选引用：0 表示未引用，非 0 必须存在于目标表。
+func (v *validator) ref(table string, id int, field string, ref int, set map[int]bool, target string) {
+	if ref != 0 && !set[ref] {
+		v.addf(table, id, field, "%s %d not found", target, ref)
+	}
+}
+
+// required 校验必填引用：必须非 0 且存在于目标表。
+func (v *validator) required(table string, id int, field string, ref int, set map[int]bool, target string) {
+	if ref == 0 {
+		v.addf(table, id, field, "required")
+		return
+	}
+	v.ref(table, id, field, ref, set, target)
+}
+
+func (v *validator) intRange(table string, id int, field string, val, lo, hi int) {
+	if val < lo || val > hi {
+		v.addf(table, id, field, "%d out of range [%d, %d]", val, lo, hi)
+	}
+}
+
+func (v *validator) nonNegative(table string, id int, field string, val float64) {
+	if val < 0 {
+		v.addf(table, id, field, "%v must be >= 0", val)
+	}
+}
+
+func (v *validator) ratio(table string, id int, field string, val float64) {
+	if val < 0 || val > 1 {
+		v.addf(table, id, field, "%v out of range [0, 1]", val)
+	}
+}
+
+func (v *validator) skill(r *config.Skill) {
+	const t = "skills"
+	v.ref(t, r.ID, "TargetSelectorID", r.TargetSelectorID, v.selectors, "selector")
+	for _, effId := range r.EffectIDs {
+		v.required(t, r.ID, "EffectIDs", effId, v.skillEffects, "skillEffect")
+	}
+	v.ref(t, r.ID, "RequireBuffID", r.RequireBuffID, v.buffs, "buff")
+	v.ref(t, r.ID, "ConsumeBuffID", r.ConsumeBuffID, v.buffs, "buff")
+
+	v.intRange(t, r.ID, "CooldownStartStage", r.CooldownStartStage, 0, timingPoint_Max)
+	v.intRange(t, r.ID, "GcdStartStage", r.GcdStartStage, 0, timingPoint_Max)
+	v.intRange(t, r.ID, "ResourceType", r.ResourceType, 0, resourceType_Max)
+
+	v.nonNegative(t, r.ID, "CooldownMs", float64(r.CooldownMs))
+	v.nonNegative(t, r.ID, "GcdMs", float64(r.GcdMs))
+	v.nonNegative(t, r.ID, "CastTimeMs", float64(r.CastTimeMs))
+	v.nonNegative(t, r.ID, "ChannelTimeMs", float64(r.ChannelTimeMs))
+	v.nonNegative(t, r.ID, "ChannelTickMs", float64(r.ChannelTickMs))
+	v.nonNegative(t, r.ID, "Range", r.Range)
+	v.nonNegative(t, r.ID, "ResourceCost", float64(r.ResourceCost))
+	if r.MaxLevel < 1 {
+		v.addf(t, r.ID, "MaxLevel", "%d must be >= 1", r.MaxLevel)
+	}
+	if r.SchoolMask < 0 || DamageSchool(r.SchoolMask)&^DamageSchool_All != 0 {
+		v.addf(t, r.ID, "SchoolMask", "%d has unknown school bits", r.SchoolMask)
+	}
+	if r.ChannelTickMs > 0 && r.ChannelTickMs > r.ChannelTimeMs {
+		v.addf(t, r.ID, "ChannelTickMs", "%d exceeds ChannelTimeMs %d", r.ChannelTickMs, r.ChannelTimeMs)
+	}
+}
+
+func (v *validator) skillEffect(r *config.SkillEffect) {
+	const t = "skillEffects"
+	v.intRange(t, r.ID, "EffectType", r.EffectType, skillEffectType_Min, skillEffectType_Max)
+	v.intRange(t, r.ID, "Stage", r.Stage, effectStage_Min, effectStage_Max)
+	if r.Times < 1 {
+		v.addf(t, r.ID, "Times", "%d must be >= 1", r.Times)
+	}
+	v.nonNegative(t, r.ID, "IntervalMs", float64(r.IntervalMs))
+	v.nonNegative(t, r.ID, "DelayMs", float64(r.DelayMs))
+	v.nonNegative(t, r.ID, "BuffDurationMs", float64(r.BuffDurationMs))
+	v.nonNegative(t, r.ID, "BuffStacks", float64(r.BuffStacks))
+	v.nonNegative(t, r.ID, "DispelCount", float64(r.DispelCount))
+
+	switch EffectType(r.EffectType) {
+	case EffectType_Damage:
+		v.required(t, r.ID, "DamageFormulaID", r.DamageFormulaID, v.damageFormulas, "damageFormula")
+	case EffectType_Heal:
+		v.required(t, r.ID, "HealFormulaID", r.HealFormulaID, v.damageFormulas, "damageFormula")
+	case EffectType_ApplyAura:
+		v.required(t, r.ID, "BuffID", r.BuffID, v.buffs, "buff")
+	default:
+		v.ref(t, r.ID, "DamageFormulaID", r.DamageFormulaID, v.damageFormulas, "damageFormula")
+		v.ref(t, r.ID, "HealFormulaID", r.HealFormulaID, v.damageFormulas, "damageFormula")
+		v.ref(t, r.ID, "BuffID", r.BuffID, v.buffs, "buff")
+	}
+}
+
+func (v *validator) selector(r *config.Selector) {
+	const t = "selectors"
+	v.intRange(t, r.ID, "Mode", r.Mode, selectorMode_Min, selectorMode_Max)
+	v.intRange(t, r.ID, "Relation", r.Relation, selectorRel_Min, selectorRel_Max)
+
+	shape := buildTarget(r).Shape
+	if shape == ShapeType_Invalid {
+		v.addf(t, r.ID, "Shape", "%d is not a valid shape", r.Shape)
+	}
+
+	v.nonNegative(t, r.ID, "Radius", r.Radius)
+	v.nonNegative(t, r.ID, "InnerRadius", r.InnerRadius)
+	v.nonNegative(t, r.ID, "Width", r.Width)
+	v.nonNegative(t, r.ID, "Length", r.Length)
+	v.nonNegative(t, r.ID, "MaxCount", float64(r.MaxCount))
+	v.intRange(t, r.ID, "Sort", r.Sort, 0, selectorSort_Max)
+	if r.Angle < 0 || r.Angle > 360 {
+		v.addf(t, r.ID, "Angle", "%v out of range [0, 360]", r.Angle)
+	}
+
+	switch shape {
+	case ShapeType_Cone:
+		if r.Angle <= 0 {
+			v.addf(t, r.ID, "Angle", "cone requires Angle > 0")
+		}
+		if r.Radius <= 0 && r.Length <= 0 {
+			v.addf(t, r.ID, "Radius", "cone requires Radius or Length > 0")
+		}
+	case ShapeType_Rect:
+		if r.Width <= 0 || r.Length <= 0 {
+			v.addf(t, r.ID, "Width", "rect requires Width and Length > 0")
+		}
+	case ShapeType_Ring:
+		if r.Radius <= r.InnerRadius {
+			v.addf(t, r.ID, "InnerRadius", "%v must be < Radius %v", r.InnerRadius, r.Radius)
+		}
+	}
+
+	v.nonNegative(t, r.ID, "MinHP", float64(r.MinHP))
+	if r.MaxHP > 0 && r.MinHP > r.MaxHP {
+		v.addf(t, r.ID, "MinHP", "%d exceeds MaxHP %d", r.MinHP, r.MaxHP)
+	}
+	v.ratio(t, r.ID, "MinHPPct", r.MinHPPct)
+	v.ratio(t, r.ID, "MaxHPPct", r.MaxHPPct)
+	if r.MaxHPPct > 0 && r.MinHPPct > r.MaxHPPct {
+		v.addf(t, r.ID, "MinHPPct", "%v exceeds MaxHPPct %v", r.MinHPPct, r.MaxHPPct)
+	}
+
+	v.ref(t, r.ID, "RequireBuffID", r.RequireBuffID, v.buffs, "buff")
+	v.ref(t, r.ID, "ExcludeBuffID", r.ExcludeBuffID, v.buffs, "buff")
+	if r.RequireBuffID != 0 && r.RequireBuffID == r.ExcludeBuffID {
+		v.addf(t, r.ID, "ExcludeBuffID", "same as RequireBuffID %d", r.RequireBuffID)
+	}
+}
+
+func (v *validator) buff(r *config.Buff) {
+	const t = "buffs"
+	v.intRange(t, r.ID, "BuffType", r.BuffType, buffType_Min, buffType_Max)
+	v.intRange(t, r.ID, "DispelType", r.DispelType, 0, dispelType_Max)
+	v.nonNegative(t, r.ID, "DurationMs", float64(r.DurationMs))
+	v.nonNegative(t, r.ID, "Priority", float64(r.Priority))
+	v.intRange(t, r.ID, "StackRule", r.StackRule, int(StackRule_Refresh), int(StackRule_Max)-1)
+	if r.MaxStacks <= 0 {
+		v.addf(t, r.ID, "MaxStacks", "%d must be > 0", r.MaxStacks)
+	} else if r.MaxStacks > 1 && !StackRule(r.StackRule).Stackable() {
+		v.addf(t, r.ID, "MaxStacks", "%d requires StackRule AddStack or PerCaster", r.MaxStacks)
+	}
+	if BuffHasTag(r, BuffTag_GroupPrefix) {
+		v.addf(t, r.ID, "Tags", "empty group name")
+	}
+	if len(r.EffectIDs) == 0 {
+		v.addf(t, r.ID, "EffectIDs", "empty")
+	}
+	for _, effId := range r.EffectIDs {
+		v.required(t, r.ID, "EffectIDs", effId, v.buffEffects, "buffEffect")
+	}
+}
+
+func (v *validator) buffEffect(r *config.BuffEffect) {
+	const t = "buffEffects"
+	v.intRange(t, r.ID, "EffectType", r.EffectType, buffEffectType_Min, buffEffectType_Max)
+	if ty := BuffEffectType(r.EffectType); ty > BuffEffectType_Haste && ty < BuffEffectType_Immunity {
+		v.addf(t, r.ID, "EffectType", "%d is reserved", r.EffectType)
+	}
+	v.intRange(t, r.ID, "TriggerType", r.TriggerType, triggerType_Min, triggerType_Max)
+	v.intRange(t, r.ID, "CCType", r.CCType, 0, int(CCType_Max)-1)
+	v.nonNegative(t, r.ID, "TickIntervalMs", float64(r.TickIntervalMs))
+	v.nonNegative(t, r.ID, "MaxTicks", float64(r.MaxTicks))
+	v.nonNegative(t, r.ID, "CooldownMs", float64(r.CooldownMs))
+	v.nonNegative(t, r.ID, "ShieldAmount", float64(r.ShieldAmount))
+	v.ratio(t, r.ID, "TriggerChance", r.TriggerChance)
+	if BuffTrigger(r.TriggerType) == BuffTrigger_Tick && r.TickIntervalMs <= 0 {
+		v.addf(t, r.ID, "TickIntervalMs", "periodic trigger requires TickIntervalMs > 0")
+	}
+	if BuffEffectType(r.EffectType) == BuffEffectType_Attribute {
+		if !enum.AttrType(r.AttributeType).IsValid() {
+			v.addf(t, r.ID, "AttributeType", "%d is not a defined attribute", r.AttributeType)
+		}
+		v.intRange(t, r.ID, "ModType", r.ModType, int(data.AttrModType_Flat), int(data.AttrModType_Max)-1)
+	}
+	if BuffEffectType(r.EffectType) == BuffEffectType_Shield {
+		if r.ShieldAmount <= 0 && r.HealFormulaID <= 0 {
+			v.addf(t, r.ID, "ShieldAmount", "shield requires ShieldAmount > 0 or HealFormulaID")
+		}
+		if r.DamageFormulaID != 0 {
+			v.addf(t, r.ID, "DamageFormulaID", "shield formula must be set in HealFormulaID")
+		}
+		if r.P1 < 0 || DamageSchool(r.P1)&^DamageSchool_All != 0 {
+			v.addf(t, r.ID, "P1", "%d has unknown school bits", r.P1)
+		}
+	}
+	if r.MoveSpeedPct < -1 {
+		v.addf(t, r.ID, "MoveSpeedPct", "%v must be >= -1", r.MoveSpeedPct)
+	}
+	v.ref(t, r.ID, "DamageFormulaID", r.DamageFormulaID, v.damageFormulas, "damageFormula")
+	v.ref(t, r.ID, "HealFormulaID", r.HealFormulaID, v.damageFormulas, "damageFormula")
+}
+
+func (v *validator) damageFormula(r *config.DamageFormula) {
+	const t = "damageFormulas"
+	v.intRange(t, r.ID, "DamageType", r.DamageType, damageType_Min, damageType_Max)
+	if r.School < 0 || DamageSchool(r.School)&^DamageSchool_All != 0 {
+		v.addf(t, r.ID, "School", "%d has unknown school bits", r.School)
+	}
+	v.nonNegative(t, r.ID, "BaseDamage", float64(r.BaseDamage))
+	v.nonNegative(t, r.ID, "MinDamage", float64(r.MinDamage))
+	v.nonNegative(t, r.ID, "MaxDamage", float64(r.MaxDamage))
+	v.nonNegative(t, r.ID, "SplashRadius", r.SplashRadius)
+	v.ratio(t, r.ID, "ExecuteThreshold", r.ExecuteThreshold)
+	v.ratio(t, r.ID, "IgnoreArmorPct", r.IgnoreArmorPct)
+	v.ratio(t, r.ID, "SplashDamagePct", r.SplashDamagePct)
+	v.nonNegative(t, r.ID, "ExecuteBonus", r.ExecuteBonus)
+	if r.MaxDamage > 0 && r.MinDamage > r.MaxDamage {
+		v.addf(t, r.ID, "MinDamage", "%d exceeds MaxDamage %d", r.MinDamage, r.MaxDamage)
+	}
+	if r.CanCrit && r.CritMultiplier < 1 {
+		v.addf(t, r.ID, "CritMultiplier", "%v must be >= 1 when CanCrit", r.CritMultiplier)
+	}
+}
+
+func (v *validator) attrDerive(r *AttrDerive) {
+	const t = "attrDerives"
+	v.nonNegative(t, r.ID, "ClassID", float64(r.ClassID))
+	v.nonNegative(t, r.ID, "Ratio", r.Ratio)
+	if !enum.AttrType(r.SourceAttr).IsPrimary() {
+		v.addf(t, r.ID, "SourceAttr", "%d is not a primary attribute", r.SourceAttr)
+	}
+	// 转换目标不能是主属性（避免成环），也不能是当前生命/法力
+	if ty := enum.AttrType(r.TargetAttr); ty <= enum.AttrType_Invalid || ty.IsPrimary() || ty >= enum.AttrType_Hp {
+		v.addf(t, r.ID, "TargetAttr", "%d is not a derivable attribute", r.TargetAttr)
+	}
+}
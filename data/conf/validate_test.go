@@ -0,0 +1,115 @@
+package conf
+
+import (
+	"errors"
+	"testing"
+
+	"server/data/enum"
+	config "server/data/xls"
+)
+
+func TestValidate_AllJson(t *testing.T) {
+	tables, err := LoadTables("../../conf/all.json")
+	if err != nil {
+		t.Fatalf("Expected all.json to be valid, got: %v", err)
+	}
+	if errs := Validate(tables.Raw); errs != nil {
+		t.Errorf("Expected no validation errors, got: %v", errs)
+	}
+}
+
+func TestValidate_ReportsAll(t *testing.T) {
+	all := &AllConfig{
+		AllConfig: config.AllConfig{
+			Skills: []config.Skill{
+				{ID: 1, MaxLevel: 1, TargetSelectorID: 77, EffectIDs: []int{10, 11}},
+			},
+			SkillEffects: []config.SkillEffect{
+				{ID: 10, EffectType: int(EffectType_Damage), Stage: 4, Times: 0, IntervalMs: -1, DamageFormulaID: 5},
+			},
+			Selectors: []config.Selector{
+				{ID: 20, Mode: 2, Relation: 3, Shape: 9, MinHPPct: 0.5, MaxHPPct: 0.2, Sort: 99},
+			},
+			Buffs: []config.Buff{
+				{ID: 30, BuffType: 1, MaxStacks: 0, EffectIDs: []int{40}},
+				{ID: 31, BuffType: 1, StackRule: int(StackRule_Refresh), MaxStacks: 2, EffectIDs: []int{40}, Tags: "Buff|" + BuffTag_GroupPrefix},
+			},
+			BuffEffects: []config.BuffEffect{
+				{ID: 40, EffectType: int(BuffEffectType_Damage), TriggerType: 1, TriggerChance: 1, DamageFormulaID: 99},
+				{ID: 41, EffectType: int(BuffEffectType_Attribute), TriggerType: int(BuffTrigger_Passive), TriggerChance: 1, ModType: 9},
+				{ID: 42, EffectType: int(BuffEffectType_Shield), TriggerType: int(BuffTrigger_Passive), TriggerChance: 1, P1: 1 << 9},
+				{ID: 43, EffectType: 9, TriggerType: int(BuffTrigger_Passive), TriggerChance: 1},
+				{ID: 44, EffectType: int(BuffEffectType_Attribute), TriggerType: int(BuffTrigger_Passive), TriggerChance: 1, AttributeType: 10, ModType: 1},
+				{ID: 45, EffectType: int(BuffEffectType_Shield), TriggerType: int(BuffTrigger_Passive), TriggerChance: 1, ShieldAmount: 100, DamageFormulaID: 60},
+			},
+			DamageFormulas: []config.DamageFormula{
+				{ID: 60, DamageType: int(DamageType_Magic)},
+			},
+		},
+		AttrDerives: []AttrDerive{
+			{ID: 50, ClassID: 1, SourceAttr: int(enum.AttrType_MaxHp), TargetAttr: int(enum.AttrType_Strength), Ratio: 1},
+		},
+	}
+
+	errs := Validate(all)
+	expected := []struct {
+		table string
+		id    int
+		field string
+	}{
+		{"skills", 1, "TargetSelectorID"},
+		{"skills", 1, "EffectIDs"},
+		{"skillEffects", 10, "Times"},
+		{"skillEffects", 10, "IntervalMs"},
+		{"skillEffects", 10, "DamageFormulaID"},
+		{"selectors", 20, "Shape"},
+		{"selectors", 20, "MinHPPct"},
+		{"selectors", 20, "Sort"},
+		{"buffs", 30, "StackRule"},
+		{"buffs", 30, "MaxStacks"},
+		{"buffs", 31, "MaxStacks"},
+		{"buffs", 31, "Tags"},
+		{"buffEffects", 40, "TickIntervalMs"},
+		{"buffEffects", 40, "DamageFormulaID"},
+		{"buffEffects", 41, "AttributeType"},
+		{"buffEffects", 41, "ModType"},
+		{"buffEffects", 42, "ShieldAmount"},
+		{"buffEffects", 42, "P1"},
+		{"buffEffects", 43, "EffectType"},
+		{"buffEffects", 44, "AttributeType"},
+		{"buffEffects", 45, "DamageFormulaID"},
+		{"attrDerives", 50, "SourceAttr"},
+		{"attrDerives", 50, "TargetAttr"},
+	}
+
+	for _, e := range expected {
+		found := false
+		for _, got := range errs {
+			if got.Table == e.table && got.ID == e.id && got.Field == e.field {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected error for %s[%d].%s, got: %v", e.table, e.id, e.field, errs)
+		}
+	}
+	if len(errs) != len(expected) {
+		t.Errorf("Expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+}
+
+func TestNewTables_ReturnsValidationErrors(t *testing.T) {
+	all := &AllConfig{AllConfig: config.AllConfig{
+		DamageFormulas: []config.DamageFormula{{ID: 1, DamageType: 0, MinDamage: 10, MaxDamage: 5}},
+	}}
+
+	_, err := NewTables(all)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+	if len(errs) != 2 {
+		t.Errorf("Expected 2 errors, got %d: %v", len(errs), errs)
+	}
+}
@@ -1,5 +1,7 @@
 package data
 
 type EntityInitData struct {
-	Attrs *Attrs
+	Attrs   *Attrs
+	Faction Faction
+	ClassId int32 // 职业ID，决定主属性到次级属性的转换系数（见 conf/attr_derive.json）
 }
@@ -6,6 +6,24 @@ func (a AttrType) Int32() int32 {
 	return int32(a)
 }
 
+// IsPrimary 判断是否为主属性（体质、力量、智力、耐力、敏捷），次级属性可由主属性转换得到。
+func (a AttrType) IsPrimary() bool {
+	return a >= AttrType_Constitution && a <= AttrType_Agility
+}
+
+// IsValid 判断是否为已定义的属性。
+func (a AttrType) IsValid() bool {
+	switch {
+	case a.IsPrimary(),
+		a >= AttrType_MaxHp && a <= AttrType_MagicDefense,
+		a >= AttrType_PhyDamageBonus && a <= AttrType_MagicDefensePenetrationRate,
+		a >= AttrType_PhyCritRate && a <= AttrType_ControlDodgeRate,
+		a == AttrType_Hp, a == AttrType_Mp:
+		return true
+	}
+	return false
+}
+
 const (
 	AttrType_Invalid                        AttrType = 0   // 无效属性
 	AttrType_Constitution                   AttrType = 1   // 体质
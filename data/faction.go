@@ -0,0 +1,11 @@
+package data
+
+import "server/lib/uid"
+
+// Faction 为实体的阵营数据，用于判定实体之间的敌友关系。
+type Faction struct {
+	Camp    int32   // 阵营ID（0 表示无阵营，与所有实体中立）
+	TeamId  int64   // 队伍ID（0 表示无队伍），同队实体总是友方
+	Pvp     bool    // 是否开启 PvP，同阵营双方均开启且不同队时互为敌方
+	OwnerId uid.Uid // 召唤者ID（非召唤物为 0），召唤物继承召唤者的敌友关系
+}
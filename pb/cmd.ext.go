@@ -65,6 +65,8 @@ func (p *parser) Load() {
 	p.Register(EKey_PingXXX, func() proto.Message { return &ReqPingXXX{} })
 	p.Register(EKey_EnterScene, func() proto.Message { return &ReqEnterScene{} })
 	p.Register(EKey_TestEnter, func() proto.Message { return &ReqTestEnter{} })
+	p.Register(EKey_CastSkill, func() proto.Message { return &ReqCastSkill{} })
+	p.Register(EKey_Move, func() proto.Message { return &ReqMove{} })
 	p.Register(EKey_Login, func() proto.Message { return &RspLogin{} })
 	p.Register(EKey_CreateRole, func() proto.Message { return &RspCreateRole{} })
 	p.Register(EKey_DeleteRole, func() proto.Message { return &RspDeleteRole{} })
@@ -73,12 +75,17 @@ func (p *parser) Load() {
 	p.Register(EKey_PingXXX, func() proto.Message { return &RspPingXXX{} })
 	p.Register(EKey_EnterScene, func() proto.Message { return &RspEnterScene{} })
 	p.Register(EKey_TestEnter, func() proto.Message { return &RspTestEnter{} })
+	p.Register(EKey_CastSkill, func() proto.Message { return &RspCastSkill{} })
 	p.Register(EKey_LoginFast, func() proto.Message { return &DspLoginFast{} })
 	p.Register(EKey_LoginData, func() proto.Message { return &DspLoginData{} })
 	p.Register(EKey_ServerMaintain, func() proto.Message { return &DspServerMaintain{} })
 	p.Register(EKey_KickRole, func() proto.Message { return &DspKickRole{} })
 	p.Register(EKey_PreparedEnterScene, func() proto.Message { return &DspPreparedEnterScene{} })
 	p.Register(EKey_Test, func() proto.Message { return &DspTest{} })
+	p.Register(EKey_EntityAppear, func() proto.Message { return &DspEntityAppear{} })
+	p.Register(EKey_EntityDisappear, func() proto.Message { return &DspEntityDisappear{} })
+	p.Register(EKey_EntityMove, func() proto.Message { return &DspEntityMove{} })
+	p.Register(EKey_MoveSnap, func() proto.Message { return &DspMoveSnap{} })
 }
 
 func (msg *ReqLogin) Key() EKey_T {
@@ -113,6 +120,14 @@ func (msg *ReqTestEnter) Key() EKey_T {
 	return EKey_TestEnter
 }
 
+func (msg *ReqCastSkill) Key() EKey_T {
+	return EKey_CastSkill
+}
+
+func (msg *ReqMove) Key() EKey_T {
+	return EKey_Move
+}
+
 func (msg *RspLogin) Key() EKey_T {
 	return EKey_Login
 }
@@ -145,6 +160,10 @@ func (msg *RspTestEnter) Key() EKey_T {
 	return EKey_TestEnter
 }
 
+func (msg *RspCastSkill) Key() EKey_T {
+	return EKey_CastSkill
+}
+
 func (msg *DspLoginFast) Key() EKey_T {
 	return EKey_LoginFast
 }
@@ -169,3 +188,19 @@ func (msg *DspTest) Key() EKey_T {
 	return EKey_Test
 }
 
+func (msg *DspEntityAppear) Key() EKey_T {
+	return EKey_EntityAppear
+}
+
+func (msg *DspEntityDisappear) Key() EKey_T {
+	return EKey_EntityDisappear
+}
+
+func (msg *DspEntityMove) Key() EKey_T {
+	return EKey_EntityMove
+}
+
+func (msg *DspMoveSnap) Key() EKey_T {
+	return EKey_MoveSnap
+}
+
@@ -34,6 +34,8 @@ const (
 	EKey_PingXXX    EKey_T = 11 // 心跳(客户端切换后台发送该消息)
 	EKey_EnterScene EKey_T = 20 // 通知服务器已经进入场景
 	EKey_TestEnter  EKey_T = 21 // ceshi
+	EKey_CastSkill  EKey_T = 22 // 释放技能
+	EKey_Move       EKey_T = 23 // 移动
 	// dsp start
 	EKey_LoginFast          EKey_T = 40000 // 同步快速重登 token
 	EKey_LoginData          EKey_T = 40001 // 同步玩家登录数据
@@ -41,6 +43,10 @@ const (
 	EKey_KickRole           EKey_T = 40003 // 踢玩家下线
 	EKey_PreparedEnterScene EKey_T = 40004 // 准备进入场景
 	EKey_Test               EKey_T = 40005 // 测试
+	EKey_EntityAppear       EKey_T = 40006 // 实体进入视野
+	EKey_EntityDisappear    EKey_T = 40007 // 实体离开视野
+	EKey_EntityMove         EKey_T = 40008 // 视野内实体移动
+	EKey_MoveSnap           EKey_T = 40009 // 移动被拒绝，拉回权威位置
 	// 0xF000 及以上为服务器保留用
 	EKey_Max EKey_T = 65535
 )
@@ -57,12 +63,18 @@ var (
 		11:    "PingXXX",
 		20:    "EnterScene",
 		21:    "TestEnter",
+		22:    "CastSkill",
+		23:    "Move",
 		40000: "LoginFast",
 		40001: "LoginData",
 		40002: "ServerMaintain",
 		40003: "KickRole",
 		40004: "PreparedEnterScene",
 		40005: "Test",
+		40006: "EntityAppear",
+		40007: "EntityDisappear",
+		40008: "EntityMove",
+		40009: "MoveSnap",
 		65535: "Max",
 	}
 	EKey_T_value = map[string]int32{
@@ -75,12 +87,18 @@ var (
 		"PingXXX":            11,
 		"EnterScene":         20,
 		"TestEnter":          21,
+		"CastSkill":          22,
+		"Move":               23,
 		"LoginFast":          40000,
 		"LoginData":          40001,
 		"ServerMaintain":     40002,
 		"KickRole":           40003,
 		"PreparedEnterScene": 40004,
 		"Test":               40005,
+		"EntityAppear":       40006,
+		"EntityDisappear":    40007,
+		"EntityMove":         40008,
+		"MoveSnap":           40009,
 		"Max":                65535,
 	}
 )
@@ -152,8 +170,8 @@ var File_cmd_proto protoreflect.FileDescriptor
 
 const file_cmd_proto_rawDesc = "" +
 	"\n" +
-	"\tcmd.proto\x12\x02pb\"\x82\x02\n" +
-	"\x04EKey\"\xf9\x01\n" +
+	"\tcmd.proto\x12\x02pb\"\xe8\x02\n" +
+	"\x04EKey\"\xdf\x02\n" +
 	"\x01T\x12\v\n" +
 	"\aInvalid\x10\x00\x12\t\n" +
 	"\x05Login\x10\x01\x12\x0e\n" +
@@ -167,14 +185,21 @@ const file_cmd_proto_rawDesc = "" +
 	"\aPingXXX\x10\v\x12\x0e\n" +
 	"\n" +
 	"EnterScene\x10\x14\x12\r\n" +
-	"\tTestEnter\x10\x15\x12\x0f\n" +
+	"\tTestEnter\x10\x15\x12\r\n" +
+	"\tCastSkill\x10\x16\x12\b\n" +
+	"\x04Move\x10\x17\x12\x0f\n" +
 	"\tLoginFast\x10\xc0\xb8\x02\x12\x0f\n" +
 	"\tLoginData\x10\xc1\xb8\x02\x12\x14\n" +
 	"\x0eServerMaintain\x10¸\x02\x12\x0e\n" +
 	"\bKickRole\x10ø\x02\x12\x18\n" +
 	"\x12PreparedEnterScene\x10ĸ\x02\x12\n" +
 	"\n" +
-	"\x04Test\x10Ÿ\x02\x12\t\n" +
+	"\x04Test\x10Ÿ\x02\x12\x12\n" +
+	"\fEntityAppear\x10Ƹ\x02\x12\x15\n" +
+	"\x0fEntityDisappear\x10Ǹ\x02\x12\x10\n" +
+	"\n" +
+	"EntityMove\x10ȸ\x02\x12\x0e\n" +
+	"\bMoveSnap\x10ɸ\x02\x12\t\n" +
 	"\x03Max\x10\xff\xff\x03B\vZ\tserver/pbb\x06proto3"
 
 var (
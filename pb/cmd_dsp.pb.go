@@ -313,6 +313,210 @@ func (*DspTest) Descriptor() ([]byte, []int) {
 	return file_cmd_dsp_proto_rawDescGZIP(), []int{5}
 }
 
+// 实体进入视野（每帧合并为一条）
+type DspEntityAppear struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entities      []*AoiEntity           `protobuf:"bytes,1,rep,name=entities,proto3" json:"entities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DspEntityAppear) Reset() {
+	*x = DspEntityAppear{}
+	mi := &file_cmd_dsp_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DspEntityAppear) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DspEntityAppear) ProtoMessage() {}
+
+func (x *DspEntityAppear) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_dsp_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DspEntityAppear.ProtoReflect.Descriptor instead.
+func (*DspEntityAppear) Descriptor() ([]byte, []int) {
+	return file_cmd_dsp_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DspEntityAppear) GetEntities() []*AoiEntity {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+// 实体离开视野（每帧合并为一条）
+type DspEntityDisappear struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []int64                `protobuf:"zigzag64,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DspEntityDisappear) Reset() {
+	*x = DspEntityDisappear{}
+	mi := &file_cmd_dsp_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DspEntityDisappear) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DspEntityDisappear) ProtoMessage() {}
+
+func (x *DspEntityDisappear) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_dsp_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DspEntityDisappear.ProtoReflect.Descriptor instead.
+func (*DspEntityDisappear) Descriptor() ([]byte, []int) {
+	return file_cmd_dsp_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DspEntityDisappear) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+// 视野内实体移动（每帧合并为一条）
+type DspEntityMove struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Moves         []*AoiMove             `protobuf:"bytes,1,rep,name=moves,proto3" json:"moves,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DspEntityMove) Reset() {
+	*x = DspEntityMove{}
+	mi := &file_cmd_dsp_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DspEntityMove) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DspEntityMove) ProtoMessage() {}
+
+func (x *DspEntityMove) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_dsp_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DspEntityMove.ProtoReflect.Descriptor instead.
+func (*DspEntityMove) Descriptor() ([]byte, []int) {
+	return file_cmd_dsp_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DspEntityMove) GetMoves() []*AoiMove {
+	if x != nil {
+		return x.Moves
+	}
+	return nil
+}
+
+// 服务端拒绝客户端移动时将其拉回权威位置（只发给移动者本人）
+type DspMoveSnap struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"zigzag64,1,opt,name=id,proto3" json:"id,omitempty"`   // 实体ID
+	Seq           int64                  `protobuf:"zigzag64,2,opt,name=seq,proto3" json:"seq,omitempty"` // 被拒绝的移动序号
+	Pos           *Vector                `protobuf:"bytes,3,opt,name=pos,proto3" json:"pos,omitempty"`    // 权威位置
+	Dir           int32                  `protobuf:"zigzag32,4,opt,name=dir,proto3" json:"dir,omitempty"` // 权威朝向
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DspMoveSnap) Reset() {
+	*x = DspMoveSnap{}
+	mi := &file_cmd_dsp_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DspMoveSnap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DspMoveSnap) ProtoMessage() {}
+
+func (x *DspMoveSnap) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_dsp_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DspMoveSnap.ProtoReflect.Descriptor instead.
+func (*DspMoveSnap) Descriptor() ([]byte, []int) {
+	return file_cmd_dsp_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DspMoveSnap) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DspMoveSnap) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *DspMoveSnap) GetPos() *Vector {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *DspMoveSnap) GetDir() int32 {
+	if x != nil {
+		return x.Dir
+	}
+	return 0
+}
+
 var File_cmd_dsp_proto protoreflect.FileDescriptor
 
 const file_cmd_dsp_proto_rawDesc = "" +
@@ -335,7 +539,19 @@ const file_cmd_dsp_proto_rawDesc = "" +
 	"\x02ty\x18\x01 \x01(\x0e2\x0f.pb.EKickType.TR\x02ty\x12\x17\n" +
 	"\asess_id\x18\x02 \x01(\x12R\x06sessId\"\x17\n" +
 	"\x15DspPreparedEnterScene\"\t\n" +
-	"\aDspTestB\vZ\tserver/pbb\x06proto3"
+	"\aDspTest\"<\n" +
+	"\x0fDspEntityAppear\x12)\n" +
+	"\bentities\x18\x01 \x03(\v2\r.pb.AoiEntityR\bentities\"&\n" +
+	"\x12DspEntityDisappear\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x12R\x03ids\"2\n" +
+	"\rDspEntityMove\x12!\n" +
+	"\x05moves\x18\x01 \x03(\v2\v.pb.AoiMoveR\x05moves\"_\n" +
+	"\vDspMoveSnap\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x12R\x02id\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x12R\x03seq\x12\x1c\n" +
+	"\x03pos\x18\x03 \x01(\v2\n" +
+	".pb.VectorR\x03pos\x12\x10\n" +
+	"\x03dir\x18\x04 \x01(\x11R\x03dirB\vZ\tserver/pbb\x06proto3"
 
 var (
 	file_cmd_dsp_proto_rawDescOnce sync.Once
@@ -349,7 +565,7 @@ func file_cmd_dsp_proto_rawDescGZIP() []byte {
 	return file_cmd_dsp_proto_rawDescData
 }
 
-var file_cmd_dsp_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_cmd_dsp_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_cmd_dsp_proto_goTypes = []any{
 	(*DspLoginFast)(nil),          // 0: pb.DspLoginFast
 	(*DspLoginData)(nil),          // 1: pb.DspLoginData
@@ -357,19 +573,29 @@ var file_cmd_dsp_proto_goTypes = []any{
 	(*DspKickRole)(nil),           // 3: pb.DspKickRole
 	(*DspPreparedEnterScene)(nil), // 4: pb.DspPreparedEnterScene
 	(*DspTest)(nil),               // 5: pb.DspTest
-	(ESignInFastType_T)(0),        // 6: pb.ESignInFastType.T
-	(*LoginData)(nil),             // 7: pb.LoginData
-	(EKickType_T)(0),              // 8: pb.EKickType.T
+	(*DspEntityAppear)(nil),       // 6: pb.DspEntityAppear
+	(*DspEntityDisappear)(nil),    // 7: pb.DspEntityDisappear
+	(*DspEntityMove)(nil),         // 8: pb.DspEntityMove
+	(*DspMoveSnap)(nil),           // 9: pb.DspMoveSnap
+	(ESignInFastType_T)(0),        // 10: pb.ESignInFastType.T
+	(*LoginData)(nil),             // 11: pb.LoginData
+	(EKickType_T)(0),              // 12: pb.EKickType.T
+	(*AoiEntity)(nil),             // 13: pb.AoiEntity
+	(*AoiMove)(nil),               // 14: pb.AoiMove
+	(*Vector)(nil),                // 15: pb.Vector
 }
 var file_cmd_dsp_proto_depIdxs = []int32{
-	6, // 0: pb.DspLoginFast.ty:type_name -> pb.ESignInFastType.T
-	7, // 1: pb.DspLoginData.data:type_name -> pb.LoginData
-	8, // 2: pb.DspKickRole.ty:type_name -> pb.EKickType.T
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	10, // 0: pb.DspLoginFast.ty:type_name -> pb.ESignInFastType.T
+	11, // 1: pb.DspLoginData.data:type_name -> pb.LoginData
+	12, // 2: pb.DspKickRole.ty:type_name -> pb.EKickType.T
+	13, // 3: pb.DspEntityAppear.entities:type_name -> pb.AoiEntity
+	14, // 4: pb.DspEntityMove.moves:type_name -> pb.AoiMove
+	15, // 5: pb.DspMoveSnap.pos:type_name -> pb.Vector
+	6,  // [6:6] is the sub-list for method output_type
+	6,  // [6:6] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_cmd_dsp_proto_init() }
@@ -385,7 +611,7 @@ func file_cmd_dsp_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cmd_dsp_proto_rawDesc), len(file_cmd_dsp_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -511,11 +511,150 @@ func (*ReqTestEnter) Descriptor() ([]byte, []int) {
 	return file_cmd_req_proto_rawDescGZIP(), []int{7}
 }
 
+// 释放技能
+type ReqCastSkill struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cid           int64                  `protobuf:"zigzag64,1,opt,name=cid,proto3" json:"cid,omitempty"`                                 // 技能ID
+	SubCid        int64                  `protobuf:"zigzag64,2,opt,name=sub_cid,json=subCid,proto3" json:"sub_cid,omitempty"`             // 子技能ID
+	Pos           *Vector                `protobuf:"bytes,3,opt,name=pos,proto3" json:"pos,omitempty"`                                    // 目标点
+	Dir           *Vector                `protobuf:"bytes,4,opt,name=dir,proto3" json:"dir,omitempty"`                                    // 施法方向
+	LockTarget    int64                  `protobuf:"zigzag64,5,opt,name=lock_target,json=lockTarget,proto3" json:"lock_target,omitempty"` // 锁定目标ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReqCastSkill) Reset() {
+	*x = ReqCastSkill{}
+	mi := &file_cmd_req_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReqCastSkill) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReqCastSkill) ProtoMessage() {}
+
+func (x *ReqCastSkill) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_req_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReqCastSkill.ProtoReflect.Descriptor instead.
+func (*ReqCastSkill) Descriptor() ([]byte, []int) {
+	return file_cmd_req_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReqCastSkill) GetCid() int64 {
+	if x != nil {
+		return x.Cid
+	}
+	return 0
+}
+
+func (x *ReqCastSkill) GetSubCid() int64 {
+	if x != nil {
+		return x.SubCid
+	}
+	return 0
+}
+
+func (x *ReqCastSkill) GetPos() *Vector {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *ReqCastSkill) GetDir() *Vector {
+	if x != nil {
+		return x.Dir
+	}
+	return nil
+}
+
+func (x *ReqCastSkill) GetLockTarget() int64 {
+	if x != nil {
+		return x.LockTarget
+	}
+	return 0
+}
+
+// 移动
+type ReqMove struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           int64                  `protobuf:"zigzag64,1,opt,name=seq,proto3" json:"seq,omitempty"` // 客户端移动序号，递增；过期的请求被忽略
+	Pos           *Vector                `protobuf:"bytes,2,opt,name=pos,proto3" json:"pos,omitempty"`    // 客户端当前位置
+	Dir           int32                  `protobuf:"zigzag32,3,opt,name=dir,proto3" json:"dir,omitempty"` // 客户端当前朝向
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReqMove) Reset() {
+	*x = ReqMove{}
+	mi := &file_cmd_req_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReqMove) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReqMove) ProtoMessage() {}
+
+func (x *ReqMove) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_req_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReqMove.ProtoReflect.Descriptor instead.
+func (*ReqMove) Descriptor() ([]byte, []int) {
+	return file_cmd_req_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ReqMove) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *ReqMove) GetPos() *Vector {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *ReqMove) GetDir() int32 {
+	if x != nil {
+		return x.Dir
+	}
+	return 0
+}
+
 var File_cmd_req_proto protoreflect.FileDescriptor
 
 const file_cmd_req_proto_rawDesc = "" +
 	"\n" +
-	"\rcmd_req.proto\x12\x02pb\"\x86\x04\n" +
+	"\rcmd_req.proto\x12\x02pb\x1a\n" +
+	"data.proto\"\x86\x04\n" +
 	"\bReqLogin\x12\x12\n" +
 	"\x04fast\x18\x01 \x01(\bR\x04fast\x12\x14\n" +
 	"\x05magic\x18\x02 \x01(\x12R\x05magic\x12\x1c\n" +
@@ -552,7 +691,21 @@ const file_cmd_req_proto_rawDesc = "" +
 	"\n" +
 	"ReqPingXXX\"\x0f\n" +
 	"\rReqEnterScene\"\x0e\n" +
-	"\fReqTestEnterB\vZ\tserver/pbb\x06proto3"
+	"\fReqTestEnter\"\x96\x01\n" +
+	"\fReqCastSkill\x12\x10\n" +
+	"\x03cid\x18\x01 \x01(\x12R\x03cid\x12\x17\n" +
+	"\asub_cid\x18\x02 \x01(\x12R\x06subCid\x12\x1c\n" +
+	"\x03pos\x18\x03 \x01(\v2\n" +
+	".pb.VectorR\x03pos\x12\x1c\n" +
+	"\x03dir\x18\x04 \x01(\v2\n" +
+	".pb.VectorR\x03dir\x12\x1f\n" +
+	"\vlock_target\x18\x05 \x01(\x12R\n" +
+	"lockTarget\"K\n" +
+	"\aReqMove\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x12R\x03seq\x12\x1c\n" +
+	"\x03pos\x18\x02 \x01(\v2\n" +
+	".pb.VectorR\x03pos\x12\x10\n" +
+	"\x03dir\x18\x03 \x01(\x11R\x03dirB\vZ\tserver/pbb\x06proto3"
 
 var (
 	file_cmd_req_proto_rawDescOnce sync.Once
@@ -566,7 +719,7 @@ func file_cmd_req_proto_rawDescGZIP() []byte {
 	return file_cmd_req_proto_rawDescData
 }
 
-var file_cmd_req_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_cmd_req_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_cmd_req_proto_goTypes = []any{
 	(*ReqLogin)(nil),      // 0: pb.ReqLogin
 	(*ReqCreateRole)(nil), // 1: pb.ReqCreateRole
@@ -576,13 +729,19 @@ var file_cmd_req_proto_goTypes = []any{
 	(*ReqPingXXX)(nil),    // 5: pb.ReqPingXXX
 	(*ReqEnterScene)(nil), // 6: pb.ReqEnterScene
 	(*ReqTestEnter)(nil),  // 7: pb.ReqTestEnter
+	(*ReqCastSkill)(nil),  // 8: pb.ReqCastSkill
+	(*ReqMove)(nil),       // 9: pb.ReqMove
+	(*Vector)(nil),        // 10: pb.Vector
 }
 var file_cmd_req_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	10, // 0: pb.ReqCastSkill.pos:type_name -> pb.Vector
+	10, // 1: pb.ReqCastSkill.dir:type_name -> pb.Vector
+	10, // 2: pb.ReqMove.pos:type_name -> pb.Vector
+	3,  // [3:3] is the sub-list for method output_type
+	3,  // [3:3] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_cmd_req_proto_init() }
@@ -590,13 +749,14 @@ func file_cmd_req_proto_init() {
 	if File_cmd_req_proto != nil {
 		return
 	}
+	file_data_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cmd_req_proto_rawDesc), len(file_cmd_req_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
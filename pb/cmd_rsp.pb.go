@@ -424,6 +424,67 @@ func (*RspTestEnter) Descriptor() ([]byte, []int) {
 	return file_cmd_rsp_proto_rawDescGZIP(), []int{7}
 }
 
+// 释放技能
+type RspCastSkill struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cid           int64                  `protobuf:"zigzag64,1,opt,name=cid,proto3" json:"cid,omitempty"`                    // 技能ID
+	Err           EErrorCode_T           `protobuf:"varint,2,opt,name=err,proto3,enum=pb.EErrorCode_T" json:"err,omitempty"` // Ok 或 Failed
+	Result        int32                  `protobuf:"zigzag32,3,opt,name=result,proto3" json:"result,omitempty"`              // 施法结果（skill.CastResult）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RspCastSkill) Reset() {
+	*x = RspCastSkill{}
+	mi := &file_cmd_rsp_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RspCastSkill) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RspCastSkill) ProtoMessage() {}
+
+func (x *RspCastSkill) ProtoReflect() protoreflect.Message {
+	mi := &file_cmd_rsp_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RspCastSkill.ProtoReflect.Descriptor instead.
+func (*RspCastSkill) Descriptor() ([]byte, []int) {
+	return file_cmd_rsp_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RspCastSkill) GetCid() int64 {
+	if x != nil {
+		return x.Cid
+	}
+	return 0
+}
+
+func (x *RspCastSkill) GetErr() EErrorCode_T {
+	if x != nil {
+		return x.Err
+	}
+	return EErrorCode_Ok
+}
+
+func (x *RspCastSkill) GetResult() int32 {
+	if x != nil {
+		return x.Result
+	}
+	return 0
+}
+
 var File_cmd_rsp_proto protoreflect.FileDescriptor
 
 const file_cmd_rsp_proto_rawDesc = "" +
@@ -455,7 +516,11 @@ const file_cmd_rsp_proto_rawDesc = "" +
 	"\n" +
 	"RspPingXXX\"\x0f\n" +
 	"\rRspEnterScene\"\x0e\n" +
-	"\fRspTestEnterB\vZ\tserver/pbb\x06proto3"
+	"\fRspTestEnter\"\\\n" +
+	"\fRspCastSkill\x12\x10\n" +
+	"\x03cid\x18\x01 \x01(\x12R\x03cid\x12\"\n" +
+	"\x03err\x18\x02 \x01(\x0e2\x10.pb.EErrorCode.TR\x03err\x12\x16\n" +
+	"\x06result\x18\x03 \x01(\x11R\x06resultB\vZ\tserver/pbb\x06proto3"
 
 var (
 	file_cmd_rsp_proto_rawDescOnce sync.Once
@@ -469,7 +534,7 @@ func file_cmd_rsp_proto_rawDescGZIP() []byte {
 	return file_cmd_rsp_proto_rawDescData
 }
 
-var file_cmd_rsp_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_cmd_rsp_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_cmd_rsp_proto_goTypes = []any{
 	(*RspLogin)(nil),        // 0: pb.RspLogin
 	(*RspCreateRole)(nil),   // 1: pb.RspCreateRole
@@ -479,23 +544,25 @@ var file_cmd_rsp_proto_goTypes = []any{
 	(*RspPingXXX)(nil),      // 5: pb.RspPingXXX
 	(*RspEnterScene)(nil),   // 6: pb.RspEnterScene
 	(*RspTestEnter)(nil),    // 7: pb.RspTestEnter
-	(EErrorCode_T)(0),       // 8: pb.EErrorCode.T
-	(*RoleSummaryData)(nil), // 9: pb.RoleSummaryData
-	(*LoginData)(nil),       // 10: pb.LoginData
+	(*RspCastSkill)(nil),    // 8: pb.RspCastSkill
+	(EErrorCode_T)(0),       // 9: pb.EErrorCode.T
+	(*RoleSummaryData)(nil), // 10: pb.RoleSummaryData
+	(*LoginData)(nil),       // 11: pb.LoginData
 }
 var file_cmd_rsp_proto_depIdxs = []int32{
-	8,  // 0: pb.RspLogin.err:type_name -> pb.EErrorCode.T
-	9,  // 1: pb.RspLogin.roles:type_name -> pb.RoleSummaryData
-	8,  // 2: pb.RspCreateRole.err:type_name -> pb.EErrorCode.T
-	9,  // 3: pb.RspCreateRole.role:type_name -> pb.RoleSummaryData
-	8,  // 4: pb.RspDeleteRole.err:type_name -> pb.EErrorCode.T
-	8,  // 5: pb.RspLoginRole.err:type_name -> pb.EErrorCode.T
-	10, // 6: pb.RspLoginRole.data:type_name -> pb.LoginData
-	7,  // [7:7] is the sub-list for method output_type
-	7,  // [7:7] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	9,  // 0: pb.RspLogin.err:type_name -> pb.EErrorCode.T
+	10, // 1: pb.RspLogin.roles:type_name -> pb.RoleSummaryData
+	9,  // 2: pb.RspCreateRole.err:type_name -> pb.EErrorCode.T
+	10, // 3: pb.RspCreateRole.role:type_name -> pb.RoleSummaryData
+	9,  // 4: pb.RspDeleteRole.err:type_name -> pb.EErrorCode.T
+	9,  // 5: pb.RspLoginRole.err:type_name -> pb.EErrorCode.T
+	11, // 6: pb.RspLoginRole.data:type_name -> pb.LoginData
+	9,  // 7: pb.RspCastSkill.err:type_name -> pb.EErrorCode.T
+	8,  // [8:8] is the sub-list for method output_type
+	8,  // [8:8] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_cmd_rsp_proto_init() }
@@ -511,7 +578,7 @@ func file_cmd_rsp_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cmd_rsp_proto_rawDesc), len(file_cmd_rsp_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -615,6 +615,136 @@ func (x *Vector) GetZ() float64 {
 	return 0
 }
 
+// 进入视野的实体快照
+type AoiEntity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"zigzag64,1,opt,name=id,proto3" json:"id,omitempty"`                         // 实体ID
+	Type          EEntityType_T          `protobuf:"varint,2,opt,name=type,proto3,enum=pb.EEntityType_T" json:"type,omitempty"` // 实体类型
+	Pos           *Vector                `protobuf:"bytes,3,opt,name=pos,proto3" json:"pos,omitempty"`                          // 位置
+	Dir           int32                  `protobuf:"zigzag32,4,opt,name=dir,proto3" json:"dir,omitempty"`                       // 朝向
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AoiEntity) Reset() {
+	*x = AoiEntity{}
+	mi := &file_data_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AoiEntity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AoiEntity) ProtoMessage() {}
+
+func (x *AoiEntity) ProtoReflect() protoreflect.Message {
+	mi := &file_data_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AoiEntity.ProtoReflect.Descriptor instead.
+func (*AoiEntity) Descriptor() ([]byte, []int) {
+	return file_data_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AoiEntity) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AoiEntity) GetType() EEntityType_T {
+	if x != nil {
+		return x.Type
+	}
+	return EEntityType_None
+}
+
+func (x *AoiEntity) GetPos() *Vector {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *AoiEntity) GetDir() int32 {
+	if x != nil {
+		return x.Dir
+	}
+	return 0
+}
+
+// 视野内实体的位置/朝向变化
+type AoiMove struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"zigzag64,1,opt,name=id,proto3" json:"id,omitempty"`   // 实体ID
+	Pos           *Vector                `protobuf:"bytes,2,opt,name=pos,proto3" json:"pos,omitempty"`    // 位置
+	Dir           int32                  `protobuf:"zigzag32,3,opt,name=dir,proto3" json:"dir,omitempty"` // 朝向
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AoiMove) Reset() {
+	*x = AoiMove{}
+	mi := &file_data_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AoiMove) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AoiMove) ProtoMessage() {}
+
+func (x *AoiMove) ProtoReflect() protoreflect.Message {
+	mi := &file_data_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AoiMove.ProtoReflect.Descriptor instead.
+func (*AoiMove) Descriptor() ([]byte, []int) {
+	return file_data_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AoiMove) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AoiMove) GetPos() *Vector {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *AoiMove) GetDir() int32 {
+	if x != nil {
+		return x.Dir
+	}
+	return 0
+}
+
 var File_data_proto protoreflect.FileDescriptor
 
 const file_data_proto_rawDesc = "" +
@@ -669,7 +799,18 @@ const file_data_proto_rawDesc = "" +
 	"\x06Vector\x12\f\n" +
 	"\x01x\x18\x01 \x01(\x01R\x01x\x12\f\n" +
 	"\x01y\x18\x02 \x01(\x01R\x01y\x12\f\n" +
-	"\x01z\x18\x03 \x01(\x01R\x01zB\vZ\tserver/pbb\x06proto3"
+	"\x01z\x18\x03 \x01(\x01R\x01z\"r\n" +
+	"\tAoiEntity\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x12R\x02id\x12%\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x11.pb.EEntityType.TR\x04type\x12\x1c\n" +
+	"\x03pos\x18\x03 \x01(\v2\n" +
+	".pb.VectorR\x03pos\x12\x10\n" +
+	"\x03dir\x18\x04 \x01(\x11R\x03dir\"I\n" +
+	"\aAoiMove\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x12R\x02id\x12\x1c\n" +
+	"\x03pos\x18\x02 \x01(\v2\n" +
+	".pb.VectorR\x03pos\x12\x10\n" +
+	"\x03dir\x18\x03 \x01(\x11R\x03dirB\vZ\tserver/pbb\x06proto3"
 
 var (
 	file_data_proto_rawDescOnce sync.Once
@@ -683,7 +824,7 @@ func file_data_proto_rawDescGZIP() []byte {
 	return file_data_proto_rawDescData
 }
 
-var file_data_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_data_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_data_proto_goTypes = []any{
 	(*RoleSummaryData)(nil), // 0: pb.RoleSummaryData
 	(*LoginData)(nil),       // 1: pb.LoginData
@@ -693,19 +834,25 @@ var file_data_proto_goTypes = []any{
 	(*Time)(nil),            // 5: pb.Time
 	(*Attr)(nil),            // 6: pb.Attr
 	(*Vector)(nil),          // 7: pb.Vector
-	(EObjType_T)(0),         // 8: pb.EObjType.T
-	(EAttrType_T)(0),        // 9: pb.EAttrType.T
+	(*AoiEntity)(nil),       // 8: pb.AoiEntity
+	(*AoiMove)(nil),         // 9: pb.AoiMove
+	(EObjType_T)(0),         // 10: pb.EObjType.T
+	(EAttrType_T)(0),        // 11: pb.EAttrType.T
+	(EEntityType_T)(0),      // 12: pb.EEntityType.T
 }
 var file_data_proto_depIdxs = []int32{
-	5, // 0: pb.LoginData.time:type_name -> pb.Time
-	8, // 1: pb.Object.objType:type_name -> pb.EObjType.T
-	2, // 2: pb.Award.object:type_name -> pb.Object
-	9, // 3: pb.Attr.ty:type_name -> pb.EAttrType.T
-	4, // [4:4] is the sub-list for method output_type
-	4, // [4:4] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	5,  // 0: pb.LoginData.time:type_name -> pb.Time
+	10, // 1: pb.Object.objType:type_name -> pb.EObjType.T
+	2,  // 2: pb.Award.object:type_name -> pb.Object
+	11, // 3: pb.Attr.ty:type_name -> pb.EAttrType.T
+	12, // 4: pb.AoiEntity.type:type_name -> pb.EEntityType.T
+	7,  // 5: pb.AoiEntity.pos:type_name -> pb.Vector
+	7,  // 6: pb.AoiMove.pos:type_name -> pb.Vector
+	7,  // [7:7] is the sub-list for method output_type
+	7,  // [7:7] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_data_proto_init() }
@@ -720,7 +867,7 @@ func file_data_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_data_proto_rawDesc), len(file_data_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,237 @@
+package aoi
+
+import (
+	"cmp"
+	"slices"
+
+	"server/data/enum"
+	"server/lib/container"
+	"server/lib/uid"
+	"server/pb"
+	"server/service/world/zone/izone"
+)
+
+// Config 为视野参数。
+// 进入视野使用 EnterRange、离开视野使用 LeaveRange（LeaveRange > EnterRange），
+// 两者之间的缓冲带避免实体在视野边缘来回移动时反复出现/消失。
+type Config struct {
+	EnterRange float64 // 进入视野距离
+	LeaveRange float64 // 离开视野距离
+
+	MaxVisible       int // 单个观察者最多可见实体数（0 表示不限制），拥挤时只保留最近的实体
+	MaxAppearPerTick int // 单个观察者每帧最多新增的可见实体数（0 表示不限制），其余顺延到后续帧
+}
+
+// DefaultConfig 为默认视野参数。
+var DefaultConfig = Config{
+	EnterRange:       40,
+	LeaveRange:       48,
+	MaxVisible:       100,
+	MaxAppearPerTick: 20,
+}
+
+// Sync 为一个观察者在一帧内的视野变化，没有变化的消息为 nil。
+type Sync struct {
+	Appear    *pb.DspEntityAppear
+	Disappear *pb.DspEntityDisappear
+	Move      *pb.DspEntityMove
+}
+
+// seen 为观察者最近一次同步的实体状态。
+type seen struct {
+	pos *pb.Vector
+	dir int32
+}
+
+// watcher 为观察者（角色）及其可见实体集合。
+type watcher struct {
+	e       izone.IEntity
+	visible *container.LMap[uid.Uid, *seen]
+}
+
+// Manager 为区域的视野管理器：跟踪每个角色可见的实体，每帧合并出现/消失/移动变化后通过 OnSync 下发。
+// 新进入视野的候选实体通过区域的空间索引按 EnterRange 查询，不遍历全区域。
+// 注意：非线程安全，只在区域逻辑线程内使用。
+type Manager struct {
+	cfg  Config
+	zone izone.IZone
+
+	watchers *container.LMap[uid.Uid, *watcher]
+
+	// OnSync 观察者视野有变化时的回调（每帧每个观察者最多一次）
+	OnSync func(watcherId uid.Uid, sync *Sync)
+}
+
+// NewManager 创建视野管理器，LeaveRange 小于 EnterRange 时按 EnterRange 处理。
+func NewManager(zone izone.IZone, cfg Config) *Manager {
+	cfg.LeaveRange = max(cfg.LeaveRange, cfg.EnterRange)
+	return &Manager{
+		cfg:      cfg,
+		zone:     zone,
+		watchers: container.NewLMap[uid.Uid, *watcher](),
+	}
+}
+
+// IsWatcher 判断实体是否需要视野同步（只有角色有客户端）。
+func IsWatcher(e izone.IEntity) bool {
+	return e != nil && e.GetType() == enum.EntityType_Role
+}
+
+// AddWatcher 添加观察者，可见实体在下一次 Update 时计算。
+func (m *Manager) AddWatcher(e izone.IEntity) {
+	if e == nil || m.watchers.Has(e.GetId()) {
+		return
+	}
+	m.watchers.Set(e.GetId(), &watcher{
+		e:       e,
+		visible: container.NewLMap[uid.Uid, *seen](),
+	})
+}
+
+// RemoveWatcher 移除观察者（如角色离开区域），不再下发消息。
+func (m *Manager) RemoveWatcher(id uid.Uid) {
+	m.watchers.Delete(id)
+}
+
+// GetVisible 获取观察者当前可见的实体ID。
+func (m *Manager) GetVisible(watcherId uid.Uid) []uid.Uid {
+	w, ok := m.watchers.Get(watcherId)
+	if !ok {
+		return nil
+	}
+	return w.visible.Keys()
+}
+
+// CanSee 判断观察者当前是否能看到实体。
+func (m *Manager) CanSee(watcherId, targetId uid.Uid) bool {
+	w, ok := m.watchers.Get(watcherId)
+	return ok && w.visible.Has(targetId)
+}
+
+// Update 计算所有观察者本帧的视野变化并回调 OnSync，应在所有实体更新之后调用。
+func (m *Manager) Update() {
+	m.watchers.ForEach(func(w *watcher) {
+		if sync := m.update(w); sync != nil && m.OnSync != nil {
+			m.OnSync(w.e.GetId(), sync)
+		}
+	})
+}
+
+// update 计算单个观察者的视野变化：
+//  1. 已离开区域或超出 LeaveRange 的实体消失
+//  2. 仍可见且位置/朝向变化的实体移动
+//  3. EnterRange 内新进入的实体按距离由近到远出现，受 MaxVisible 与 MaxAppearPerTick 限制；
+//     可见数已满时，比最远的可见实体更近的候选会替换掉最远的实体（被替换的实体消失）
+func (m *Manager) update(w *watcher) *Sync {
+	center := w.e.GetPos()
+	var disappear []int64
+	var moves []*pb.AoiMove
+
+	for _, entry := range w.visible.Entries() {
+		id, last := entry.Key, entry.Value
+		e, ok := m.zone.GetEntity(id)
+		if !ok || center == nil || e.GetPos() == nil || e.GetPos().Distance2D(center) > m.cfg.LeaveRange {
+			w.visible.Delete(id)
+			disappear = append(disappear, int64(id))
+			continue
+		}
+		pos, dir := e.GetPos(), e.GetDir()
+		if !pos.ApproximatelyEqual2D(last.pos) || dir != last.dir {
+			last.pos, last.dir = pos.Copy(), dir
+			moves = append(moves, &pb.AoiMove{Id: int64(id), Pos: pos.Copy(), Dir: dir})
+		}
+	}
+
+	var appear []*pb.AoiEntity
+	if center != nil {
+		var evicted []int64
+		appear, evicted = m.collectAppear(w, center)
+		disappear = append(disappear, evicted...)
+		// 被替换掉的实体本帧只发消失，不再发移动
+		if len(evicted) > 0 {
+			moves = slices.DeleteFunc(moves, func(mv *pb.AoiMove) bool {
+				return slices.Contains(evicted, mv.Id)
+			})
+		}
+	}
+
+	if len(appear) == 0 && len(disappear) == 0 && len(moves) == 0 {
+		return nil
+	}
+	sync := &Sync{}
+	if len(appear) > 0 {
+		sync.Appear = &pb.DspEntityAppear{Entities: appear}
+	}
+	if len(disappear) > 0 {
+		sync.Disappear = &pb.DspEntityDisappear{Ids: disappear}
+	}
+	if len(moves) > 0 {
+		sync.Move = &pb.DspEntityMove{Moves: moves}
+	}
+	return sync
+}
+
+// collectAppear 选出本帧新进入视野的实体并加入可见集合，返回出现的实体与为腾出位置被替换掉的实体ID。
+func (m *Manager) collectAppear(w *watcher, center *pb.Vector) ([]*pb.AoiEntity, []int64) {
+	var candidates []izone.IEntity
+	m.zone.QueryRadius(center, m.cfg.EnterRange, func(e izone.IEntity) {
+		if e.GetId() != w.e.GetId() && !w.visible.Has(e.GetId()) {
+			candidates = append(candidates, e)
+		}
+	})
+	limit, room := m.cfg.MaxAppearPerTick, m.cfg.MaxVisible-w.visible.Len()
+	full := m.cfg.MaxVisible > 0 && len(candidates) > room
+	if full || (limit > 0 && len(candidates) > limit) {
+		slices.SortStableFunc(candidates, func(a, b izone.IEntity) int {
+			return cmp.Compare(a.GetPos().DistanceSq2D(center), b.GetPos().DistanceSq2D(center))
+		})
+	}
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	var evicted []int64
+	if full && len(candidates) > room {
+		// 候选由近到远、可见实体由远到近依次比较，候选更近时替换掉最远的可见实体
+		farthest := m.sortedVisible(w, center)
+		n := max(room, 0)
+		for n < len(candidates) && len(farthest) > 0 && candidates[n].GetPos().DistanceSq2D(center) < farthest[0].distSq {
+			w.visible.Delete(farthest[0].id)
+			evicted = append(evicted, int64(farthest[0].id))
+			farthest = farthest[1:]
+			n++
+		}
+		candidates = candidates[:n]
+	}
+
+	appear := make([]*pb.AoiEntity, 0, len(candidates))
+	for _, e := range candidates {
+		pos := e.GetPos().Copy()
+		w.visible.Set(e.GetId(), &seen{pos: pos, dir: e.GetDir()})
+		appear = append(appear, &pb.AoiEntity{
+			Id:   int64(e.GetId()),
+			Type: pb.EEntityType_T(e.GetType()),
+			Pos:  pos.Copy(),
+			Dir:  e.GetDir(),
+		})
+	}
+	return appear, evicted
+}
+
+// visibleDist 为可见实体到观察者的平面距离平方。
+type visibleDist struct {
+	id     uid.Uid
+	distSq float64
+}
+
+// sortedVisible 获取观察者当前可见的实体，按距离由远到近排序（位置取最近一次同步的位置）。
+func (m *Manager) sortedVisible(w *watcher, center *pb.Vector) []visibleDist {
+	ret := make([]visibleDist, 0, w.visible.Len())
+	for _, entry := range w.visible.Entries() {
+		ret = append(ret, visibleDist{id: entry.Key, distSq: entry.Value.pos.DistanceSq2D(center)})
+	}
+	slices.SortStableFunc(ret, func(a, b visibleDist) int {
+		return cmp.Compare(b.distSq, a.distSq)
+	})
+	return ret
+}
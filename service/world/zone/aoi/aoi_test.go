@@ -0,0 +1,260 @@
+package aoi
+
+import (
+	"slices"
+	"testing"
+
+	"server/data"
+	"server/data/conf"
+	"server/data/enum"
+	"server/lib/container"
+	"server/lib/uid"
+	"server/pb"
+	"server/service/world/zone/izone"
+	"server/service/world/zone/spatial"
+)
+
+// testZone 为测试用的最小区域，空间查询使用真实的网格索引。
+type testZone struct {
+	entities *container.LMap[uid.Uid, izone.IEntity]
+	grid     *spatial.Grid
+}
+
+func newTestZone() *testZone {
+	return &testZone{
+		entities: container.NewLMap[uid.Uid, izone.IEntity](),
+		grid:     spatial.NewGrid(spatial.DefaultCellSize),
+	}
+}
+
+func (z *testZone) Init() {}
+func (z *testZone) AddEntity(e izone.IEntity) {
+	z.entities.Set(e.GetId(), e)
+	z.grid.Add(e)
+}
+func (z *testZone) RemoveEntity(id uid.Uid) {
+	z.entities.Delete(id)
+	z.grid.Remove(id)
+}
+func (z *testZone) GetEntity(id uid.Uid) (izone.IEntity, bool) { return z.entities.Get(id) }
+func (z *testZone) ForEach(fn func(e izone.IEntity))           { z.entities.ForEach(fn) }
+func (z *testZone) GetTables() *conf.Tables                    { return nil }
+func (z *testZone) NowMs() int64                               { return 0 }
+func (z *testZone) UpdateEntityPos(e izone.IEntity)            { z.grid.Update(e) }
+func (z *testZone) QueryRadius(center *pb.Vector, radius float64, fn func(e izone.IEntity)) {
+	z.grid.QueryRadius(center, radius, fn)
+}
+func (z *testZone) QueryBox(lo, hi *pb.Vector, fn func(e izone.IEntity)) {
+	z.grid.QueryBox(lo, hi, fn)
+}
+func (z *testZone) QueryCone(center, facing *pb.Vector, radius, angle float64, fn func(e izone.IEntity)) {
+	z.grid.QueryCone(center, facing, radius, angle, fn)
+}
+
+// testEntity 为测试用的最小 IEntity 实现。
+type testEntity struct {
+	id   uid.Uid
+	ety  enum.EntityType
+	pos  *pb.Vector
+	dir  int32
+	zone *testZone
+}
+
+func (e *testEntity) Init(izone.IZone, data.EntityInitData)    {}
+func (e *testEntity) Update(int64)                             {}
+func (e *testEntity) GetZone() izone.IZone                     { return e.zone }
+func (e *testEntity) GetType() enum.EntityType                 { return e.ety }
+func (e *testEntity) GetId() uid.Uid                           { return e.id }
+func (e *testEntity) GetPos() *pb.Vector                       { return e.pos }
+func (e *testEntity) GetDir() int32                            { return e.dir }
+func (e *testEntity) SetDir(dir int32)                         { e.dir = dir }
+func (e *testEntity) GetModule(izone.ModuleType) izone.IModule { return nil }
+func (e *testEntity) SetPos(pos *pb.Vector) {
+	e.pos = pos
+	e.zone.UpdateEntityPos(e)
+}
+
+func addEntity(z *testZone, id int64, ety enum.EntityType, x float64) *testEntity {
+	e := &testEntity{id: uid.Uid(id), ety: ety, pos: pb.NewVector(x, 0, 0), zone: z}
+	z.AddEntity(e)
+	return e
+}
+
+// recorder 记录每个观察者最近一次收到的视野变化。
+type recorder map[uid.Uid]*Sync
+
+func newManager(z *testZone, cfg Config) (*Manager, recorder) {
+	m := NewManager(z, cfg)
+	rec := recorder{}
+	m.OnSync = func(watcherId uid.Uid, sync *Sync) { rec[watcherId] = sync }
+	return m, rec
+}
+
+func appearIds(s *Sync) []int64 {
+	if s == nil || s.Appear == nil {
+		return nil
+	}
+	var ids []int64
+	for _, e := range s.Appear.Entities {
+		ids = append(ids, e.Id)
+	}
+	return ids
+}
+
+func TestManager_Hysteresis(t *testing.T) {
+	z := newTestZone()
+	role := addEntity(z, 1, enum.EntityType_Role, 0)
+	npc := addEntity(z, 2, enum.EntityType_Npc, 30)
+	m, rec := newManager(z, Config{EnterRange: 40, LeaveRange: 48})
+	m.AddWatcher(role)
+
+	m.Update()
+	if got := appearIds(rec[1]); !slices.Equal(got, []int64{2}) {
+		t.Fatalf("Expected npc to appear, got %v", got)
+	}
+	if rec[1].Appear.Entities[0].Type != pb.EEntityType_Npc {
+		t.Errorf("Expected npc type, got %v", rec[1].Appear.Entities[0].Type)
+	}
+
+	// 移到进入与离开距离之间：仍可见，只同步移动
+	clear(rec)
+	npc.SetPos(pb.NewVector(45, 0, 0))
+	m.Update()
+	if s := rec[1]; s == nil || s.Disappear != nil || s.Move == nil || s.Move.Moves[0].Pos.X != 45 {
+		t.Fatalf("Expected only a move inside the hysteresis band, got %+v", s)
+	}
+
+	// 无变化时不回调
+	clear(rec)
+	m.Update()
+	if _, ok := rec[1]; ok {
+		t.Errorf("Expected no sync without changes")
+	}
+
+	// 超出离开距离后消失，回到缓冲带内也不会重新出现
+	npc.SetPos(pb.NewVector(50, 0, 0))
+	m.Update()
+	if s := rec[1]; s == nil || s.Disappear == nil || !slices.Equal(s.Disappear.Ids, []int64{2}) {
+		t.Fatalf("Expected npc to disappear beyond leave range, got %+v", s)
+	}
+	clear(rec)
+	npc.SetPos(pb.NewVector(45, 0, 0))
+	m.Update()
+	if m.CanSee(1, 2) || rec[1] != nil {
+		t.Errorf("Expected npc to stay hidden until within enter range")
+	}
+}
+
+func TestManager_Batching(t *testing.T) {
+	z := newTestZone()
+	role := addEntity(z, 1, enum.EntityType_Role, 0)
+	for i := int64(2); i <= 7; i++ {
+		addEntity(z, i, enum.EntityType_Npc, float64(20-i)) // id 越大越近
+	}
+	m, rec := newManager(z, Config{EnterRange: 40, LeaveRange: 48, MaxVisible: 5, MaxAppearPerTick: 2})
+	m.AddWatcher(role)
+
+	// 每帧最多出现 2 个，由近到远
+	m.Update()
+	if got := appearIds(rec[1]); !slices.Equal(got, []int64{7, 6}) {
+		t.Errorf("Expected nearest two first, got %v", got)
+	}
+	m.Update()
+	m.Update()
+	if got := appearIds(rec[1]); !slices.Equal(got, []int64{3}) {
+		t.Errorf("Expected appear capped by max visible, got %v", got)
+	}
+	if n := len(m.GetVisible(1)); n != 5 {
+		t.Errorf("Expected 5 visible, got %d", n)
+	}
+
+	// 可见实体离开区域后腾出位置
+	clear(rec)
+	z.RemoveEntity(7)
+	m.Update()
+	s := rec[1]
+	if s == nil || s.Disappear == nil || !slices.Equal(s.Disappear.Ids, []int64{7}) {
+		t.Fatalf("Expected removed entity to disappear, got %+v", s)
+	}
+	if got := appearIds(s); !slices.Equal(got, []int64{2}) {
+		t.Errorf("Expected freed slot to be filled, got %v", got)
+	}
+}
+
+func TestManager_EvictFarthest(t *testing.T) {
+	z := newTestZone()
+	role := addEntity(z, 1, enum.EntityType_Role, 0)
+	addEntity(z, 2, enum.EntityType_Npc, 30)
+	far := addEntity(z, 3, enum.EntityType_Npc, -35)
+	monster := addEntity(z, 4, enum.EntityType_Npc, 80)
+	m, rec := newManager(z, Config{EnterRange: 40, LeaveRange: 48, MaxVisible: 2})
+	m.AddWatcher(role)
+	m.Update()
+
+	// 视野已满时走近怪物：怪物替换掉最远的可见实体
+	clear(rec)
+	role.SetPos(pb.NewVector(5, 0, 0))
+	monster.SetPos(pb.NewVector(8, 0, 0))
+	far.SetPos(pb.NewVector(-34, 0, 0))
+	m.Update()
+	s := rec[1]
+	if got := appearIds(s); !slices.Equal(got, []int64{4}) {
+		t.Errorf("Expected nearer monster to appear, got %v", got)
+	}
+	if s == nil || s.Disappear == nil || !slices.Equal(s.Disappear.Ids, []int64{3}) {
+		t.Errorf("Expected farthest entity evicted, got %+v", s)
+	}
+	// 同一帧被替换的实体即使移动过也只发消失
+	if s != nil && s.Move != nil {
+		t.Errorf("Expected no move for evicted entity, got %v", s.Move.Moves)
+	}
+	if !m.CanSee(1, 4) || !m.CanSee(1, 2) || m.CanSee(1, 3) || len(m.GetVisible(1)) != 2 {
+		t.Errorf("Expected [2 4] visible, got %v", m.GetVisible(1))
+	}
+
+	// 候选比所有可见实体都远时不替换
+	clear(rec)
+	addEntity(z, 5, enum.EntityType_Npc, 40)
+	m.Update()
+	if s := rec[1]; s != nil {
+		t.Errorf("Expected no change for farther candidate, got %+v", s)
+	}
+}
+
+func TestManager_Watchers(t *testing.T) {
+	z := newTestZone()
+	a := addEntity(z, 1, enum.EntityType_Role, 0)
+	b := addEntity(z, 2, enum.EntityType_Role, 10)
+	if !IsWatcher(a) || IsWatcher(addEntity(z, 3, enum.EntityType_Npc, 5)) {
+		t.Fatal("Expected only roles to be watchers")
+	}
+	m, rec := newManager(z, DefaultConfig)
+	m.AddWatcher(a)
+	m.AddWatcher(b)
+
+	// 观察者互相可见但看不到自己
+	m.Update()
+	if got := appearIds(rec[1]); !slices.Equal(got, []int64{2, 3}) {
+		t.Errorf("Expected watcher a to see [2 3], got %v", got)
+	}
+	if m.CanSee(1, 1) || !m.CanSee(2, 1) {
+		t.Errorf("Expected watchers to see each other but not themselves")
+	}
+
+	// 朝向变化也同步
+	clear(rec)
+	b.SetDir(90)
+	m.Update()
+	if s := rec[1]; s == nil || s.Move == nil || s.Move.Moves[0].Dir != 90 {
+		t.Errorf("Expected dir change to sync, got %+v", s)
+	}
+
+	// 移除的观察者不再回调
+	clear(rec)
+	m.RemoveWatcher(2)
+	a.SetPos(pb.NewVector(1, 0, 0))
+	m.Update()
+	if _, ok := rec[2]; ok || m.GetVisible(2) != nil {
+		t.Errorf("Expected removed watcher to receive nothing")
+	}
+}
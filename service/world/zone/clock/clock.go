@@ -0,0 +1,51 @@
+package clock
+
+// IClock 为区域逻辑时钟接口，*Clock 与 *Fake 均实现该接口，测试时可向区域注入 *Fake。
+type IClock interface {
+	NowMs() int64
+	Advance(deltaMs int64)
+}
+
+var (
+	_ IClock = (*Clock)(nil)
+	_ IClock = (*Fake)(nil)
+)
+
+// Clock 为区域逻辑时钟（毫秒）：只由帧循环按固定步长推进，与墙钟无关。
+// 区域内的技能、效果、Buff、冷却等逻辑统一读取同一个时钟，避免各模块各自累加导致漂移。
+// 注意：非线程安全，只在区域逻辑线程内使用。
+type Clock struct {
+	nowMs int64
+}
+
+// New 创建从 startMs 开始的逻辑时钟。
+func New(startMs int64) *Clock {
+	return &Clock{nowMs: startMs}
+}
+
+// NowMs 获取当前逻辑时间。
+func (c *Clock) NowMs() int64 {
+	return c.nowMs
+}
+
+// Advance 推进逻辑时间，deltaMs <= 0 时不变。
+func (c *Clock) Advance(deltaMs int64) {
+	if deltaMs > 0 {
+		c.nowMs += deltaMs
+	}
+}
+
+// Fake 为测试用的手动时钟，可直接设置时间。
+type Fake struct {
+	Clock
+}
+
+// NewFake 创建从 startMs 开始的手动时钟。
+func NewFake(startMs int64) *Fake {
+	return &Fake{Clock: Clock{nowMs: startMs}}
+}
+
+// Set 直接设置当前时间（允许回拨，用于构造边界场景）。
+func (f *Fake) Set(nowMs int64) {
+	f.nowMs = nowMs
+}
@@ -0,0 +1,22 @@
+package clock
+
+import "testing"
+
+func TestClock_Advance(t *testing.T) {
+	c := New(1000)
+	c.Advance(50)
+	c.Advance(-10)
+	c.Advance(0)
+	if c.NowMs() != 1050 {
+		t.Errorf("Expected 1050, got %d", c.NowMs())
+	}
+}
+
+func TestFake_Set(t *testing.T) {
+	f := NewFake(0)
+	f.Advance(100)
+	f.Set(42)
+	if f.NowMs() != 42 {
+		t.Errorf("Expected 42, got %d", f.NowMs())
+	}
+}
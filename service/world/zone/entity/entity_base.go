@@ -5,17 +5,21 @@ import (
 	"server/data/enum"
 	"server/lib/uid"
 	"server/pb"
+	"server/service/world/zone/entity/mod/buff"
 	"server/service/world/zone/entity/mod/combat"
+	"server/service/world/zone/entity/mod/move"
 	"server/service/world/zone/izone"
 )
 
 var _ izone.IEntity = (*EntityBase)(nil)
 
-type ManagerType = int
+type ManagerType = izone.ModuleType
 
 const (
-	CombatManager ManagerType = iota
-	Max
+	CombatManager ManagerType = izone.ModuleType_Combat
+	MoveManager   ManagerType = izone.ModuleType_Move
+	BuffManager   ManagerType = izone.ModuleType_Buff
+	Max           ManagerType = izone.ModuleType_Max
 )
 
 type EntityBase struct {
@@ -28,6 +32,11 @@ type EntityBase struct {
 	managers [Max]izone.IModule
 }
 
+// NewEntity 创建指定类型的实体，需调用 Init 加入区域。
+func NewEntity(ety enum.EntityType) *EntityBase {
+	return &EntityBase{ety: ety}
+}
+
 func (e *EntityBase) Init(zone izone.IZone, initData data.EntityInitData) {
 	e.zone = zone
 	e.id = uid.Gen()
@@ -36,7 +45,8 @@ func (e *EntityBase) Init(zone izone.IZone, initData data.EntityInitData) {
 	}
 	if e.ety == enum.EntityType_Role || e.ety == enum.EntityType_Npc {
 		e.managers[CombatManager] = &combat.CombatManager{}
-
+		e.managers[MoveManager] = &move.MoveManager{}
+		e.managers[BuffManager] = &buff.BuffManager{}
 	}
 
 	for _, m := range e.managers {
@@ -56,10 +66,21 @@ func (e *EntityBase) Update(duration int64) {
 	}
 }
 
+func (e *EntityBase) GetModule(t izone.ModuleType) izone.IModule {
+	if t < 0 || t >= Max {
+		return nil
+	}
+	return e.managers[t]
+}
+
 func (e *EntityBase) GetId() uid.Uid {
 	return e.id
 }
 
+func (e *EntityBase) GetType() enum.EntityType {
+	return e.ety
+}
+
 func (e *EntityBase) GetZone() izone.IZone {
 	return e.zone
 }
@@ -70,6 +91,9 @@ func (e *EntityBase) GetPos() *pb.Vector {
 
 func (e *EntityBase) SetPos(pos *pb.Vector) {
 	e.pos = pos
+	if e.zone != nil {
+		e.zone.UpdateEntityPos(e)
+	}
 }
 
 func (e *EntityBase) GetDir() int32 {
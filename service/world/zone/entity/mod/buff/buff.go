@@ -0,0 +1,138 @@
+package buff
+
+import (
+	"fmt"
+
+	"server/data/conf"
+	config "server/data/xls"
+	"server/lib/uid"
+	"server/pb"
+	"server/service/world/zone/entity/mod/combat/skill"
+	"server/service/world/zone/izone"
+)
+
+// RemoveReason 为 Buff 被移除的原因。
+type RemoveReason int32
+
+const (
+	RemoveReason_Invalid RemoveReason = 0
+	RemoveReason_Expire  RemoveReason = 1 // 持续时间结束
+	RemoveReason_Remove  RemoveReason = 2 // 主动移除（驱散、脚本等）
+	RemoveReason_Death   RemoveReason = 3 // 宿主死亡
+	RemoveReason_Replace RemoveReason = 4 // 被同组 Buff 顶替
+	RemoveReason_Break   RemoveReason = 5 // 护盾吸收量耗尽
+)
+
+var removeReasonNames = map[RemoveReason]string{
+	RemoveReason_Invalid: "Invalid",
+	RemoveReason_Expire:  "Expire",
+	RemoveReason_Remove:  "Remove",
+	RemoveReason_Death:   "Death",
+	RemoveReason_Replace: "Replace",
+	RemoveReason_Break:   "Break",
+}
+
+func (r RemoveReason) String() string {
+	if name, ok := removeReasonNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("RemoveReason(%d)", int32(r))
+}
+
+// effectState 为 Buff 中单个效果的运行状态。
+type effectState struct {
+	cfg        *config.BuffEffect
+	nextTickMs int64 // 下次周期触发时间（仅周期效果）
+	ticks      int32 // 已触发次数
+	absorb     int64 // 剩余吸收量（仅护盾效果）
+}
+
+// isShield 判断是否为常驻的护盾效果。
+func (e *effectState) isShield() bool {
+	return conf.BuffTrigger(e.cfg.TriggerType) == conf.BuffTrigger_Passive && conf.BuffEffectType(e.cfg.EffectType) == conf.BuffEffectType_Shield
+}
+
+// canAbsorb 判断护盾能否吸收 school 系别的伤害，P1 为可吸收的系别掩码（0 表示不限系别）。
+func (e *effectState) canAbsorb(school conf.DamageSchool) bool {
+	mask := conf.DamageSchool(e.cfg.P1)
+	return e.absorb > 0 && (mask == conf.DamageSchool_None || mask.Has(school))
+}
+
+// Buff 为实体身上的一个 Buff 实例。
+type Buff struct {
+	Id       uid.Uid      // 实例唯一ID
+	Cfg      *config.Buff // 配表行
+	CasterId uid.Uid      // 施加者ID
+	Stacks   int32        // 当前层数
+	StartMs  int64        // 施加时间
+	EndMs    int64        // 结束时间，0 表示永久
+
+	caster     izone.IEntity
+	skillId    int64 // 施加时的技能ID快照
+	skillLevel int64 // 施加时的技能等级快照
+	effects    []*effectState
+	removed    bool
+}
+
+// BuffId 获取 Buff 配置ID。
+func (b *Buff) BuffId() int64 {
+	return int64(b.Cfg.ID)
+}
+
+// HasTag 判断 Buff 是否带有指定标签。
+func (b *Buff) HasTag(tag string) bool {
+	return conf.BuffHasTag(b.Cfg, tag)
+}
+
+// GetRemainingMs 获取剩余时间，永久 Buff 返回 -1。
+func (b *Buff) GetRemainingMs(nowMs int64) int64 {
+	if b.EndMs <= 0 {
+		return -1
+	}
+	return max(b.EndMs-nowMs, 0)
+}
+
+// GetAbsorb 获取剩余护盾吸收量。
+func (b *Buff) GetAbsorb() int64 {
+	var n int64
+	for _, e := range b.effects {
+		if e.isShield() {
+			n += e.absorb
+		}
+	}
+	return n
+}
+
+// IsExpired 判断是否已到结束时间。
+func (b *Buff) IsExpired(nowMs int64) bool {
+	return b.EndMs > 0 && nowMs >= b.EndMs
+}
+
+// setCaster 记录施加者，周期效果由最近一次施加者结算。
+// 只快照技能ID与等级，不持有施法上下文，避免施法结束后仍被周期效果读写。
+func (b *Buff) setCaster(caster izone.IEntity, ctx *skill.SkillContext) {
+	b.caster = caster
+	b.CasterId = casterId(caster)
+	b.skillId, b.skillLevel = 0, 1
+	if ctx != nil {
+		b.skillLevel = ctx.SkillLevel
+		if ctx.Req != nil {
+			b.skillId = ctx.Req.Cid
+		}
+	}
+}
+
+// newContext 按施加时的快照构造一次性结算上下文。
+func (b *Buff) newContext() *skill.SkillContext {
+	return skill.NewSkillContext(b.caster, &pb.ReqCastSkill{Cid: b.skillId}, b.skillLevel)
+}
+
+// hasAttrEffect 判断 Buff 是否带有常驻的属性修正效果。
+func (b *Buff) hasAttrEffect() bool {
+	for _, e := range b.effects {
+		if conf.BuffTrigger(e.cfg.TriggerType) == conf.BuffTrigger_Passive && conf.BuffEffectType(e.cfg.EffectType) == conf.BuffEffectType_Attribute {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package buff
+
+import (
+	"server/data"
+	"server/data/enum"
+	"server/pb"
+	"server/service/world/zone/entity/mod/combat"
+	"server/service/world/zone/internal/zonetest"
+	"server/service/world/zone/izone"
+)
+
+type (
+	testZone   = zonetest.Zone
+	testEntity = zonetest.Entity
+)
+
+// testModules 为测试实体挂载的模块：战斗与 Buff。
+var testModules = zonetest.Modules{
+	izone.ModuleType_Combat: func() izone.IModule { return &combat.CombatManager{} },
+	izone.ModuleType_Buff:   func() izone.IModule { return &BuffManager{} },
+}
+
+// newTestZone 创建加载了仓库配表的测试区域。
+func newTestZone() *testZone {
+	return zonetest.NewZone(zonetest.LoadTables())
+}
+
+// newUnit 在区域内创建一个生命上限为 maxHp 的测试实体，攻击判定固定命中。
+func newUnit(z *testZone, maxHp int64) *testEntity {
+	e := zonetest.NewEntity(pb.NewVector(0, 0, 0), testModules)
+	e.Init(z, data.EntityInitData{Attrs: zonetest.NewAttrs(map[enum.AttrType]int64{enum.AttrType_MaxHp: maxHp})})
+	combat.GetCombatManager(e).SetRand(zonetest.FixedRand(combat.RatePrecision - 1))
+	return e
+}
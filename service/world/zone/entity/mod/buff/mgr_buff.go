@@ -0,0 +1,549 @@
+package buff
+
+import (
+	"cmp"
+	"slices"
+
+	"server/data"
+	"server/data/conf"
+	"server/data/enum"
+	config "server/data/xls"
+	"server/lib/container"
+	"server/lib/uid"
+	"server/service/world/zone/entity/mod/combat/skill"
+	"server/service/world/zone/izone"
+)
+
+var (
+	_ izone.IModule = (*BuffManager)(nil)
+	_ skill.IBuff   = (*BuffManager)(nil)
+)
+
+// BuffManager 为实体的 Buff 模块，管理施加在本实体身上的所有 Buff（无论施加者是谁）：
+//   - 施加：同 ID / 同组的 Buff 已存在时按 StackRule 刷新、叠层、按施加者独立、顶替或拒绝
+//   - 常驻效果（控制、移动速度、属性修正）在施加时生效、移除时还原，属性修正按层数倍增
+//   - 周期效果（DoT/HoT）按 TickIntervalMs 触发，伤害/治疗由施加者的战斗模块结算，每层结算一次
+//   - 护盾效果在伤害扣除生命前吸收伤害，按优先级、结束时间依次消耗，耗尽时移除
+//   - 到期、主动移除、宿主死亡时移除（带 KeepOnDeath 标签的 Buff 死亡时保留）
+//
+// 注意：非线程安全，只在区域逻辑线程内使用。
+type BuffManager struct {
+	owner izone.IEntity
+	buffs *container.LMap[uid.Uid, *Buff]
+
+	// OnAdd 新 Buff 施加后的回调
+	OnAdd func(b *Buff)
+	// OnRefresh 已有 Buff 被刷新或叠层后的回调
+	OnRefresh func(b *Buff)
+	// OnRemove Buff 移除后的回调
+	OnRemove func(b *Buff, reason RemoveReason)
+	// OnShieldBreak 护盾吸收量耗尽时的回调（随后以 RemoveReason_Break 移除）
+	OnShieldBreak func(b *Buff)
+}
+
+func (m *BuffManager) Init(owner izone.IEntity, _ data.EntityInitData) {
+	m.owner = owner
+	m.buffs = container.NewLMap[uid.Uid, *Buff]()
+}
+
+// Update 触发到期的周期效果并移除到期的 Buff。
+// 周期效果在结束时间点上的触发先于移除，因此 6 秒、每 2 秒一跳的 Buff 共触发 3 次。
+func (m *BuffManager) Update(duration int64) {
+	nowMs := m.nowMs()
+	for _, b := range m.buffs.Values() {
+		if b.removed {
+			continue
+		}
+		m.tick(b, nowMs)
+		if !b.removed && b.IsExpired(nowMs) {
+			m.remove(b, RemoveReason_Expire)
+		}
+	}
+}
+
+// nowMs 获取区域逻辑时间（唯一的时间来源），未加入区域时为 0。
+func (m *BuffManager) nowMs() int64 {
+	if z := m.owner.GetZone(); z != nil {
+		return z.NowMs()
+	}
+	return 0
+}
+
+// getCfg 获取 Buff 配表行。
+func (m *BuffManager) getCfg(buffId int64) *config.Buff {
+	z := m.owner.GetZone()
+	if z == nil || z.GetTables() == nil {
+		return nil
+	}
+	return z.GetTables().GetBuff(buffId)
+}
+
+// AddBuff 实现 skill.IBuff，见 Apply。
+func (m *BuffManager) AddBuff(caster izone.IEntity, ctx *skill.SkillContext, buffId int64, stacks int32, durationMs int64) bool {
+	return m.Apply(caster, ctx, buffId, stacks, durationMs) != nil
+}
+
+// Apply 由 caster 对本实体施加 Buff，stacks/durationMs 为 0 时使用 1 层与配表持续时间（配表为 0 时永久）。
+// 已有同 ID / 同组 Buff 时按配表 StackRule 处理，见 conf.StackRule。
+// 返回新施加或被刷新的 Buff；Buff 不存在、本实体已死亡或被同组更高优先级的 Buff 拒绝时返回 nil。
+func (m *BuffManager) Apply(caster izone.IEntity, ctx *skill.SkillContext, buffId int64, stacks int32, durationMs int64) *Buff {
+	cfg := m.getCfg(buffId)
+	if cfg == nil {
+		return nil
+	}
+	if st := m.status(); st != nil && st.IsDead() {
+		return nil
+	}
+	stacks = max(stacks, 1)
+	if durationMs <= 0 {
+		durationMs = int64(cfg.DurationMs)
+	}
+
+	switch conf.StackRule(cfg.StackRule) {
+	case conf.StackRule_AddStack:
+		if b := m.Find(buffId); b != nil {
+			return m.refresh(b, stacks, durationMs, caster, ctx)
+		}
+	case conf.StackRule_PerCaster:
+		if b := m.findByCaster(buffId, casterId(caster)); b != nil {
+			return m.refresh(b, stacks, durationMs, caster, ctx)
+		}
+	case conf.StackRule_Replace:
+		// 同组有更高优先级的 Buff 时拒绝，否则顶替同组所有 Buff（优先级相同时新的生效）
+		group := m.getGroup(cfg)
+		for _, b := range group {
+			if b.Cfg.Priority > cfg.Priority {
+				return nil
+			}
+		}
+		for _, b := range group {
+			m.remove(b, RemoveReason_Replace)
+		}
+	case conf.StackRule_Reject:
+		// 同组有更高优先级的 Buff 时拒绝；否则同 ID 已存在时刷新，不存在时与同组不强于它的 Buff 共存
+		for _, b := range m.getGroup(cfg) {
+			if b.Cfg.Priority > cfg.Priority {
+				return nil
+			}
+		}
+		if b := m.Find(buffId); b != nil {
+			return m.refresh(b, 0, durationMs, caster, ctx)
+		}
+	default:
+		if b := m.Find(buffId); b != nil {
+			return m.refresh(b, 0, durationMs, caster, ctx)
+		}
+	}
+	return m.add(cfg, stacks, durationMs, caster, ctx)
+}
+
+// refresh 刷新已有 Buff 的持续时间并叠加 addStacks 层（不超过 MaxStacks），施加者更新为最近一次。
+func (m *BuffManager) refresh(b *Buff, addStacks int32, durationMs int64, caster izone.IEntity, ctx *skill.SkillContext) *Buff {
+	if stacks := min(b.Stacks+addStacks, maxStacks(b.Cfg)); stacks != b.Stacks {
+		b.Stacks = stacks
+		m.reapplyAttrs(b)
+	}
+	m.resetShields(b)
+	b.EndMs = endMs(m.nowMs(), durationMs)
+	b.setCaster(caster, ctx)
+	if m.OnRefresh != nil {
+		m.OnRefresh(b)
+	}
+	return b
+}
+
+// add 创建新的 Buff 实例并使常驻效果生效。
+func (m *BuffManager) add(cfg *config.Buff, stacks int32, durationMs int64, caster izone.IEntity, ctx *skill.SkillContext) *Buff {
+	nowMs := m.nowMs()
+	b := &Buff{
+		Id:      uid.Gen(),
+		Cfg:     cfg,
+		Stacks:  min(stacks, maxStacks(cfg)),
+		StartMs: nowMs,
+		EndMs:   endMs(nowMs, durationMs),
+	}
+	b.setCaster(caster, ctx)
+	tables := m.owner.GetZone().GetTables()
+	for _, effId := range cfg.EffectIDs {
+		ec := tables.GetBuffEffect(int64(effId))
+		if ec == nil {
+			continue
+		}
+		st := &effectState{cfg: ec}
+		if conf.BuffTrigger(ec.TriggerType) == conf.BuffTrigger_Tick {
+			st.nextTickMs = nowMs + int64(ec.TickIntervalMs)
+		}
+		b.effects = append(b.effects, st)
+	}
+
+	m.buffs.Set(b.Id, b)
+	m.applyEffects(b)
+	m.resetShields(b)
+	if m.OnAdd != nil {
+		m.OnAdd(b)
+	}
+	return b
+}
+
+// getGroup 获取与 cfg 同组的所有 Buff：配置了 Group 标签时按组名匹配，否则按配置ID匹配。
+func (m *BuffManager) getGroup(cfg *config.Buff) []*Buff {
+	if g := conf.BuffGroup(cfg); g != "" {
+		return m.filter(func(b *Buff) bool { return conf.BuffGroup(b.Cfg) == g })
+	}
+	return m.GetBuffsById(int64(cfg.ID))
+}
+
+// findByCaster 获取由指定实体施加的指定配置ID的 Buff，不存在时返回 nil。
+func (m *BuffManager) findByCaster(buffId int64, casterId uid.Uid) *Buff {
+	var found *Buff
+	m.buffs.ForEachBreakable(func(b *Buff) bool {
+		if b.BuffId() == buffId && b.CasterId == casterId {
+			found = b
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+func casterId(caster izone.IEntity) uid.Uid {
+	if caster == nil {
+		return uid.Zero
+	}
+	return caster.GetId()
+}
+
+func maxStacks(cfg *config.Buff) int32 {
+	return int32(max(cfg.MaxStacks, 1))
+}
+
+func endMs(nowMs, durationMs int64) int64 {
+	if durationMs <= 0 {
+		return 0
+	}
+	return nowMs + durationMs
+}
+
+// status 获取本实体的控制/移动速度接口，未挂载战斗模块时返回 nil。
+func (m *BuffManager) status() skill.IStatus {
+	st, _ := m.owner.GetModule(izone.ModuleType_Combat).(skill.IStatus)
+	return st
+}
+
+// applyEffects 使常驻效果生效。
+func (m *BuffManager) applyEffects(b *Buff) {
+	st := m.status()
+	if st == nil {
+		return
+	}
+	for _, e := range b.effects {
+		if conf.BuffTrigger(e.cfg.TriggerType) != conf.BuffTrigger_Passive {
+			continue
+		}
+		switch conf.BuffEffectType(e.cfg.EffectType) {
+		case conf.BuffEffectType_Control:
+			st.AddControl(conf.CCType(e.cfg.CCType))
+		case conf.BuffEffectType_MoveSpeed:
+			st.AddMoveSpeedPct(e.cfg.MoveSpeedPct)
+		case conf.BuffEffectType_Attribute:
+			st.AddAttrModifier(attrModifier(b, e.cfg))
+		}
+	}
+}
+
+// revertEffects 还原常驻效果。
+func (m *BuffManager) revertEffects(b *Buff) {
+	st := m.status()
+	if st == nil {
+		return
+	}
+	for _, e := range b.effects {
+		if conf.BuffTrigger(e.cfg.TriggerType) != conf.BuffTrigger_Passive {
+			continue
+		}
+		switch conf.BuffEffectType(e.cfg.EffectType) {
+		case conf.BuffEffectType_Control:
+			st.RemoveControl(conf.CCType(e.cfg.CCType))
+		case conf.BuffEffectType_MoveSpeed:
+			st.RemoveMoveSpeedPct(e.cfg.MoveSpeedPct)
+		}
+	}
+	if b.hasAttrEffect() {
+		st.RemoveAttrModifiers(b.Id)
+	}
+}
+
+// reapplyAttrs 层数变化后按新层数重新添加属性修正。
+func (m *BuffManager) reapplyAttrs(b *Buff) {
+	st := m.status()
+	if st == nil || !b.hasAttrEffect() {
+		return
+	}
+	st.RemoveAttrModifiers(b.Id)
+	for _, e := range b.effects {
+		if conf.BuffTrigger(e.cfg.TriggerType) == conf.BuffTrigger_Passive && conf.BuffEffectType(e.cfg.EffectType) == conf.BuffEffectType_Attribute {
+			st.AddAttrModifier(attrModifier(b, e.cfg))
+		}
+	}
+}
+
+// attrModifier 生成属性效果对应的修正，来源为 Buff 实例ID；除覆盖外修正值按层数倍增。
+// 配表中百分比类修正的 ModValue 为百分数（-20 表示 -20%），这里换算为属性集使用的万分比。
+func attrModifier(b *Buff, ec *config.BuffEffect) data.AttrModifier {
+	mod := data.AttrModifier{
+		Source:  b.Id,
+		Type:    enum.AttrType(ec.AttributeType),
+		ModType: data.AttrModType(ec.ModType),
+		Value:   int64(ec.ModValue),
+	}
+	if mod.ModType == data.AttrModType_PctAdd || mod.ModType == data.AttrModType_PctMul {
+		mod.Value *= data.AttrRatePrecision / 100
+	}
+	if mod.ModType != data.AttrModType_Override {
+		mod.Value *= int64(b.Stacks)
+	}
+	return mod
+}
+
+// resetShields 按施加者重新计算护盾吸收量 × 层数：配置了护盾公式（HealFormulaID）时取施加者按公式计算的数值，
+// 未配置公式或施加者没有战斗模块时取固定的 ShieldAmount。
+func (m *BuffManager) resetShields(b *Buff) {
+	for _, e := range b.effects {
+		if !e.isShield() {
+			continue
+		}
+		amount := int64(e.cfg.ShieldAmount)
+		if e.cfg.HealFormulaID > 0 && b.caster != nil {
+			if cm, _ := b.caster.GetModule(izone.ModuleType_Combat).(skill.ICombat); cm != nil {
+				amount = cm.CalculateHeal(b.newContext(), b.caster, m.owner, int64(e.cfg.HealFormulaID))
+			}
+		}
+		e.absorb = max(amount, 0) * int64(b.Stacks)
+	}
+}
+
+// AbsorbDamage 实现 skill.IBuff：由护盾吸收 school 系别的伤害，返回吸收量。
+// 护盾按 Buff 优先级从高到低、结束时间从早到晚（永久的最后）、施加时间从早到晚依次消耗，
+// 吸收量耗尽的 Buff 触发 OnShieldBreak 后移除。
+func (m *BuffManager) AbsorbDamage(damage int64, school conf.DamageSchool) int64 {
+	shields := m.filter(func(b *Buff) bool {
+		return slices.ContainsFunc(b.effects, func(e *effectState) bool { return e.isShield() && e.canAbsorb(school) })
+	})
+	slices.SortStableFunc(shields, compareShield)
+
+	var absorbed int64
+	for _, b := range shields {
+		for _, e := range b.effects {
+			if absorbed >= damage {
+				break
+			}
+			if e.isShield() && e.canAbsorb(school) {
+				n := min(e.absorb, damage-absorbed)
+				e.absorb -= n
+				absorbed += n
+			}
+		}
+		if b.GetAbsorb() <= 0 {
+			if m.OnShieldBreak != nil {
+				m.OnShieldBreak(b)
+			}
+			m.remove(b, RemoveReason_Break)
+		}
+		if absorbed >= damage {
+			break
+		}
+	}
+	return absorbed
+}
+
+// compareShield 护盾消耗顺序：优先级高的、先结束的、先施加的优先。
+func compareShield(a, b *Buff) int {
+	if c := cmp.Compare(b.Cfg.Priority, a.Cfg.Priority); c != 0 {
+		return c
+	}
+	if a.EndMs != b.EndMs {
+		if a.EndMs <= 0 {
+			return 1
+		}
+		if b.EndMs <= 0 {
+			return -1
+		}
+		return cmp.Compare(a.EndMs, b.EndMs)
+	}
+	return cmp.Compare(a.StartMs, b.StartMs)
+}
+
+// tick 触发到期的周期效果（不晚于 Buff 结束时间），帧间隔较大时补齐错过的触发。
+func (m *BuffManager) tick(b *Buff, nowMs int64) {
+	limit := nowMs
+	if b.EndMs > 0 {
+		limit = min(limit, b.EndMs)
+	}
+	for _, e := range b.effects {
+		interval := int64(e.cfg.TickIntervalMs)
+		if conf.BuffTrigger(e.cfg.TriggerType) != conf.BuffTrigger_Tick || interval <= 0 {
+			continue
+		}
+		for e.nextTickMs <= limit && (e.cfg.MaxTicks <= 0 || e.ticks < int32(e.cfg.MaxTicks)) {
+			e.ticks++
+			e.nextTickMs += interval
+			m.trigger(b, e)
+			if b.removed {
+				return
+			}
+		}
+	}
+}
+
+// trigger 触发一次周期效果，由施加者的战斗模块按层数结算。
+func (m *BuffManager) trigger(b *Buff, e *effectState) {
+	if b.caster == nil {
+		return
+	}
+	cm, _ := b.caster.GetModule(izone.ModuleType_Combat).(skill.ICombat)
+	if cm == nil {
+		return
+	}
+	for range b.Stacks {
+		switch conf.BuffEffectType(e.cfg.EffectType) {
+		case conf.BuffEffectType_Damage:
+			cm.DealDamage(b.newContext(), m.owner, int64(e.cfg.DamageFormulaID), 0)
+		case conf.BuffEffectType_Heal:
+			cm.DealHeal(b.newContext(), m.owner, int64(e.cfg.HealFormulaID))
+		}
+		if b.removed {
+			return
+		}
+	}
+}
+
+// remove 移除 Buff 并还原其常驻效果。
+func (m *BuffManager) remove(b *Buff, reason RemoveReason) {
+	if b.removed {
+		return
+	}
+	b.removed = true
+	m.buffs.Delete(b.Id)
+	m.revertEffects(b)
+	if m.OnRemove != nil {
+		m.OnRemove(b, reason)
+	}
+}
+
+// RemoveBuff 按实例ID移除 Buff。
+func (m *BuffManager) RemoveBuff(id uid.Uid) bool {
+	b, ok := m.buffs.Get(id)
+	if !ok {
+		return false
+	}
+	m.remove(b, RemoveReason_Remove)
+	return true
+}
+
+// RemoveBuffById 移除指定配置ID的所有 Buff，返回移除数量。
+func (m *BuffManager) RemoveBuffById(buffId int64) int {
+	return m.removeIf(func(b *Buff) bool { return b.BuffId() == buffId }, RemoveReason_Remove)
+}
+
+// RemoveBuffByTag 移除带有指定标签的所有 Buff，返回移除数量。
+func (m *BuffManager) RemoveBuffByTag(tag string) int {
+	return m.removeIf(func(b *Buff) bool { return b.HasTag(tag) }, RemoveReason_Remove)
+}
+
+// RemoveOnDeath 实现 skill.IBuff：宿主死亡时移除不带 KeepOnDeath 标签的 Buff。
+func (m *BuffManager) RemoveOnDeath() {
+	m.removeIf(func(b *Buff) bool { return !b.HasTag(conf.BuffTag_KeepOnDeath) }, RemoveReason_Death)
+}
+
+func (m *BuffManager) removeIf(match func(b *Buff) bool, reason RemoveReason) int {
+	n := 0
+	for _, b := range m.buffs.Values() {
+		if !b.removed && match(b) {
+			m.remove(b, reason)
+			n++
+		}
+	}
+	return n
+}
+
+// GetBuff 按实例ID获取 Buff。
+func (m *BuffManager) GetBuff(id uid.Uid) *Buff {
+	b, _ := m.buffs.Get(id)
+	return b
+}
+
+// Find 获取指定配置ID的一个 Buff，不存在时返回 nil。
+func (m *BuffManager) Find(buffId int64) *Buff {
+	var found *Buff
+	m.buffs.ForEachBreakable(func(b *Buff) bool {
+		if b.BuffId() == buffId {
+			found = b
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// HasBuff 实现 skill.IBuff：判断身上是否有指定配置ID的 Buff。
+func (m *BuffManager) HasBuff(buffId int64) bool {
+	return m.Find(buffId) != nil
+}
+
+// HasTag 判断身上是否有带指定标签的 Buff。
+func (m *BuffManager) HasTag(tag string) bool {
+	return len(m.GetBuffsByTag(tag)) > 0
+}
+
+// GetStacks 获取指定配置ID的 Buff 的总层数。
+func (m *BuffManager) GetStacks(buffId int64) int32 {
+	var n int32
+	for _, b := range m.GetBuffsById(buffId) {
+		n += b.Stacks
+	}
+	return n
+}
+
+// GetBuffsById 获取指定配置ID的所有 Buff。
+func (m *BuffManager) GetBuffsById(buffId int64) []*Buff {
+	return m.filter(func(b *Buff) bool { return b.BuffId() == buffId })
+}
+
+// GetBuffsByTag 获取带有指定标签的所有 Buff。
+func (m *BuffManager) GetBuffsByTag(tag string) []*Buff {
+	return m.filter(func(b *Buff) bool { return b.HasTag(tag) })
+}
+
+// GetBuffsByCaster 获取由指定实体施加的所有 Buff。
+func (m *BuffManager) GetBuffsByCaster(casterId uid.Uid) []*Buff {
+	return m.filter(func(b *Buff) bool { return b.CasterId == casterId })
+}
+
+func (m *BuffManager) filter(match func(b *Buff) bool) []*Buff {
+	var result []*Buff
+	m.buffs.ForEach(func(b *Buff) {
+		if match(b) {
+			result = append(result, b)
+		}
+	})
+	return result
+}
+
+// Count 获取身上的 Buff 数量。
+func (m *BuffManager) Count() int {
+	return m.buffs.Len()
+}
+
+// ForEach 遍历身上的 Buff。
+func (m *BuffManager) ForEach(fn func(b *Buff)) {
+	m.buffs.ForEach(fn)
+}
+
+// GetBuffManager 获取实体的 Buff 模块，未挂载时返回 nil。
+func GetBuffManager(e izone.IEntity) *BuffManager {
+	if e == nil {
+		return nil
+	}
+	m, _ := e.GetModule(izone.ModuleType_Buff).(*BuffManager)
+	return m
+}
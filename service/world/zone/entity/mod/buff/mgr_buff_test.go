@@ -0,0 +1,461 @@
+package buff
+
+import (
+	"testing"
+
+	"server/data"
+	"server/data/conf"
+	"server/data/enum"
+	config "server/data/xls"
+	"server/pb"
+	"server/service/world/zone/entity/mod/combat"
+	"server/service/world/zone/entity/mod/combat/skill"
+	"server/service/world/zone/internal/zonetest"
+)
+
+const (
+	buffBurn    = 2001 // 灼烧：6 秒，每 2 秒一跳
+	buffSlow    = 2002 // 减速：4 秒，移动速度 -50%
+	buffStun    = 2003 // 眩晕：1 秒
+	buffShield  = 2004 // 奥术护盾
+	buffCurse   = 2008 // 虚弱诅咒：体质 -20%
+	buffBerserk = 2010 // 狂暴
+)
+
+func TestBuffManager_PeriodicDamage(t *testing.T) {
+	z := newTestZone()
+	caster, target := newUnit(z, 1000), newUnit(z, 1000)
+	bm := GetBuffManager(target)
+	ticks := 0
+	combat.GetCombatManager(target).OnCombatLog = func(log *combat.CombatLog) {
+		if log.Type == combat.CombatLogType_Damage && log.Source == caster.GetId() {
+			ticks++
+		}
+	}
+	var removed []RemoveReason
+	bm.OnRemove = func(b *Buff, reason RemoveReason) { removed = append(removed, reason) }
+
+	b := bm.Apply(caster, nil, buffBurn, 0, 0)
+	if b == nil || b.CasterId != caster.GetId() || b.GetRemainingMs(z.NowMs()) != 6000 {
+		t.Fatalf("Expected burn applied by caster for 6000ms, got %+v", b)
+	}
+	// Buff 在目标身上，施加者身上没有
+	if !bm.HasBuff(buffBurn) || GetBuffManager(caster).HasBuff(buffBurn) {
+		t.Error("Expected buff on the target only")
+	}
+
+	z.Advance(1999)
+	if ticks != 0 {
+		t.Errorf("Expected no tick before interval, got %d", ticks)
+	}
+	// 一帧跨过多个间隔时补齐，结束时间点上的一跳先于移除
+	z.Advance(4001)
+	if ticks != 3 {
+		t.Errorf("Expected 3 ticks, got %d", ticks)
+	}
+	if bm.HasBuff(buffBurn) || len(removed) != 1 || removed[0] != RemoveReason_Expire {
+		t.Errorf("Expected burn expired, got %v", removed)
+	}
+	if hp := combat.GetCombatManager(target).GetHp(); hp >= 1000 {
+		t.Errorf("Expected hp reduced, got %d", hp)
+	}
+}
+
+func TestBuffManager_CasterSnapshot(t *testing.T) {
+	z := newTestZone()
+	caster, target := newUnit(z, 1000), newUnit(z, 1000)
+	var logs []*combat.CombatLog
+	combat.GetCombatManager(target).OnCombatLog = func(log *combat.CombatLog) {
+		if log.Type == combat.CombatLogType_Damage {
+			logs = append(logs, log)
+		}
+	}
+
+	ctx := skill.NewSkillContext(caster, &pb.ReqCastSkill{Cid: 1001}, 3)
+	b := GetBuffManager(target).Apply(caster, ctx, buffBurn, 0, 0)
+	if b == nil || b.skillId != 1001 || b.skillLevel != 3 {
+		t.Fatalf("Expected skill 1001 level 3 snapshot, got %+v", b)
+	}
+	ctx.Finish()
+	ctx.SkillLevel = 9
+
+	z.Advance(2000)
+	if len(logs) != 1 || logs[0].SkillId != 1001 {
+		t.Fatalf("Expected one tick attributed to skill 1001, got %v", logs)
+	}
+	// 周期效果不再读写施法上下文
+	if ctx.TotalDamage != 0 || ctx.TotalHits != 0 {
+		t.Errorf("Expected cast context untouched, got damage=%d hits=%d", ctx.TotalDamage, ctx.TotalHits)
+	}
+}
+
+func TestBuffManager_StackRules(t *testing.T) {
+	// 每个用例把灼烧与减速配置为同一规则，grouped 时两者同属一个互斥组
+	type step struct {
+		caster   int // 施加者下标
+		buffId   int64
+		stacks   int32
+		rejected bool
+	}
+	cases := []struct {
+		name         string
+		rule         conf.StackRule
+		grouped      bool
+		burnPriority int
+		slowPriority int
+		steps        []step
+		wantCount    int
+		wantStacks   map[int64]int32 // 各配置ID的总层数，不存在为 0
+		wantReplaced int
+	}{
+		{
+			name:       "Refresh keeps stacks",
+			rule:       conf.StackRule_Refresh,
+			steps:      []step{{0, buffBurn, 1, false}, {1, buffBurn, 2, false}},
+			wantCount:  1,
+			wantStacks: map[int64]int32{buffBurn: 1},
+		},
+		{
+			name:       "AddStack capped",
+			rule:       conf.StackRule_AddStack,
+			steps:      []step{{0, buffBurn, 1, false}, {1, buffBurn, 5, false}},
+			wantCount:  1,
+			wantStacks: map[int64]int32{buffBurn: 3},
+		},
+		{
+			name:       "PerCaster separate instances",
+			rule:       conf.StackRule_PerCaster,
+			steps:      []step{{0, buffBurn, 2, false}, {1, buffBurn, 1, false}, {0, buffBurn, 2, false}},
+			wantCount:  2,
+			wantStacks: map[int64]int32{buffBurn: 4},
+		},
+		{
+			name:         "Replace same id",
+			rule:         conf.StackRule_Replace,
+			steps:        []step{{0, buffBurn, 1, false}, {1, buffBurn, 1, false}},
+			wantCount:    1,
+			wantStacks:   map[int64]int32{buffBurn: 1},
+			wantReplaced: 1,
+		},
+		{
+			name:         "Replace lower priority in group",
+			rule:         conf.StackRule_Replace,
+			grouped:      true,
+			burnPriority: 1,
+			slowPriority: 2,
+			steps:        []step{{0, buffBurn, 1, false}, {0, buffSlow, 1, false}},
+			wantCount:    1,
+			wantStacks:   map[int64]int32{buffSlow: 1},
+			wantReplaced: 1,
+		},
+		{
+			name:         "Replace equal priority, newest wins",
+			rule:         conf.StackRule_Replace,
+			grouped:      true,
+			burnPriority: 1,
+			slowPriority: 1,
+			steps:        []step{{0, buffBurn, 1, false}, {0, buffSlow, 1, false}},
+			wantCount:    1,
+			wantStacks:   map[int64]int32{buffSlow: 1},
+			wantReplaced: 1,
+		},
+		{
+			name:         "Replace rejected by stronger",
+			rule:         conf.StackRule_Replace,
+			grouped:      true,
+			burnPriority: 2,
+			slowPriority: 1,
+			steps:        []step{{0, buffBurn, 1, false}, {0, buffSlow, 1, true}},
+			wantCount:    1,
+			wantStacks:   map[int64]int32{buffBurn: 1},
+		},
+		{
+			name:       "Reject same id refreshes",
+			rule:       conf.StackRule_Reject,
+			steps:      []step{{0, buffBurn, 1, false}, {1, buffBurn, 2, false}},
+			wantCount:  1,
+			wantStacks: map[int64]int32{buffBurn: 1},
+		},
+		{
+			name:         "Reject rejected by stronger",
+			rule:         conf.StackRule_Reject,
+			grouped:      true,
+			burnPriority: 2,
+			slowPriority: 1,
+			steps:        []step{{0, buffBurn, 1, false}, {0, buffSlow, 1, true}},
+			wantCount:    1,
+			wantStacks:   map[int64]int32{buffBurn: 1},
+		},
+		{
+			name:         "Reject coexists with weaker",
+			rule:         conf.StackRule_Reject,
+			grouped:      true,
+			burnPriority: 1,
+			slowPriority: 2,
+			steps:        []step{{0, buffBurn, 1, false}, {0, buffSlow, 1, false}, {1, buffBurn, 1, true}},
+			wantCount:    2,
+			wantStacks:   map[int64]int32{buffBurn: 1, buffSlow: 1},
+		},
+		{
+			name:         "Reject coexists with equal priority",
+			rule:         conf.StackRule_Reject,
+			grouped:      true,
+			burnPriority: 1,
+			slowPriority: 1,
+			steps:        []step{{0, buffBurn, 1, false}, {1, buffSlow, 1, false}},
+			wantCount:    2,
+			wantStacks:   map[int64]int32{buffBurn: 1, buffSlow: 1},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			z := newTestZone()
+			for id, prio := range map[int64]int{buffBurn: c.burnPriority, buffSlow: c.slowPriority} {
+				cfg := z.Tables.Buffs[id]
+				cfg.StackRule, cfg.MaxStacks, cfg.Priority = int(c.rule), 1, prio
+				if c.rule.Stackable() {
+					cfg.MaxStacks = 3
+				}
+				if c.grouped {
+					cfg.Tags += "|" + conf.BuffTag_GroupPrefix + "Test"
+				}
+			}
+			casters := []*testEntity{newUnit(z, 1000), newUnit(z, 1000)}
+			bm := GetBuffManager(newUnit(z, 1000))
+			replaced := 0
+			bm.OnRemove = func(_ *Buff, reason RemoveReason) {
+				if reason == RemoveReason_Replace {
+					replaced++
+				}
+			}
+
+			for i, s := range c.steps {
+				z.Advance(1000)
+				b := bm.Apply(casters[s.caster], nil, s.buffId, s.stacks, 0)
+				if s.rejected {
+					if b != nil {
+						t.Errorf("Expected step %d rejected", i)
+					}
+					continue
+				}
+				// 新施加或刷新后持续时间从当前时间重新计算，施加者为最近一次
+				if b == nil || b.GetRemainingMs(z.NowMs()) != int64(b.Cfg.DurationMs) || b.CasterId != casters[s.caster].GetId() {
+					t.Fatalf("Expected step %d applied with full duration, got %+v", i, b)
+				}
+			}
+
+			if n := bm.Count(); n != c.wantCount {
+				t.Errorf("Expected %d buffs, got %d", c.wantCount, n)
+			}
+			for _, id := range []int64{buffBurn, buffSlow} {
+				if got := bm.GetStacks(id); got != c.wantStacks[id] {
+					t.Errorf("Expected %d stacks of %d, got %d", c.wantStacks[id], id, got)
+				}
+			}
+			if replaced != c.wantReplaced {
+				t.Errorf("Expected %d replaced, got %d", c.wantReplaced, replaced)
+			}
+		})
+	}
+}
+
+func TestBuffManager_Apply(t *testing.T) {
+	z := newTestZone()
+	caster, target := newUnit(z, 1000), newUnit(z, 1000)
+	bm := GetBuffManager(target)
+
+	// 自定义持续时间
+	if b := bm.Apply(caster, nil, buffShield, 1, 500); b.GetRemainingMs(z.NowMs()) != 500 {
+		t.Errorf("Expected custom duration 500, got %d", b.GetRemainingMs(z.NowMs()))
+	}
+	if bm.Apply(caster, nil, 999999, 1, 0) != nil {
+		t.Error("Expected unknown buff rejected")
+	}
+}
+
+func TestBuffManager_PassiveEffects(t *testing.T) {
+	z := newTestZone()
+	caster, target := newUnit(z, 1000), newUnit(z, 1000)
+	bm, cm := GetBuffManager(target), combat.GetCombatManager(target)
+
+	bm.Apply(caster, nil, buffSlow, 0, 0)
+	bm.Apply(caster, nil, buffStun, 0, 0)
+	if cm.MoveSpeedRate() != 0.5 || !cm.HasControl(conf.CCType_Stun) {
+		t.Fatalf("Expected slow and stun applied, got rate %v", cm.MoveSpeedRate())
+	}
+
+	// 眩晕 1 秒后到期还原，减速仍在
+	z.Advance(1000)
+	if cm.HasControl(conf.CCType_Stun) || cm.MoveSpeedRate() != 0.5 {
+		t.Error("Expected stun reverted and slow kept")
+	}
+	if n := bm.RemoveBuffById(buffSlow); n != 1 || cm.MoveSpeedRate() != 1 {
+		t.Errorf("Expected slow removed and reverted, got %d removed, rate %v", n, cm.MoveSpeedRate())
+	}
+}
+
+func TestBuffManager_Queries(t *testing.T) {
+	z := newTestZone()
+	a, b, target := newUnit(z, 1000), newUnit(z, 1000), newUnit(z, 1000)
+	bm := GetBuffManager(target)
+	bm.Apply(a, nil, buffBurn, 0, 0)
+	bm.Apply(a, nil, buffSlow, 0, 0)
+	bm.Apply(b, nil, buffBerserk, 0, 0)
+
+	if got := bm.GetBuffsByTag("Debuff"); len(got) != 2 {
+		t.Errorf("Expected 2 debuffs, got %d", len(got))
+	}
+	if !bm.HasTag("Enrage") || bm.HasTag("Poison") {
+		t.Error("Unexpected HasTag result")
+	}
+	if got := bm.GetBuffsByCaster(b.GetId()); len(got) != 1 || got[0].BuffId() != buffBerserk {
+		t.Errorf("Expected berserk from b, got %v", got)
+	}
+	if got := bm.GetBuffsById(buffSlow); len(got) != 1 || bm.GetBuff(got[0].Id) != got[0] {
+		t.Error("Expected lookup by id and instance id")
+	}
+
+	if n := bm.RemoveBuffByTag("Debuff"); n != 2 || bm.Count() != 1 {
+		t.Errorf("Expected 2 debuffs removed, got %d", n)
+	}
+}
+
+func TestBuffManager_Death(t *testing.T) {
+	z := newTestZone()
+	z.Tables.Buffs[buffShield].Tags += "|" + conf.BuffTag_KeepOnDeath
+	caster, target := newUnit(z, 1000), newUnit(z, 1000)
+	bm := GetBuffManager(target)
+	bm.Apply(caster, nil, buffStun, 0, 0)
+	bm.Apply(caster, nil, buffShield, 0, 0)
+
+	combat.GetCombatManager(target).Kill(caster)
+	if bm.HasBuff(buffStun) || !bm.HasBuff(buffShield) {
+		t.Error("Expected buffs removed on death except KeepOnDeath")
+	}
+	if combat.GetCombatManager(target).HasControl(conf.CCType_Stun) {
+		t.Error("Expected stun reverted on death")
+	}
+	if bm.Apply(caster, nil, buffBurn, 0, 0) != nil {
+		t.Error("Expected dead target to reject buffs")
+	}
+}
+
+func TestBuffManager_AttributeEffects(t *testing.T) {
+	z := newTestZone()
+	caster, target := newUnit(z, 1000), newUnit(z, 1000)
+	bm, attrs := GetBuffManager(target), combat.GetCombatManager(target).GetAttrs()
+	attrs.SetBase(enum.AttrType_Constitution, 100)
+
+	// 虚弱诅咒：体质 -20%（配表为百分数）
+	curse := bm.Apply(caster, nil, buffCurse, 0, 0)
+	if v := attrs.GetValue(enum.AttrType_Constitution); v != 80 {
+		t.Errorf("Expected constitution 80 under curse, got %d", v)
+	}
+	bm.RemoveBuff(curse.Id)
+	if v := attrs.GetValue(enum.AttrType_Constitution); v != 100 {
+		t.Errorf("Expected constitution restored to 100, got %d", v)
+	}
+
+	// 狂暴：物攻增伤 +30%、物理减伤 -20%（易伤），修正值随层数倍增
+	z.Tables.Buffs[buffBerserk].StackRule, z.Tables.Buffs[buffBerserk].MaxStacks = int(conf.StackRule_AddStack), 3
+	bm.Apply(target, nil, buffBerserk, 0, 0)
+	bm.Apply(target, nil, buffBerserk, 1, 0)
+	if v := attrs.GetValue(enum.AttrType_PhyDamageBonus); v != 6000 {
+		t.Errorf("Expected damage bonus 6000 at 2 stacks, got %d", v)
+	}
+	if v := attrs.GetValue(enum.AttrType_PhyDamageReduction); v != -4000 {
+		t.Errorf("Expected damage reduction -4000 at 2 stacks, got %d", v)
+	}
+	z.Advance(10000)
+	if v := attrs.GetValue(enum.AttrType_PhyDamageBonus); v != 0 || bm.HasBuff(buffBerserk) {
+		t.Errorf("Expected berserk expired and reverted, got %d", v)
+	}
+}
+
+func TestBuffManager_Shields(t *testing.T) {
+	z := newTestZone()
+	// 火焰结界：只吸收火焰伤害，比奥术护盾先结束
+	z.Tables.BuffEffects[29001] = &config.BuffEffect{ID: 29001, EffectType: int(conf.BuffEffectType_Shield), TriggerType: int(conf.BuffTrigger_Passive),
+		TriggerChance: 1, ShieldAmount: 200, P1: int(conf.DamageSchool_Fire)}
+	z.Tables.Buffs[2901] = &config.Buff{ID: 2901, BuffType: 1, DurationMs: 3000, StackRule: 1, MaxStacks: 1, Priority: 1, EffectIDs: []int{29001}}
+	caster, target := newUnit(z, 2000), newUnit(z, 2000)
+	bm, cm := GetBuffManager(target), combat.GetCombatManager(target)
+	var broken []int64
+	bm.OnShieldBreak = func(b *Buff) { broken = append(broken, b.BuffId()) }
+	var logs []*combat.CombatLog
+	cm.OnCombatLog = func(log *combat.CombatLog) { logs = append(logs, log) }
+
+	// 奥术护盾固定吸收 1500
+	arcane := bm.Apply(caster, nil, buffShield, 0, 0)
+	ward := bm.Apply(caster, nil, 2901, 0, 0)
+	if arcane.GetAbsorb() != 1500 || ward.GetAbsorb() != 200 {
+		t.Fatalf("Expected absorbs 1500/200, got %d/%d", arcane.GetAbsorb(), ward.GetAbsorb())
+	}
+
+	// 火焰伤害先消耗先结束的火焰结界
+	r := combat.GetCombatManager(caster).DealDamageResult(nil, target, 1003, 0)
+	if r.Absorbed != r.Damage || r.Applied != 0 || ward.GetAbsorb() != 200-r.Damage || arcane.GetAbsorb() != 1500 {
+		t.Errorf("Expected fire damage absorbed by ward, got %s", r)
+	}
+
+	// 无系别伤害只能由不限系别的护盾吸收，耗尽时破盾并移除，剩余伤害扣除生命
+	combat.GetCombatManager(caster).ApplyDamage(target, 1700)
+	if cm.GetHp() != 1800 || bm.HasBuff(buffShield) || len(broken) != 1 || broken[0] != buffShield {
+		t.Errorf("Expected arcane shield broken and hp 1800, got hp %d broken %v", cm.GetHp(), broken)
+	}
+	if last := logs[len(logs)-1]; last.Absorbed != 1500 || last.Applied != 200 || last.Overflow != 0 {
+		t.Errorf("Expected log absorbed 1500 applied 200, got %s", last)
+	}
+	if !bm.HasBuff(2901) {
+		t.Error("Expected fire ward kept")
+	}
+}
+
+func TestBuffManager_ShieldFormula(t *testing.T) {
+	z := newTestZone()
+	// 法力屏障：吸收量由护盾吸收公式（1010）计算，无施加者时取固定值
+	z.Tables.BuffEffects[29002] = &config.BuffEffect{ID: 29002, EffectType: int(conf.BuffEffectType_Shield), TriggerType: int(conf.BuffTrigger_Passive),
+		TriggerChance: 1, ShieldAmount: 100, HealFormulaID: 1010}
+	z.Tables.Buffs[2902] = &config.Buff{ID: 2902, BuffType: 1, DurationMs: 3000, StackRule: 1, MaxStacks: 1, EffectIDs: []int{29002}}
+	caster := zonetest.NewEntity(pb.NewVector(0, 0, 0), testModules)
+	caster.Init(z, data.EntityInitData{Attrs: zonetest.NewAttrs(map[enum.AttrType]int64{
+		enum.AttrType_MaxHp:       1000,
+		enum.AttrType_MagicAttack: 200,
+	})})
+	target := newUnit(z, 1000)
+
+	// 1500 基础 + 150 × (3 - 1) 级 + 200 法术强度 × 1
+	ctx := skill.NewSkillContext(caster, &pb.ReqCastSkill{Cid: 1001}, 3)
+	if b := GetBuffManager(target).Apply(caster, ctx, 2902, 0, 0); b == nil || b.GetAbsorb() != 2000 {
+		t.Fatalf("Expected shield formula absorb 2000, got %+v", b)
+	}
+	if b := GetBuffManager(newUnit(z, 1000)).Apply(nil, nil, 2902, 0, 0); b == nil || b.GetAbsorb() != 100 {
+		t.Errorf("Expected fixed ShieldAmount without caster, got %+v", b)
+	}
+	// 奥术护盾同样按护盾吸收公式计算
+	if b := GetBuffManager(target).Apply(caster, ctx, buffShield, 0, 0); b == nil || b.GetAbsorb() != 2000 {
+		t.Errorf("Expected arcane shield absorb 2000, got %+v", b)
+	}
+}
+
+func TestCompareShield(t *testing.T) {
+	shield := func(priority int, startMs, endMs int64) *Buff {
+		return &Buff{Cfg: &config.Buff{Priority: priority}, StartMs: startMs, EndMs: endMs}
+	}
+	cases := []struct {
+		name  string
+		first *Buff
+		then  *Buff
+	}{
+		{"higher priority first", shield(2, 0, 9000), shield(1, 0, 1000)},
+		{"earlier end first", shield(1, 0, 1000), shield(1, 0, 2000)},
+		{"permanent last", shield(1, 0, 5000), shield(1, 0, 0)},
+		{"earlier start first", shield(1, 100, 2000), shield(1, 200, 2000)},
+	}
+	for _, c := range cases {
+		if compareShield(c.first, c.then) >= 0 || compareShield(c.then, c.first) <= 0 {
+			t.Errorf("%s: unexpected order", c.name)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package combat
+
+import (
+	"fmt"
+
+	"server/lib/uid"
+)
+
+// CombatLogType 为战斗日志类型。
+type CombatLogType int32
+
+const (
+	CombatLogType_Invalid CombatLogType = 0
+	CombatLogType_Damage  CombatLogType = 1 // 伤害
+	CombatLogType_Heal    CombatLogType = 2 // 治疗
+)
+
+// CombatLog 为一次伤害/治疗结算的战斗日志，由承受方的战斗模块产生。
+type CombatLog struct {
+	Type     CombatLogType
+	Source   uid.Uid    // 来源实体ID
+	Target   uid.Uid    // 承受实体ID
+	SkillId  int64      // 来源技能ID（非技能结算时为 0）
+	Outcome  HitOutcome // 攻击判定结果（仅伤害有效）
+	Value    int64      // 结算前的数值
+	Absorbed int64      // 被护盾吸收的数值（仅伤害有效）
+	Applied  int64      // 实际生效的数值（实际扣除/恢复的生命值）
+	Overflow int64      // 溢出的数值（过量伤害/过量治疗）
+	NowMs    int64      // 结算时间
+}
+
+func (l *CombatLog) String() string {
+	return fmt.Sprintf("type=%d source=%d target=%d skill=%d outcome=%d value=%d absorbed=%d applied=%d overflow=%d",
+		l.Type, l.Source, l.Target, l.SkillId, l.Outcome, l.Value, l.Absorbed, l.Applied, l.Overflow)
+}
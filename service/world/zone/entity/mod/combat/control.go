@@ -0,0 +1,49 @@
+package combat
+
+import (
+	"server/data/conf"
+)
+
+// AddControl 对本实体施加控制，同类控制按次数叠加，全部移除后才解除。
+// 眩晕与沉默会打断正在吟唱/引导且可被控制打断的技能（见 SkillManager.InterruptByControl）。
+func (m *CombatManager) AddControl(t conf.CCType) {
+	if t <= conf.CCType_None || t >= conf.CCType_Max {
+		return
+	}
+	m.controls[t]++
+	if t == conf.CCType_Stun || t == conf.CCType_Silence {
+		m.skillMgr.InterruptByControl(t)
+	}
+}
+
+// RemoveControl 移除一次控制。
+func (m *CombatManager) RemoveControl(t conf.CCType) {
+	if t <= conf.CCType_None || t >= conf.CCType_Max || m.controls[t] <= 0 {
+		return
+	}
+	m.controls[t]--
+}
+
+// HasControl 判断本实体是否处于某种控制中。
+func (m *CombatManager) HasControl(t conf.CCType) bool {
+	if t <= conf.CCType_None || t >= conf.CCType_Max {
+		return false
+	}
+	return m.controls[t] > 0
+}
+
+// AddMoveSpeedPct 叠加一个移动速度修正（如 BuffEffect.MoveSpeedPct，-0.3 为减速 30%），
+// 效果结束时以相同的值调用 RemoveMoveSpeedPct。
+func (m *CombatManager) AddMoveSpeedPct(pct float64) {
+	m.moveSpeedPct += pct
+}
+
+// RemoveMoveSpeedPct 移除一个移动速度修正。
+func (m *CombatManager) RemoveMoveSpeedPct(pct float64) {
+	m.moveSpeedPct -= pct
+}
+
+// MoveSpeedRate 获取移动速度倍率（1 为原速，减速叠加后最低为 0）。
+func (m *CombatManager) MoveSpeedRate() float64 {
+	return max(1+m.moveSpeedPct, 0)
+}
@@ -0,0 +1,137 @@
+package combat
+
+import (
+	"maps"
+	"math"
+	"slices"
+
+	"server/data/conf"
+	"server/lib/uid"
+	"server/service/world/zone/entity/mod/combat/skill"
+	"server/service/world/zone/izone"
+)
+
+// AssistWindowMs 为助攻判定窗口：死亡前该时间内造成过伤害的其他来源记为助攻。
+const AssistWindowMs = 10000
+
+// DeathEvent 为一次死亡的击杀归属，同时用于 OnDeath（死者）与 OnKill（击杀者）回调。
+type DeathEvent struct {
+	Victim  uid.Uid   // 死亡实体ID
+	Killer  uid.Uid   // 击杀者ID（最后一击的来源，无来源时为 0）
+	Assists []uid.Uid // 助攻者ID（按进入仇恨表的顺序）
+	SkillId int64     // 造成最后一击的技能ID（非技能结算时为 0）
+	NowMs   int64     // 死亡时间
+}
+
+// isDead 判断实体是否已死亡，未挂载战斗模块的实体视为存活。
+func isDead(e izone.IEntity) bool {
+	m := GetCombatManager(e)
+	return m != nil && m.dead
+}
+
+// IsDead 判断本实体是否已死亡。
+func (m *CombatManager) IsDead() bool {
+	return m.dead
+}
+
+// die 处理本实体死亡：
+//  1. 打断所有吟唱/引导中的技能
+//  2. 移除以本实体为目标的效果（只通知登记为效果来源的实体），以及本实体身上的 Buff（带 KeepOnDeath 标签的保留）
+//  3. 根据仇恨表计算击杀者与助攻者，清空仇恨表
+//  4. 触发本实体的 OnDeath 与击杀者的 OnKill 回调
+func (m *CombatManager) die(killer izone.IEntity, skillId int64) {
+	if m.dead {
+		return
+	}
+	m.dead = true
+	m.hp = 0
+
+	m.skillMgr.CancelAll()
+
+	victimId := m.owner.GetId()
+	for _, cm := range m.effectSourceManagers() {
+		cm.effectMgr.RemoveDeadTarget(victimId)
+	}
+	if b := skill.BuffOf(m.owner); b != nil {
+		b.RemoveOnDeath()
+	}
+
+	ev := &DeathEvent{
+		Victim:  victimId,
+		SkillId: skillId,
+		NowMs:   m.nowMs(),
+	}
+	if killer != nil {
+		ev.Killer = killer.GetId()
+	}
+	for _, entry := range m.threat.Entries() {
+		if entry.Source == ev.Killer || entry.Source == victimId || entry.Damage <= 0 {
+			continue
+		}
+		if ev.NowMs-entry.LastDamageMs > AssistWindowMs {
+			continue
+		}
+		ev.Assists = append(ev.Assists, entry.Source)
+	}
+	m.threat.Clear()
+
+	if m.OnDeath != nil {
+		m.OnDeath(ev)
+	}
+	if km := GetCombatManager(killer); km != nil && km != m && km.OnKill != nil {
+		km.OnKill(ev)
+	}
+}
+
+// addEffectSource 修改来源 source 持有的以本实体为目标的持续效果数。
+func (m *CombatManager) addEffectSource(source uid.Uid, delta int) {
+	if n := m.effectSources[source] + delta; n > 0 {
+		m.effectSources[source] = n
+	} else {
+		delete(m.effectSources, source)
+	}
+}
+
+// effectSourceManagers 按实体ID顺序获取持有以本实体为目标的持续效果的战斗模块（已离开区域的实体跳过）。
+func (m *CombatManager) effectSourceManagers() []*CombatManager {
+	ids := slices.Sorted(maps.Keys(m.effectSources))
+	ret := make([]*CombatManager, 0, len(ids))
+	z := m.owner.GetZone()
+	for _, id := range ids {
+		if id == m.owner.GetId() {
+			ret = append(ret, m)
+			continue
+		}
+		if z == nil {
+			continue
+		}
+		if e, ok := z.GetEntity(id); ok {
+			if cm := GetCombatManager(e); cm != nil {
+				ret = append(ret, cm)
+			}
+		}
+	}
+	return ret
+}
+
+// Kill 直接杀死本实体（如脚本/GM 指令），killer 可为空。
+func (m *CombatManager) Kill(killer izone.IEntity) {
+	m.die(killer, 0)
+}
+
+// Revive 复活本实体，按最大值的比例（0~1）恢复生命与法力，生命至少恢复 1 点，其他资源恢复为初始值。
+// 本实体未死亡时返回 false。
+func (m *CombatManager) Revive(hpPct, mpPct float64) bool {
+	if !m.dead {
+		return false
+	}
+	m.dead = false
+	m.hp = min(max(int64(math.Round(float64(m.maxHp)*hpPct)), 1), max(m.maxHp, 1))
+	m.resourceMgr.Reset()
+	m.resourceMgr.Restore(conf.ResourceType_Mp, mpPct)
+
+	if m.OnRevive != nil {
+		m.OnRevive()
+	}
+	return true
+}
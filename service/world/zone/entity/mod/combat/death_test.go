@@ -0,0 +1,191 @@
+package combat
+
+import (
+	"testing"
+
+	"server/data/conf"
+	"server/data/enum"
+	"server/pb"
+	"server/service/world/zone/entity/mod/combat/skill"
+	"server/service/world/zone/izone"
+)
+
+func TestCombatManager_DeathAndKillCredit(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	killer := newTestEntity(z, 0, 0, nil)
+	helper := newTestEntity(z, 1, 0, nil)
+	victim := newTestEntity(z, 2, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 1500})
+	km, vm := GetCombatManager(killer), GetCombatManager(victim)
+
+	var death, kill *DeathEvent
+	vm.OnDeath = func(ev *DeathEvent) { death = ev }
+	km.OnKill = func(ev *DeathEvent) { kill = ev }
+
+	GetCombatManager(helper).ApplyDamage(victim, 600)
+
+	// 真实伤害公式 1009：1000 点伤害，击杀剩余 900 生命的目标
+	ctx := skill.NewSkillContext(killer, &pb.ReqCastSkill{Cid: 1001}, 1)
+	if applied := km.DealDamage(ctx, victim, 1009, 0); applied != 900 {
+		t.Errorf("Expected 900 applied damage, got %d", applied)
+	}
+	if !vm.IsDead() {
+		t.Fatal("Expected victim dead")
+	}
+	if !ctx.GetCurrentResult().KilledAny || ctx.KillCount != 1 {
+		t.Errorf("Expected kill recorded in context, got killedAny=%v count=%d", ctx.GetCurrentResult().KilledAny, ctx.KillCount)
+	}
+	if death == nil || death.Killer != killer.GetId() || death.SkillId != 1001 {
+		t.Fatalf("Expected death event from killer, got %+v", death)
+	}
+	if len(death.Assists) != 1 || death.Assists[0] != helper.GetId() {
+		t.Errorf("Expected helper as assist, got %v", death.Assists)
+	}
+	if kill != death {
+		t.Error("Expected OnKill fired on killer with the same event")
+	}
+	if vm.GetThreatTable().Len() != 0 {
+		t.Error("Expected threat table cleared on death")
+	}
+
+	// 死亡后不再承受伤害/治疗，也不再计入击杀与命中
+	hits := ctx.TotalHits
+	if km.DealDamage(ctx, victim, 1009, 0) != 0 || km.ApplyHeal(victim, 100) != 0 {
+		t.Error("Expected no damage or heal on dead target")
+	}
+	if ctx.KillCount != 1 {
+		t.Errorf("Expected kill count 1, got %d", ctx.KillCount)
+	}
+	if ctx.TotalHits != hits {
+		t.Errorf("Expected no hits recorded on dead target, got %d -> %d", hits, ctx.TotalHits)
+	}
+}
+
+func TestCombatManager_DeathCancelsCast(t *testing.T) {
+	tables := loadTestTables()
+	z := newTestZone(tables)
+	caster := newTestEntity(z, 0, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100, enum.AttrType_MaxMp: 1000})
+	target := newTestEntity(z, 5, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100})
+	setEnemies(caster, target)
+	cm := GetCombatManager(caster)
+	sm := cm.GetSkillManager()
+	req := &pb.ReqCastSkill{LockTarget: int64(target.GetId())}
+
+	// 火球术 2 秒吟唱
+	sm.AddSkill(tables.GetSkill(1001))
+	if !sm.Cast(1001, req).Ok() {
+		t.Fatal("Expected cast to start")
+	}
+	rt, _ := sm.skills.Get(1001)
+	if rt.State != skill.RuntimeState_Casting {
+		t.Fatalf("Expected casting, got %d", rt.State)
+	}
+
+	cm.Kill(nil)
+	if rt.State != skill.RuntimeState_Idle {
+		t.Errorf("Expected cast cancelled on death, got %d", rt.State)
+	}
+
+	z.Advance(5000)
+	if r := sm.Cast(1001, req); r != skill.CastResult_Dead {
+		t.Errorf("Expected Dead, got %s", r)
+	}
+}
+
+func TestCombatManager_DeathRemovesEffects(t *testing.T) {
+	tables := loadTestTables()
+	tables.Buffs[2009].Tags += "|" + conf.BuffTag_KeepOnDeath
+	z := newTestZone(tables)
+	caster := newTestEntity(z, 0, 0, nil)
+	victim := newTestEntity(z, 1, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100})
+	other := newTestEntity(z, 2, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100})
+	em := GetCombatManager(caster).GetEffectManager()
+
+	addEffect := func(targets ...izone.IEntity) *skill.EffectRuntime {
+		eff := skill.CreateEffect(conf.EffectCfg{Type: conf.EffectType_SpawnArea})
+		rt := skill.NewEffectRuntime(eff, skill.NewSkillContext(caster, nil, 1), caster, targets)
+		em.AddEffect(rt)
+		return rt
+	}
+	single := addEffect(victim)
+	shared := addEffect(victim, other)
+	if n := GetCombatManager(victim).effectSources[caster.GetId()]; n != 2 {
+		t.Errorf("Expected caster registered as source of 2 effects on victim, got %d", n)
+	}
+
+	aura := func(buffId int64) {
+		eff := skill.CreateEffect(conf.EffectCfg{Type: conf.EffectType_ApplyAura, RefId: buffId})
+		eff.Begin(skill.NewSkillContext(caster, nil, 1), caster, []izone.IEntity{victim})
+	}
+	aura(2001)
+	aura(2009)
+
+	GetCombatManager(victim).Kill(caster)
+
+	if em.GetEffect(single.Id) != nil || single.State != skill.EffectState_Cancelled {
+		t.Error("Expected effect on dead target cancelled")
+	}
+	if em.GetEffect(shared.Id) == nil || len(shared.Targets) != 1 || shared.Targets[0] != other {
+		t.Error("Expected shared effect kept on remaining target")
+	}
+	if HasBuff(victim, 2001) {
+		t.Error("Expected debuff removed on death")
+	}
+	if !HasBuff(victim, 2009) {
+		t.Error("Expected KeepOnDeath buff kept")
+	}
+	if len(GetCombatManager(victim).effectSources) != 0 {
+		t.Errorf("Expected no effect sources left on dead target, got %v", GetCombatManager(victim).effectSources)
+	}
+
+	em.RemoveEffect(shared.Id)
+	if len(GetCombatManager(other).effectSources) != 0 {
+		t.Errorf("Expected effect source released on removal, got %v", GetCombatManager(other).effectSources)
+	}
+}
+
+func TestCombatManager_TargetingSkipsDead(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newTestEntity(z, 0, 0, nil)
+	alive := newTestEntity(z, 1, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100})
+	dead := newTestEntity(z, 2, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100})
+	GetCombatManager(dead).Kill(nil)
+
+	sm := GetCombatManager(caster).GetSkillManager()
+	ctx := skill.NewSkillContext(caster, &pb.ReqCastSkill{Pos: pb.NewVector(0, 0, 0)}, 1)
+	cfg := &conf.TargetCfg{Relation: conf.TargetRelation_All, Mode: conf.TargetMode_Point, Shape: conf.ShapeType_Circle, Radius: 5, IncludeCaster: true}
+
+	targets := sm.selectTargets(cfg, ctx)
+	if len(targets) != 2 || targets[0] != caster || targets[1] != alive {
+		t.Errorf("Expected caster and alive entity only, got %d targets", len(targets))
+	}
+
+	cfg.IncludeDead = true
+	if targets := sm.selectTargets(cfg, ctx); len(targets) != 3 {
+		t.Errorf("Expected dead entity included, got %d targets", len(targets))
+	}
+}
+
+func TestCombatManager_Revive(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	e := newTestEntity(z, 0, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 1000, enum.AttrType_MaxMp: 400})
+	cm := GetCombatManager(e)
+
+	if cm.Revive(1, 1) {
+		t.Error("Expected revive to fail on alive entity")
+	}
+
+	cm.Kill(nil)
+	revived := false
+	cm.OnRevive = func() { revived = true }
+	if !cm.Revive(0.5, 0.25) || !revived {
+		t.Fatal("Expected revive to succeed")
+	}
+	if cm.IsDead() || cm.GetHp() != 500 || cm.GetMp() != 100 {
+		t.Errorf("Expected alive with hp 500 mp 100, got dead=%v hp=%d mp=%d", cm.IsDead(), cm.GetHp(), cm.GetMp())
+	}
+
+	cm.Kill(nil)
+	if cm.Revive(0, 0); cm.GetHp() != 1 {
+		t.Errorf("Expected at least 1 hp after revive, got %d", cm.GetHp())
+	}
+}
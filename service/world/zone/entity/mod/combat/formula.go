@@ -0,0 +1,106 @@
+package combat
+
+import (
+	"fmt"
+	"math"
+
+	"server/data"
+	"server/data/enum"
+	config "server/data/xls"
+)
+
+// FormulaUnit 为参与公式求值的一方（攻击者/防御者）的属性快照。
+type FormulaUnit struct {
+	Attrs data.IAttrs
+	Hp    int64
+	MaxHp int64
+}
+
+// attr 读取属性值，Attrs 为空时返回 0。
+func (u FormulaUnit) attr(ty enum.AttrType) float64 {
+	if u.Attrs == nil {
+		return 0
+	}
+	return float64(u.Attrs.GetValue(ty))
+}
+
+// FormulaResult 为一次伤害/治疗公式求值的结果与明细，用于调试与战斗日志。
+// 各分项均为未取整的中间值；Value 为四舍五入并经上下限修正后的最终值。
+type FormulaResult struct {
+	FormulaId  int64
+	SkillLevel int64
+
+	Base             float64 // 基础值（含等级成长）
+	FromAP           float64 // 攻击者物理攻击加成
+	FromSP           float64 // 攻击者法术攻击加成
+	FromTargetHp     float64 // 目标当前生命加成
+	FromTargetMissHp float64 // 目标已损失生命加成
+	FromCasterHp     float64 // 攻击者当前生命加成
+
+	Execute      bool    // 是否触发斩杀加成
+	ExecuteBonus float64 // 斩杀额外倍率（触发时有效）
+
+	Raw     float64 // 斩杀后、上下限修正前的数值
+	Clamped bool    // 是否被 MinDamage/MaxDamage 修正
+	Value   int64   // 最终数值
+}
+
+func (r *FormulaResult) String() string {
+	return fmt.Sprintf("formula=%d lv=%d base=%.1f ap=%.1f sp=%.1f thp=%.1f tmiss=%.1f chp=%.1f exec=%v(%.2f) raw=%.1f clamped=%v value=%d",
+		r.FormulaId, r.SkillLevel, r.Base, r.FromAP, r.FromSP, r.FromTargetHp, r.FromTargetMissHp, r.FromCasterHp,
+		r.Execute, r.ExecuteBonus, r.Raw, r.Clamped, r.Value)
+}
+
+// EvalFormula 按配表公式计算伤害/治疗数值，结果只取决于输入，不含随机因素。
+// 计算顺序：
+//  1. 基础值 = BaseDamage + BaseDamagePerLevel × (等级 - 1)
+//  2. 叠加攻击者物攻×AP系数、法攻×SP系数、目标当前生命×系数、目标已损失生命×系数、攻击者当前生命×系数
+//  3. 目标生命比例不高于 ExecuteThreshold 时乘以 (1 + ExecuteBonus)
+//  4. 四舍五入后按 MinDamage/MaxDamage（大于 0 时生效）修正，且不小于 0
+func EvalFormula(f *config.DamageFormula, skillLevel int64, attacker, defender FormulaUnit) *FormulaResult {
+	if f == nil {
+		return nil
+	}
+	if skillLevel < 1 {
+		skillLevel = 1
+	}
+
+	r := &FormulaResult{
+		FormulaId:  int64(f.ID),
+		SkillLevel: skillLevel,
+	}
+
+	r.Base = float64(f.BaseDamage) + float64(f.BaseDamagePerLevel)*float64(skillLevel-1)
+	r.FromAP = attacker.attr(enum.AttrType_PhyAttack) * f.APCoefficient
+	r.FromSP = attacker.attr(enum.AttrType_MagicAttack) * f.SPCoefficient
+	r.FromTargetHp = float64(defender.Hp) * f.TargetHPCoefficient
+	if defender.MaxHp > defender.Hp {
+		r.FromTargetMissHp = float64(defender.MaxHp-defender.Hp) * f.TargetMissingHPCoefficient
+	}
+	r.FromCasterHp = float64(attacker.Hp) * f.CasterHPCoefficient
+
+	r.Raw = r.Base + r.FromAP + r.FromSP + r.FromTargetHp + r.FromTargetMissHp + r.FromCasterHp
+
+	if f.ExecuteThreshold > 0 && defender.MaxHp > 0 &&
+		float64(defender.Hp) <= float64(defender.MaxHp)*f.ExecuteThreshold {
+		r.Execute = true
+		r.ExecuteBonus = f.ExecuteBonus
+		r.Raw *= 1 + f.ExecuteBonus
+	}
+
+	value := int64(math.Round(r.Raw))
+	if f.MinDamage > 0 && value < int64(f.MinDamage) {
+		value = int64(f.MinDamage)
+		r.Clamped = true
+	}
+	if f.MaxDamage > 0 && value > int64(f.MaxDamage) {
+		value = int64(f.MaxDamage)
+		r.Clamped = true
+	}
+	if value < 0 {
+		value = 0
+	}
+	r.Value = value
+
+	return r
+}
@@ -0,0 +1,93 @@
+package combat
+
+import (
+	"testing"
+
+	"server/data"
+	"server/data/enum"
+	config "server/data/xls"
+	"server/service/world/zone/entity/mod/combat/skill"
+)
+
+func TestEvalFormula(t *testing.T) {
+	attrs := data.Attrs{
+		{Type: enum.AttrType_PhyAttack, Val: 200},
+		{Type: enum.AttrType_MagicAttack, Val: 500},
+	}
+	attacker := FormulaUnit{Attrs: &attrs, Hp: 1000, MaxHp: 1000}
+	defender := FormulaUnit{Hp: 300, MaxHp: 1000}
+
+	cases := []struct {
+		name    string
+		f       config.DamageFormula
+		level   int64
+		value   int64
+		execute bool
+		clamped bool
+	}{
+		// 500 + 50*2 + 500*0.8 = 1000
+		{"level and sp", config.DamageFormula{ID: 1, BaseDamage: 500, BaseDamagePerLevel: 50, SPCoefficient: 0.8}, 3, 1000, false, false},
+		// 等级 0 视为 1 级：100 + 200*1 = 300
+		{"level floor", config.DamageFormula{ID: 2, BaseDamage: 100, BaseDamagePerLevel: 10, APCoefficient: 1}, 0, 300, false, false},
+		// 200 + 700*0.5 = 550，生命 30% > 20% 不触发斩杀
+		{"missing hp", config.DamageFormula{ID: 3, APCoefficient: 1, TargetMissingHPCoefficient: 0.5, ExecuteThreshold: 0.2, ExecuteBonus: 1}, 1, 550, false, false},
+		// 阈值 30% 触发斩杀：(200 + 350) * 2 = 1100
+		{"execute", config.DamageFormula{ID: 4, APCoefficient: 1, TargetMissingHPCoefficient: 0.5, ExecuteThreshold: 0.3, ExecuteBonus: 1}, 1, 1100, true, false},
+		// 300*0.1 + 1000*0.05 = 80
+		{"hp coefficients", config.DamageFormula{ID: 5, TargetHPCoefficient: 0.1, CasterHPCoefficient: 0.05}, 1, 80, false, false},
+		{"max clamp", config.DamageFormula{ID: 6, APCoefficient: 1, MinDamage: 50, MaxDamage: 150}, 1, 150, false, true},
+		{"min clamp", config.DamageFormula{ID: 7, BaseDamage: 10, MinDamage: 50, MaxDamage: 150}, 1, 50, false, true},
+		// 0.5 四舍五入
+		{"round", config.DamageFormula{ID: 8, SPCoefficient: 0.001}, 1, 1, false, false},
+	}
+
+	for _, c := range cases {
+		r := EvalFormula(&c.f, c.level, attacker, defender)
+		if r.Value != c.value {
+			t.Errorf("%s: expected %d, got %d (%s)", c.name, c.value, r.Value, r)
+		}
+		if r.Execute != c.execute {
+			t.Errorf("%s: expected execute=%v, got %v", c.name, c.execute, r.Execute)
+		}
+		if r.Clamped != c.clamped {
+			t.Errorf("%s: expected clamped=%v, got %v", c.name, c.clamped, r.Clamped)
+		}
+	}
+
+	if EvalFormula(nil, 1, attacker, defender) != nil {
+		t.Error("Expected nil result for nil formula")
+	}
+}
+
+func TestCombatManager_CalculateDamage(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newTestEntity(z, 0, 0, map[enum.AttrType]int64{enum.AttrType_MagicAttack: 1000, enum.AttrType_MaxHp: 5000})
+	target := newTestEntity(z, 5, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 5000})
+
+	cm := GetCombatManager(caster)
+	if cm == nil {
+		t.Fatal("Expected combat manager on test entity")
+	}
+	cm.SetRand(fixedRand(RatePrecision - 1))
+
+	// 火球伤害公式：500 + 1000*0.8 = 1300
+	r := cm.CalculateDamage(nil, caster, target, 1001)
+	if r == nil || r.Damage != 1300 {
+		t.Fatalf("Expected 1300 damage, got %v", r)
+	}
+
+	// 2 级：500 + 50*(2-1) + 1000*0.8 = 1350
+	ctx := skill.NewSkillContext(caster, nil, 2)
+	if r := cm.CalculateDamage(ctx, caster, target, 1001); r == nil || r.Damage != 1350 {
+		t.Errorf("Expected 1350 damage at level 2, got %v", r)
+	}
+
+	if cm.CalculateDamage(nil, caster, target, 424242) != nil {
+		t.Error("Expected nil for unknown formula")
+	}
+
+	// 治疗术公式：800 + 1000*1.2 = 2000
+	if heal := cm.CalculateHeal(nil, caster, target, 2001); heal != 2000 {
+		t.Errorf("Expected 2000 heal, got %d", heal)
+	}
+}
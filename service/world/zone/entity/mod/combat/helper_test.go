@@ -0,0 +1,50 @@
+package combat
+
+import (
+	"server/data"
+	"server/data/conf"
+	"server/data/enum"
+	"server/pb"
+	"server/service/world/zone/entity/mod/buff"
+	"server/service/world/zone/internal/zonetest"
+	"server/service/world/zone/izone"
+)
+
+type (
+	testZone   = zonetest.Zone
+	testEntity = zonetest.Entity
+	fixedRand  = zonetest.FixedRand
+)
+
+// testModules 为测试实体挂载的模块：战斗与 Buff。
+var testModules = zonetest.Modules{
+	izone.ModuleType_Combat: func() izone.IModule { return &CombatManager{} },
+	izone.ModuleType_Buff:   func() izone.IModule { return &buff.BuffManager{} },
+}
+
+func newTestZone(tables *conf.Tables) *testZone {
+	return zonetest.NewZone(tables)
+}
+
+// newEntityAt 创建位于 (x, y) 的测试实体，需调用 Init 加入区域。
+func newEntityAt(x, y float64) *testEntity {
+	return zonetest.NewEntity(pb.NewVector(x, y, 0), testModules)
+}
+
+// newTestEntity 在区域内创建一个带属性的测试实体。
+func newTestEntity(z izone.IZone, x, y float64, attrs map[enum.AttrType]int64) *testEntity {
+	e := newEntityAt(x, y)
+	e.Init(z, data.EntityInitData{Attrs: zonetest.NewAttrs(attrs)})
+	return e
+}
+
+// loadTestTables 加载仓库中的 all.json。
+func loadTestTables() *conf.Tables {
+	return zonetest.LoadTables()
+}
+
+// setEnemies 将两个实体设置为不同阵营（互为敌方）。
+func setEnemies(a, b izone.IEntity) {
+	GetCombatManager(a).SetCamp(1)
+	GetCombatManager(b).SetCamp(2)
+}
@@ -0,0 +1,164 @@
+package combat
+
+import (
+	"math/rand/v2"
+
+	"server/data/conf"
+	"server/data/enum"
+	config "server/data/xls"
+)
+
+// RatePrecision 为概率类属性的精度（万分比）。
+const RatePrecision = 10000
+
+// HitOutcome 为攻击判定结果。
+type HitOutcome int32
+
+const (
+	HitOutcome_Invalid HitOutcome = 0
+	HitOutcome_Normal  HitOutcome = 1 // 普通命中
+	HitOutcome_Crit    HitOutcome = 2 // 暴击
+	HitOutcome_Block   HitOutcome = 3 // 格挡（命中但减伤）
+	HitOutcome_Parry   HitOutcome = 4 // 招架
+	HitOutcome_Dodge   HitOutcome = 5 // 闪避
+	HitOutcome_Miss    HitOutcome = 6 // 未命中
+)
+
+// IsHit 判断攻击是否命中（命中后才会造成伤害）。
+func (o HitOutcome) IsHit() bool {
+	return o == HitOutcome_Normal || o == HitOutcome_Crit || o == HitOutcome_Block
+}
+
+// Rand 为战斗随机数源，测试时可注入固定种子的实现（如 rand.New(rand.NewPCG(1, 2))）。
+type Rand interface {
+	IntN(n int) int
+}
+
+// globalRand 使用 math/rand/v2 的全局随机源。
+type globalRand struct{}
+
+func (globalRand) IntN(n int) int {
+	return rand.IntN(n)
+}
+
+// AttackTable 为攻击判定表的基础参数（概率均为万分比）。
+// 命中/闪避/暴击由双方属性决定，招架/格挡暂无对应属性，使用此处的基础值。
+type AttackTable struct {
+	BaseMissRate          int64   // 基础未命中率，可被攻击者命中率抵消
+	ParryRate             int64   // 招架率（公式 CanParry 时生效）
+	BlockRate             int64   // 格挡率（公式 CanBlock 时生效）
+	BlockReduction        int64   // 格挡减伤比例
+	DefaultCritMultiplier float64 // 公式未配置暴击倍率时使用的倍率
+}
+
+// DefaultAttackTable 为默认攻击判定参数。
+var DefaultAttackTable = AttackTable{
+	BaseMissRate:          500,
+	ParryRate:             500,
+	BlockRate:             500,
+	BlockReduction:        3000,
+	DefaultCritMultiplier: 2,
+}
+
+// HitResult 为一次攻击判定的结果。
+type HitResult struct {
+	Outcome        HitOutcome
+	Roll           int64   // 本次掷骰值 [0, RatePrecision)
+	CritMultiplier float64 // 暴击倍率（仅 Crit 有效）
+	BlockReduction int64   // 格挡减伤比例（仅 Block 有效）
+}
+
+// hitAttrs 为不同伤害类型对应的命中/闪避/暴击/暴伤属性。
+type hitAttrs struct {
+	hit, dodge, crit, critDamage enum.AttrType
+}
+
+var (
+	physicalHitAttrs = hitAttrs{enum.AttrType_PhysicalHitRate, enum.AttrType_PhysicalDodgeRate, enum.AttrType_PhyCritRate, enum.AttrType_PhysicalCritDamage}
+	magicHitAttrs    = hitAttrs{enum.AttrType_MagicHitRate, enum.AttrType_MagicDodgeRate, enum.AttrType_MagicCritRate, enum.AttrType_MagicCritDamage}
+)
+
+// RollAttackTable 按单次掷骰的攻击表判定攻击结果。
+// 判定顺序（区间依次排列，总和超过 100% 时靠后的结果被挤出）：
+// 未命中 → 闪避 → 招架 → 格挡 → 暴击 → 普通命中。
+// 真实伤害不会未命中；闪避/招架/格挡/暴击分别受公式 CanDodge/CanParry/CanBlock/CanCrit 控制。
+func RollAttackTable(rng Rand, table *AttackTable, f *config.DamageFormula, attacker, defender FormulaUnit) HitResult {
+	if rng == nil {
+		rng = globalRand{}
+	}
+	if table == nil {
+		table = &DefaultAttackTable
+	}
+
+	damageType := DamageTypeOf(f)
+	attrs := physicalHitAttrs
+	if damageType == conf.DamageType_Magic {
+		attrs = magicHitAttrs
+	}
+
+	var miss, dodge, parry, block, crit int64
+	if damageType != conf.DamageType_True {
+		miss = max(table.BaseMissRate-int64(attacker.attr(attrs.hit)), 0)
+	}
+	if f.CanDodge {
+		dodge = max(int64(defender.attr(attrs.dodge)), 0)
+	}
+	if f.CanParry {
+		parry = max(table.ParryRate, 0)
+	}
+	if f.CanBlock {
+		block = max(table.BlockRate, 0)
+	}
+	if f.CanCrit {
+		crit = max(int64(attacker.attr(attrs.crit)), 0)
+	}
+
+	r := HitResult{Roll: int64(rng.IntN(RatePrecision))}
+	bound := int64(0)
+	for _, seg := range []struct {
+		outcome HitOutcome
+		chance  int64
+	}{
+		{HitOutcome_Miss, miss},
+		{HitOutcome_Dodge, dodge},
+		{HitOutcome_Parry, parry},
+		{HitOutcome_Block, block},
+		{HitOutcome_Crit, crit},
+	} {
+		bound += seg.chance
+		if r.Roll < bound {
+			r.Outcome = seg.outcome
+			break
+		}
+	}
+	if r.Outcome == HitOutcome_Invalid {
+		r.Outcome = HitOutcome_Normal
+	}
+
+	switch r.Outcome {
+	case HitOutcome_Crit:
+		mul := f.CritMultiplier
+		if mul <= 0 {
+			mul = table.DefaultCritMultiplier
+		}
+		r.CritMultiplier = mul + attacker.attr(attrs.critDamage)/RatePrecision
+	case HitOutcome_Block:
+		r.BlockReduction = table.BlockReduction
+	}
+
+	return r
+}
+
+// Apply 将判定结果作用于伤害值：未命中类结果为 0，暴击乘以倍率，格挡按比例减伤。
+func (r HitResult) Apply(damage float64) float64 {
+	switch r.Outcome {
+	case HitOutcome_Crit:
+		return damage * r.CritMultiplier
+	case HitOutcome_Block:
+		return damage * float64(RatePrecision-r.BlockReduction) / RatePrecision
+	case HitOutcome_Normal:
+		return damage
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,121 @@
+package combat
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"server/data"
+	"server/data/conf"
+	"server/data/enum"
+	config "server/data/xls"
+	"server/service/world/zone/entity/mod/combat/skill"
+)
+
+func TestRollAttackTable(t *testing.T) {
+	attackerAttrs := data.Attrs{
+		{Type: enum.AttrType_PhysicalHitRate, Val: 200},
+		{Type: enum.AttrType_PhyCritRate, Val: 2000},
+		{Type: enum.AttrType_PhysicalCritDamage, Val: 5000},
+	}
+	defenderAttrs := data.Attrs{
+		{Type: enum.AttrType_PhysicalDodgeRate, Val: 1000},
+	}
+	attacker := FormulaUnit{Attrs: &attackerAttrs}
+	defender := FormulaUnit{Attrs: &defenderAttrs}
+	table := &AttackTable{BaseMissRate: 500, ParryRate: 500, BlockRate: 500, BlockReduction: 3000, DefaultCritMultiplier: 2}
+
+	melee := &config.DamageFormula{DamageType: int(conf.DamageType_Physical), CanCrit: true, CritMultiplier: 2, CanDodge: true, CanParry: true, CanBlock: true}
+
+	// 区间：miss [0,300) dodge [300,1300) parry [1300,1800) block [1800,2300) crit [2300,4300) normal [4300,10000)
+	cases := []struct {
+		roll    int
+		outcome HitOutcome
+	}{
+		{0, HitOutcome_Miss},
+		{299, HitOutcome_Miss},
+		{300, HitOutcome_Dodge},
+		{1299, HitOutcome_Dodge},
+		{1300, HitOutcome_Parry},
+		{1800, HitOutcome_Block},
+		{2300, HitOutcome_Crit},
+		{4299, HitOutcome_Crit},
+		{4300, HitOutcome_Normal},
+		{9999, HitOutcome_Normal},
+	}
+	for _, c := range cases {
+		r := RollAttackTable(fixedRand(c.roll), table, melee, attacker, defender)
+		if r.Outcome != c.outcome {
+			t.Errorf("roll %d: expected outcome %d, got %d", c.roll, c.outcome, r.Outcome)
+		}
+	}
+
+	crit := RollAttackTable(fixedRand(2300), table, melee, attacker, defender)
+	if crit.CritMultiplier != 2.5 || crit.Apply(100) != 250 {
+		t.Errorf("Expected crit multiplier 2.5, got %v", crit.CritMultiplier)
+	}
+	block := RollAttackTable(fixedRand(1800), table, melee, attacker, defender)
+	if block.Apply(100) != 70 {
+		t.Errorf("Expected blocked damage 70, got %v", block.Apply(100))
+	}
+	if !block.Outcome.IsHit() || HitOutcome_Parry.IsHit() {
+		t.Error("Expected block to count as hit and parry not")
+	}
+
+	// 真实伤害且无任何判定标志：永远普通命中
+	trueDmg := &config.DamageFormula{DamageType: int(conf.DamageType_True)}
+	if r := RollAttackTable(fixedRand(0), table, trueDmg, attacker, defender); r.Outcome != HitOutcome_Normal {
+		t.Errorf("Expected true damage to always hit, got %d", r.Outcome)
+	}
+
+	// 法术伤害使用法术属性：物理闪避不生效
+	spell := &config.DamageFormula{DamageType: int(conf.DamageType_Magic), CanDodge: true}
+	if r := RollAttackTable(fixedRand(600), table, spell, attacker, defender); r.Outcome != HitOutcome_Normal {
+		t.Errorf("Expected spell to ignore physical dodge, got %d", r.Outcome)
+	}
+}
+
+func TestRollAttackTable_Seeded(t *testing.T) {
+	f := &config.DamageFormula{DamageType: int(conf.DamageType_Physical), CanCrit: true, CanDodge: true, CanParry: true, CanBlock: true}
+	roll := func() []HitOutcome {
+		rng := rand.New(rand.NewPCG(1, 2))
+		ret := make([]HitOutcome, 0, 32)
+		for i := 0; i < 32; i++ {
+			ret = append(ret, RollAttackTable(rng, nil, f, FormulaUnit{}, FormulaUnit{}).Outcome)
+		}
+		return ret
+	}
+
+	a, b := roll(), roll()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Expected identical outcomes with the same seed, diverged at %d", i)
+		}
+	}
+}
+
+func TestCombatManager_CalculateDamage_RecordsHit(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newTestEntity(z, 0, 0, map[enum.AttrType]int64{enum.AttrType_MagicAttack: 1000, enum.AttrType_MagicCritRate: RatePrecision})
+	target := newTestEntity(z, 5, 0, nil)
+	cm := GetCombatManager(caster)
+	cm.SetRand(fixedRand(RatePrecision - 1))
+
+	ctx := skill.NewSkillContext(caster, nil, 1)
+	r := cm.CalculateDamage(ctx, caster, target, 1001)
+	if r.Hit.Outcome != HitOutcome_Crit || r.Damage != 2600 {
+		t.Errorf("Expected crit for 2600, got %s", r)
+	}
+	res := ctx.GetCurrentResult()
+	if !res.IsCrit || res.HitCount != 1 || ctx.TotalHits != 1 {
+		t.Errorf("Expected crit hit recorded, got IsCrit=%v HitCount=%d TotalHits=%d", res.IsCrit, res.HitCount, ctx.TotalHits)
+	}
+
+	cm.SetRand(fixedRand(0))
+	ctx = skill.NewSkillContext(caster, nil, 1)
+	if r := cm.CalculateDamage(ctx, caster, target, 1001); r.Hit.Outcome != HitOutcome_Miss || r.Damage != 0 {
+		t.Errorf("Expected miss for 0, got %s", r)
+	}
+	if ctx.TotalHits != 0 {
+		t.Errorf("Expected no hits recorded on miss, got %d", ctx.TotalHits)
+	}
+}
@@ -1,41 +1,96 @@
 package combat
 
 import (
+	"fmt"
+	"math"
+
 	"server/data"
 	"server/data/conf"
 	"server/data/enum"
+	config "server/data/xls"
+	"server/lib/uid"
 	"server/service/world/zone/entity/mod/combat/skill"
 	"server/service/world/zone/izone"
 )
 
-var _ izone.IModule = (*CombatManager)(nil)
+var (
+	_ izone.IModule   = (*CombatManager)(nil)
+	_ skill.ICombat   = (*CombatManager)(nil)
+	_ skill.IResource = (*CombatManager)(nil)
+	_ skill.IStatus   = (*CombatManager)(nil)
+)
 
 type CombatManager struct {
 	owner izone.IEntity
-	attrs *data.Attrs
+	attrs *data.AttrSet
 
-	skillMgr  *SkillManager
-	effectMgr *EffectManager
+	skillMgr    *SkillManager
+	effectMgr   *EffectManager
+	resourceMgr *ResourceManager
 
 	hp    int64
 	maxHp int64
+
+	faction data.Faction // 阵营数据（敌友关系）
+
+	dead         bool                   // 是否已死亡
+	controls     [conf.CCType_Max]int32 // 各类控制的叠加次数
+	moveSpeedPct float64                // 移动速度修正之和（如减速 -0.3）
+
+	threat *ThreatTable // 仇恨表（记录对本实体造成伤害/治疗的来源）
+
+	effectSources map[uid.Uid]int // 持有以本实体为目标的持续效果的实体及其效果数，死亡时只通知这些实体
+
+	// OnCombatLog 本实体承受伤害/治疗后的回调，用于战斗日志
+	OnCombatLog func(log *CombatLog)
+	// OnDeath 本实体死亡时的回调
+	OnDeath func(ev *DeathEvent)
+	// OnKill 本实体击杀其他实体时的回调
+	OnKill func(ev *DeathEvent)
+	// OnRevive 本实体复活时的回调
+	OnRevive func()
+	// OnAttrChange 本实体属性最终值变化后的回调（生命上限等已同步）
+	OnAttrChange func(ty enum.AttrType, old, new int64)
+
+	rng             Rand             // 战斗随机数源，为 nil 时使用全局随机源
+	attackTable     *AttackTable     // 攻击判定参数，为 nil 时使用 DefaultAttackTable
+	mitigationTable *MitigationTable // 承受伤害时的减免参数，为 nil 时使用 DefaultMitigationTable
 }
 
 func (m *CombatManager) Init(owner izone.IEntity, initData data.EntityInitData) {
 	m.owner = owner
-	m.attrs = initData.Attrs
+	m.attrs = data.NewAttrSet(initData.Attrs)
+	if z := owner.GetZone(); z != nil && z.GetTables() != nil {
+		m.attrs.SetDerives(z.GetTables().GetClassDerives(initData.ClassId))
+	}
+	m.faction = initData.Faction
 	m.skillMgr = newSkillManager(m)
 	m.effectMgr = newEffectManager(m)
+	m.resourceMgr = newResourceManager(m, DefaultResourceRules)
+	m.threat = newThreatTable()
+	m.effectSources = make(map[uid.Uid]int)
 
-	if m.attrs != nil {
-		m.maxHp = m.attrs.GetValue(enum.AttrType_MaxHp)
-		m.hp = m.maxHp
+	m.maxHp = m.attrs.GetValue(enum.AttrType_MaxHp)
+	m.hp = m.maxHp
+	m.attrs.OnChange = m.onAttrChange
+}
+
+// onAttrChange 属性变化时同步生命上限与资源上限，当前值按原比例缩放。
+func (m *CombatManager) onAttrChange(ty enum.AttrType, old, new int64) {
+	if ty == enum.AttrType_MaxHp {
+		m.maxHp = new
+		m.hp = scaleCur(m.hp, old, new)
+	}
+	m.resourceMgr.onAttrChange(ty)
+	if m.OnAttrChange != nil {
+		m.OnAttrChange(ty, old, new)
 	}
 }
 
 func (m *CombatManager) Update(duration int64) {
 	m.skillMgr.Update(duration)
 	m.effectMgr.Update(duration)
+	m.resourceMgr.Update(duration)
 }
 
 func (m *CombatManager) ExecuteEffect(eff conf.EffectCfg, ctx *skill.SkillContext, caster izone.IEntity, targets []izone.IEntity) {
@@ -94,8 +149,8 @@ func (m *CombatManager) isInstantEffect(effectType conf.EffectType) bool {
 		return true
 	case conf.EffectType_Summon: // 召唤（瞬时创建）
 		return true
-	case conf.EffectType_ApplyAura: // Buff/Debuff（持续）
-		return false
+	case conf.EffectType_ApplyAura: // 施加 Buff（Buff 由目标的 Buff 模块管理）
+		return true
 	case conf.EffectType_SpawnArea: // 区域效果（持续）
 		return false
 	default:
@@ -103,50 +158,330 @@ func (m *CombatManager) isInstantEffect(effectType conf.EffectType) bool {
 	}
 }
 
-func (m *CombatManager) CalculateDamage(attacker izone.IEntity, target izone.IEntity, baseDamage int64) int64 {
-	if attacker == nil || target == nil {
+// DamageResult 为一次伤害结算的结果与明细。
+type DamageResult struct {
+	Formula    *FormulaResult    // 公式求值明细
+	Hit        HitResult         // 攻击判定结果
+	Mitigation *MitigationResult // 减免明细
+
+	School   conf.DamageSchool // 伤害系别（决定可被哪些护盾吸收）
+	Damage   int64             // 最终伤害
+	Absorbed int64             // 被护盾吸收的伤害（结算到目标后填写）
+	Applied  int64             // 实际扣除的生命值（结算到目标后填写）
+}
+
+func (r *DamageResult) String() string {
+	return fmt.Sprintf("damage=%d absorbed=%d applied=%d outcome=%d roll=%d [%s] [%s]", r.Damage, r.Absorbed, r.Applied, r.Hit.Outcome, r.Hit.Roll, r.Formula, r.Mitigation)
+}
+
+// GetCombatManager 获取实体的战斗模块，实体未挂载战斗模块时返回 nil。
+func GetCombatManager(e izone.IEntity) *CombatManager {
+	if e == nil {
+		return nil
+	}
+	m, _ := e.GetModule(izone.ModuleType_Combat).(*CombatManager)
+	return m
+}
+
+// formulaUnit 获取实体参与公式计算的属性快照。
+func formulaUnit(e izone.IEntity) FormulaUnit {
+	cm := GetCombatManager(e)
+	if cm == nil {
+		return FormulaUnit{}
+	}
+	return FormulaUnit{Attrs: cm.attrs, Hp: cm.hp, MaxHp: cm.maxHp}
+}
+
+// getFormula 从区域当前配置中查找伤害/治疗公式。
+func (m *CombatManager) getFormula(formulaId int64) *config.DamageFormula {
+	z := m.owner.GetZone()
+	if z == nil {
+		return nil
+	}
+	tables := z.GetTables()
+	if tables == nil {
+		return nil
+	}
+	return tables.GetDamageFormula(formulaId)
+}
+
+// skillLevel 读取技能等级，ctx 为空时视为 1 级。
+func skillLevel(ctx *skill.SkillContext) int64 {
+	if ctx == nil {
+		return 1
+	}
+	return ctx.SkillLevel
+}
+
+// skillIdOf 读取施法请求中的技能ID，非技能结算时为 0。
+func skillIdOf(ctx *skill.SkillContext) int64 {
+	if ctx == nil || ctx.Req == nil {
 		return 0
 	}
+	return ctx.Req.Cid
+}
+
+// CalculateDamage 按伤害公式计算 attacker 对 target 的伤害，公式不存在时返回 nil。
+// 流程：公式求值 → 攻击判定（未命中/闪避/招架/格挡/暴击）→ 按伤害类型减免（真实伤害跳过）→ 四舍五入。
+// ctx 不为空且目标存活时，判定结果会记录到当前 EffectResult（IsCrit/HitCount）与 SkillContext.TotalHits。
+func (m *CombatManager) CalculateDamage(ctx *skill.SkillContext, attacker izone.IEntity, target izone.IEntity, formulaId int64) *DamageResult {
+	if attacker == nil || target == nil {
+		return nil
+	}
+
+	formula := m.getFormula(formulaId)
+	if formula == nil {
+		return nil
+	}
+
+	attackerUnit, targetUnit := formulaUnit(attacker), formulaUnit(target)
+	fr := EvalFormula(formula, skillLevel(ctx), attackerUnit, targetUnit)
+	hit := RollAttackTable(m.rng, m.attackTable, formula, attackerUnit, targetUnit)
+	mit := Mitigate(mitigationTableOf(target), formula, attackerUnit, targetUnit, hit.Apply(float64(fr.Value)))
+
+	ret := &DamageResult{
+		Formula:    fr,
+		Hit:        hit,
+		Mitigation: mit,
+		School:     conf.DamageSchool(formula.School),
+		Damage:     int64(math.Round(mit.After)),
+	}
 
-	damage := baseDamage
+	if ctx != nil && !isDead(target) {
+		res := ctx.GetCurrentResult()
+		if hit.Outcome.IsHit() {
+			res.HitCount++
+			ctx.TotalHits++
+		}
+		if hit.Outcome == HitOutcome_Crit {
+			res.IsCrit = true
+		}
+	}
 
-	return damage
+	return ret
 }
 
-func (m *CombatManager) CalculateHeal(healer izone.IEntity, target izone.IEntity, baseHeal int64) int64 {
+// CalculateHeal 按治疗公式（与伤害公式同表）计算 healer 对 target 的治疗量，公式不存在时返回 0。
+func (m *CombatManager) CalculateHeal(ctx *skill.SkillContext, healer izone.IEntity, target izone.IEntity, formulaId int64) int64 {
 	if healer == nil || target == nil {
 		return 0
 	}
 
-	heal := baseHeal
+	formula := m.getFormula(formulaId)
+	if formula == nil {
+		return 0
+	}
 
-	return heal
+	return EvalFormula(formula, skillLevel(ctx), formulaUnit(healer), formulaUnit(target)).Value
 }
 
-func (m *CombatManager) ApplyDamage(target izone.IEntity, damage int64) {
-	if target == nil || damage <= 0 {
-		return
+// DealDamage 按公式计算 owner 对 target 的伤害并结算到 target，threat 为命中时的额外仇恨。
+// 返回实际扣除的生命值（不含护盾吸收部分），结算明细见 DealDamageResult。
+func (m *CombatManager) DealDamage(ctx *skill.SkillContext, target izone.IEntity, formulaId int64, threat int64) int64 {
+	if r := m.DealDamageResult(ctx, target, formulaId, threat); r != nil {
+		return r.Applied
+	}
+	return 0
+}
+
+// DealDamageResult 同 DealDamage，返回包含吸收量与实际扣除生命值的伤害结果；公式不存在时返回 nil。
+// ctx 不为空时命中结果与吸收量会累计到当前 EffectResult 与 SkillContext。
+func (m *CombatManager) DealDamageResult(ctx *skill.SkillContext, target izone.IEntity, formulaId int64, threat int64) *DamageResult {
+	r := m.CalculateDamage(ctx, m.owner, target, formulaId)
+	if r == nil || !r.Hit.Outcome.IsHit() {
+		return r
+	}
+
+	tm := GetCombatManager(target)
+	if tm == nil || tm.dead {
+		return r
 	}
+	if threat != 0 {
+		tm.threat.AddThreat(m.owner.GetId(), threat, tm.nowMs())
+	}
+	r.Applied, r.Absorbed = tm.takeDamage(m.owner, r.Damage, r.School, skillIdOf(ctx), r.Hit.Outcome)
 
-	if target.GetId() == m.owner.GetId() {
-		m.hp -= damage
-		if m.hp < 0 {
-			m.hp = 0
+	if ctx != nil {
+		res := ctx.GetCurrentResult()
+		res.Damage += r.Applied
+		res.Absorbed += r.Absorbed
+		res.Targets = append(res.Targets, target)
+		ctx.TotalDamage += r.Applied
+		if tm.dead {
+			res.KilledAny = true
+			ctx.KillCount++
 		}
 	}
+	return r
 }
 
-func (m *CombatManager) ApplyHeal(target izone.IEntity, heal int64) {
-	if target == nil || heal <= 0 {
-		return
+// DealHeal 按公式计算 owner 对 target 的治疗量并结算到 target，返回实际恢复的生命值。
+// ctx 不为空时结果会累计到当前 EffectResult 与 SkillContext。
+func (m *CombatManager) DealHeal(ctx *skill.SkillContext, target izone.IEntity, formulaId int64) int64 {
+	heal := m.CalculateHeal(ctx, m.owner, target, formulaId)
+	tm := GetCombatManager(target)
+	if heal <= 0 || tm == nil || tm.dead {
+		return 0
 	}
 
-	if target.GetId() == m.owner.GetId() {
-		m.hp += heal
-		if m.hp > m.maxHp {
-			m.hp = m.maxHp
-		}
+	applied := tm.takeHeal(m.owner, heal, skillIdOf(ctx))
+
+	if ctx != nil {
+		res := ctx.GetCurrentResult()
+		res.Heal += applied
+		res.Targets = append(res.Targets, target)
+		ctx.TotalHeal += applied
+	}
+	return applied
+}
+
+// ApplyDamage 以 owner 为来源对 target 直接造成伤害（不经过公式与判定），返回实际扣除的生命值。
+// 伤害没有系别，只会被不限系别的护盾吸收。
+func (m *CombatManager) ApplyDamage(target izone.IEntity, damage int64) int64 {
+	tm := GetCombatManager(target)
+	if tm == nil || damage <= 0 {
+		return 0
 	}
+	applied, _ := tm.takeDamage(m.owner, damage, conf.DamageSchool_None, 0, HitOutcome_Normal)
+	return applied
+}
+
+// ApplyHeal 以 owner 为来源对 target 直接进行治疗，返回实际恢复的生命值。
+func (m *CombatManager) ApplyHeal(target izone.IEntity, heal int64) int64 {
+	tm := GetCombatManager(target)
+	if tm == nil || heal <= 0 {
+		return 0
+	}
+	return tm.takeHeal(m.owner, heal, 0)
+}
+
+// takeDamage 本实体承受来自 source 的伤害：先由护盾吸收，剩余部分扣除生命、记录仇恨并产生战斗日志，生命归零时死亡。
+// 返回实际扣除的生命值与被吸收的伤害；已死亡的实体不再承受伤害。
+func (m *CombatManager) takeDamage(source izone.IEntity, damage int64, school conf.DamageSchool, skillId int64, outcome HitOutcome) (applied, absorbed int64) {
+	if damage <= 0 || m.dead {
+		return 0, 0
+	}
+
+	if b := skill.BuffOf(m.owner); b != nil {
+		absorbed = min(max(b.AbsorbDamage(damage, school), 0), damage)
+	}
+	applied = min(damage-absorbed, m.hp)
+	m.hp -= applied
+
+	log := &CombatLog{
+		Type:     CombatLogType_Damage,
+		Target:   m.owner.GetId(),
+		SkillId:  skillId,
+		Outcome:  outcome,
+		Value:    damage,
+		Absorbed: absorbed,
+		Applied:  applied,
+		Overflow: damage - absorbed - applied,
+		NowMs:    m.nowMs(),
+	}
+	if source != nil {
+		log.Source = source.GetId()
+		m.threat.AddDamage(log.Source, applied, log.NowMs)
+	}
+	m.emitCombatLog(log)
+
+	if m.hp <= 0 {
+		m.die(source, skillId)
+	}
+
+	return applied, absorbed
+}
+
+// takeHeal 本实体接受来自 source 的治疗：恢复生命并产生战斗日志。已死亡的实体无法被治疗。
+func (m *CombatManager) takeHeal(source izone.IEntity, heal int64, skillId int64) int64 {
+	if heal <= 0 || m.dead {
+		return 0
+	}
+
+	applied := min(heal, max(m.maxHp-m.hp, 0))
+	m.hp += applied
+
+	log := &CombatLog{
+		Type:     CombatLogType_Heal,
+		Target:   m.owner.GetId(),
+		SkillId:  skillId,
+		Value:    heal,
+		Applied:  applied,
+		Overflow: heal - applied,
+		NowMs:    m.nowMs(),
+	}
+	if source != nil {
+		log.Source = source.GetId()
+		m.threat.AddHeal(log.Source, applied, log.NowMs)
+	}
+	m.emitCombatLog(log)
+
+	return applied
+}
+
+func (m *CombatManager) emitCombatLog(log *CombatLog) {
+	if m.OnCombatLog != nil {
+		m.OnCombatLog(log)
+	}
+}
+
+// nowMs 获取当前逻辑时间：区域的统一时钟是唯一的时间来源，未加入区域时为 0。
+func (m *CombatManager) nowMs() int64 {
+	if z := m.owner.GetZone(); z != nil {
+		return z.NowMs()
+	}
+	return 0
+}
+
+// SetRand 设置战斗随机数源（测试中注入固定种子以复现结果）。
+func (m *CombatManager) SetRand(rng Rand) {
+	m.rng = rng
+}
+
+// SetAttackTable 设置攻击判定参数。
+func (m *CombatManager) SetAttackTable(table *AttackTable) {
+	m.attackTable = table
+}
+
+// mitigationTableOf 获取承受伤害一方的减免参数，未挂载战斗模块时为 nil（使用默认参数）。
+func mitigationTableOf(defender izone.IEntity) *MitigationTable {
+	if m := GetCombatManager(defender); m != nil {
+		return m.mitigationTable
+	}
+	return nil
+}
+
+// SetMitigationTable 设置本实体承受伤害时的减免参数。
+func (m *CombatManager) SetMitigationTable(table *MitigationTable) {
+	m.mitigationTable = table
+}
+
+// scaleCur 上限由 oldMax 变为 newMax 时按比例缩放当前值，存活（cur > 0）时至少保留 1 点。
+func scaleCur(cur, oldMax, newMax int64) int64 {
+	newMax = max(newMax, 0)
+	if oldMax <= 0 {
+		return min(cur, newMax)
+	}
+	scaled := min(int64(math.Round(float64(cur)*float64(newMax)/float64(oldMax))), newMax)
+	if cur > 0 && newMax > 0 {
+		scaled = max(scaled, 1)
+	}
+	return scaled
+}
+
+// GetAttrs 获取运行时属性集。
+func (m *CombatManager) GetAttrs() *data.AttrSet {
+	return m.attrs
+}
+
+// AddAttrModifier 实现 skill.IStatus：添加一条属性修正。
+func (m *CombatManager) AddAttrModifier(mod data.AttrModifier) {
+	m.attrs.AddModifier(mod)
+}
+
+// RemoveAttrModifiers 实现 skill.IStatus：移除指定来源的所有属性修正。
+func (m *CombatManager) RemoveAttrModifiers(source uid.Uid) {
+	m.attrs.RemoveModifiers(source)
 }
 
 func (m *CombatManager) GetHp() int64 {
@@ -157,6 +492,42 @@ func (m *CombatManager) GetMaxHp() int64 {
 	return m.maxHp
 }
 
+// hpPct 获取生命百分比（0~1），最大生命为 0 时返回 0。
+func (m *CombatManager) hpPct() float64 {
+	if m.maxHp <= 0 {
+		return 0
+	}
+	return float64(m.hp) / float64(m.maxHp)
+}
+
+func (m *CombatManager) GetMp() int64 {
+	return m.resourceMgr.GetCur(conf.ResourceType_Mp)
+}
+
+func (m *CombatManager) GetMaxMp() int64 {
+	return m.resourceMgr.GetMax(conf.ResourceType_Mp)
+}
+
+// HasResource 判断资源是否足够（实现 skill.IResource）。
+func (m *CombatManager) HasResource(t conf.ResourceType, amount int64) bool {
+	return m.resourceMgr.Has(t, amount)
+}
+
+// CostResource 扣除技能消耗（实现 skill.IResource）。
+func (m *CombatManager) CostResource(t conf.ResourceType, amount int64) bool {
+	return m.resourceMgr.Cost(t, amount)
+}
+
+// RefundResource 返还技能消耗（实现 skill.IResource）。
+func (m *CombatManager) RefundResource(t conf.ResourceType, amount int64) {
+	m.resourceMgr.Add(t, amount)
+}
+
+// GetThreatTable 获取本实体的仇恨表。
+func (m *CombatManager) GetThreatTable() *ThreatTable {
+	return m.threat
+}
+
 func (m *CombatManager) GetSkillManager() *SkillManager {
 	return m.skillMgr
 }
@@ -164,3 +535,7 @@ func (m *CombatManager) GetSkillManager() *SkillManager {
 func (m *CombatManager) GetEffectManager() *EffectManager {
 	return m.effectMgr
 }
+
+func (m *CombatManager) GetResourceManager() *ResourceManager {
+	return m.resourceMgr
+}
@@ -0,0 +1,111 @@
+package combat
+
+import (
+	"testing"
+
+	"server/data/conf"
+	"server/data/enum"
+	"server/service/world/zone/entity/mod/combat/skill"
+	"server/service/world/zone/izone"
+)
+
+func TestCombatManager_DealDamage(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newTestEntity(z, 0, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 5000})
+	target := newTestEntity(z, 1, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 5000})
+	cm, tm := GetCombatManager(caster), GetCombatManager(target)
+
+	var logs []*CombatLog
+	tm.OnCombatLog = func(log *CombatLog) { logs = append(logs, log) }
+
+	// 真实伤害公式 1009：1000 点伤害，额外仇恨 200
+	ctx := skill.NewSkillContext(caster, nil, 1)
+	if applied := cm.DealDamage(ctx, target, 1009, 200); applied != 1000 {
+		t.Errorf("Expected 1000 applied damage, got %d", applied)
+	}
+	if tm.GetHp() != 4000 {
+		t.Errorf("Expected target hp 4000, got %d", tm.GetHp())
+	}
+	if cm.GetHp() != 5000 {
+		t.Errorf("Expected caster hp unchanged, got %d", cm.GetHp())
+	}
+
+	res := ctx.GetCurrentResult()
+	if res.Damage != 1000 || ctx.TotalDamage != 1000 || len(res.Targets) != 1 {
+		t.Errorf("Expected damage recorded in context, got res=%d total=%d targets=%d", res.Damage, ctx.TotalDamage, len(res.Targets))
+	}
+
+	entry, ok := tm.GetThreatTable().Get(caster.GetId())
+	if !ok || entry.Damage != 1000 || entry.Threat != 1200 {
+		t.Errorf("Expected threat entry damage=1000 threat=1200, got %+v", entry)
+	}
+
+	if len(logs) != 1 || logs[0].Source != caster.GetId() || logs[0].Target != target.GetId() || logs[0].Type != CombatLogType_Damage {
+		t.Fatalf("Expected one damage log from caster to target, got %v", logs)
+	}
+}
+
+func TestCombatManager_ApplyDamage_Overkill(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newTestEntity(z, 0, 0, nil)
+	target := newTestEntity(z, 1, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 300})
+	cm, tm := GetCombatManager(caster), GetCombatManager(target)
+
+	var last *CombatLog
+	tm.OnCombatLog = func(log *CombatLog) { last = log }
+
+	if applied := cm.ApplyDamage(target, 500); applied != 300 {
+		t.Errorf("Expected 300 applied damage, got %d", applied)
+	}
+	if tm.GetHp() != 0 {
+		t.Errorf("Expected target hp 0, got %d", tm.GetHp())
+	}
+	if last == nil || last.Overflow != 200 {
+		t.Errorf("Expected overkill 200, got %v", last)
+	}
+}
+
+func TestCombatManager_ApplyHeal(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	healer := newTestEntity(z, 0, 0, nil)
+	target := newTestEntity(z, 1, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 1000})
+	hm, tm := GetCombatManager(healer), GetCombatManager(target)
+
+	hm.ApplyDamage(target, 300)
+	if applied := hm.ApplyHeal(target, 500); applied != 300 {
+		t.Errorf("Expected 300 applied heal, got %d", applied)
+	}
+	if tm.GetHp() != 1000 {
+		t.Errorf("Expected target hp 1000, got %d", tm.GetHp())
+	}
+
+	entry, _ := tm.GetThreatTable().Get(healer.GetId())
+	if entry == nil || entry.Heal != 300 {
+		t.Errorf("Expected heal 300 recorded, got %+v", entry)
+	}
+}
+
+func TestDamageEffect_Begin(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newTestEntity(z, 0, 0, nil)
+	a := newTestEntity(z, 1, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 5000})
+	b := newTestEntity(z, 2, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 5000})
+
+	ctx := skill.NewSkillContext(caster, nil, 1)
+	eff := skill.CreateEffect(conf.EffectCfg{Type: conf.EffectType_Damage, RefId: 1009})
+	eff.Begin(ctx, caster, []izone.IEntity{a, b})
+
+	for _, e := range []*testEntity{a, b} {
+		if hp := GetCombatManager(e).GetHp(); hp != 4000 {
+			t.Errorf("Expected hp 4000, got %d", hp)
+		}
+	}
+	if ctx.TotalDamage != 2000 {
+		t.Errorf("Expected total damage 2000, got %d", ctx.TotalDamage)
+	}
+
+	highest, ok := GetCombatManager(a).GetThreatTable().Highest()
+	if !ok || highest != caster.GetId() {
+		t.Errorf("Expected caster as highest threat, got %d", highest)
+	}
+}
@@ -1,9 +1,12 @@
 package combat
 
 import (
+	"slices"
+
 	"server/lib/container"
 	"server/lib/uid"
 	"server/service/world/zone/entity/mod/combat/skill"
+	"server/service/world/zone/izone"
 )
 
 // EffectManager 效果管理器
@@ -13,8 +16,6 @@ type EffectManager struct {
 
 	// 运行中的效果列表
 	runningEffects *container.LMap[uid.Uid, *skill.EffectRuntime]
-
-	nowMs int64
 }
 
 func newEffectManager(combatMgr *CombatManager) *EffectManager {
@@ -31,9 +32,26 @@ func (m *EffectManager) AddEffect(runtime *skill.EffectRuntime) {
 	}
 
 	// 激活效果
-	runtime.Activate(m.nowMs)
+	runtime.Activate(m.owner.nowMs())
 
 	m.runningEffects.Set(runtime.Id, runtime)
+	m.track(runtime.Targets, 1)
+}
+
+// track 在目标上登记（delta > 0）或注销（delta < 0）本实体为效果来源，用于目标死亡时定向通知。
+func (m *EffectManager) track(targets []izone.IEntity, delta int) {
+	sourceId := m.owner.owner.GetId()
+	for _, target := range targets {
+		if tm := GetCombatManager(target); tm != nil {
+			tm.addEffectSource(sourceId, delta)
+		}
+	}
+}
+
+// delete 从运行列表中移除效果并注销其目标上的来源登记。
+func (m *EffectManager) delete(runtime *skill.EffectRuntime) {
+	m.runningEffects.Delete(runtime.Id)
+	m.track(runtime.Targets, -1)
 }
 
 // RemoveEffect 移除效果
@@ -44,7 +62,7 @@ func (m *EffectManager) RemoveEffect(effectId uid.Uid) {
 	}
 
 	runtime.Finish()
-	m.runningEffects.Delete(effectId)
+	m.delete(runtime)
 }
 
 // CancelEffect 取消效果（带回滚）
@@ -55,32 +73,32 @@ func (m *EffectManager) CancelEffect(effectId uid.Uid) {
 	}
 
 	runtime.Cancel()
-	m.runningEffects.Delete(effectId)
+	m.delete(runtime)
 }
 
 // Update 更新所有效果
 func (m *EffectManager) Update(deltaMs int64) {
-	m.nowMs += deltaMs
+	nowMs := m.owner.nowMs()
 
 	// 收集过期的效果
-	expiredIds := make([]uid.Uid, 0)
+	expired := make([]*skill.EffectRuntime, 0)
 
 	for _, entry := range m.runningEffects.Entries() {
 		runtime := entry.Value
 		// 检查是否过期
-		if runtime.IsExpired(m.nowMs) {
+		if runtime.IsExpired(nowMs) {
 			runtime.Finish()
-			expiredIds = append(expiredIds, entry.Key)
+			expired = append(expired, runtime)
 			continue
 		}
 
 		// 执行Tick
-		runtime.DoTick(m.nowMs)
+		runtime.DoTick(nowMs)
 	}
 
 	// 清理过期效果
-	for _, id := range expiredIds {
-		m.runningEffects.Delete(id)
+	for _, runtime := range expired {
+		m.delete(runtime)
 	}
 }
 
@@ -123,7 +141,7 @@ func (m *EffectManager) ResumeEffect(effectId uid.Uid) {
 		return
 	}
 
-	runtime.Resume(m.nowMs)
+	runtime.Resume(m.owner.nowMs())
 }
 
 // PauseAllEffects 暂停目标身上的所有效果
@@ -143,7 +161,7 @@ func (m *EffectManager) ResumeAllEffects(targetId uid.Uid) {
 	m.runningEffects.ForEach(func(runtime *skill.EffectRuntime) {
 		for _, target := range runtime.Targets {
 			if target.GetId() == targetId {
-				runtime.Resume(m.nowMs)
+				runtime.Resume(m.owner.nowMs())
 				break
 			}
 		}
@@ -176,7 +194,7 @@ func (m *EffectManager) GetEffectProgress(effectId uid.Uid) float32 {
 	if !ok {
 		return 1.0
 	}
-	return runtime.GetProgress(m.nowMs)
+	return runtime.GetProgress(m.owner.nowMs())
 }
 
 // GetEffectRemainingMs 获取效果剩余时间
@@ -185,32 +203,61 @@ func (m *EffectManager) GetEffectRemainingMs(effectId uid.Uid) int64 {
 	if !ok {
 		return 0
 	}
-	return runtime.GetRemainingMs(m.nowMs)
+	return runtime.GetRemainingMs(m.owner.nowMs())
 }
 
 // Clear 清空所有效果
 func (m *EffectManager) Clear() {
 	m.runningEffects.ForEach(func(runtime *skill.EffectRuntime) {
 		runtime.Finish()
+		m.track(runtime.Targets, -1)
 	})
 	m.runningEffects.Clear()
 }
 
 // ClearByTarget 清空目标身上的所有效果
 func (m *EffectManager) ClearByTarget(targetId uid.Uid) {
-	toRemove := make([]uid.Uid, 0)
+	toRemove := make([]*skill.EffectRuntime, 0)
 
 	for _, entry := range m.runningEffects.Entries() {
 		runtime := entry.Value
 		for _, target := range runtime.Targets {
 			if target.GetId() == targetId {
 				runtime.Finish()
-				toRemove = append(toRemove, entry.Key)
+				toRemove = append(toRemove, runtime)
 				break
 			}
 		}
 	}
 
+	for _, runtime := range toRemove {
+		m.delete(runtime)
+	}
+}
+
+// RemoveDeadTarget 目标死亡时将其从效果目标中移除，移除后没有剩余目标的效果会被取消（带回滚）。
+func (m *EffectManager) RemoveDeadTarget(targetId uid.Uid) {
+	toRemove := make([]uid.Uid, 0)
+
+	for _, entry := range m.runningEffects.Entries() {
+		runtime := entry.Value
+		idx := slices.IndexFunc(runtime.Targets, func(e izone.IEntity) bool { return e.GetId() == targetId })
+		if idx < 0 {
+			continue
+		}
+
+		dead := runtime.Targets[idx]
+		n := len(runtime.Targets)
+		runtime.Targets = slices.DeleteFunc(slices.Clone(runtime.Targets), func(e izone.IEntity) bool {
+			return e.GetId() == targetId
+		})
+		m.track([]izone.IEntity{dead}, len(runtime.Targets)-n)
+		if len(runtime.Targets) == 0 {
+			runtime.Cancel()
+			toRemove = append(toRemove, entry.Key)
+		}
+	}
+
 	for _, id := range toRemove {
 		m.runningEffects.Delete(id)
 	}
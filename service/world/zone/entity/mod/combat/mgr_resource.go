@@ -0,0 +1,211 @@
+package combat
+
+import (
+	"server/data/conf"
+	"server/data/enum"
+	"server/lib/container"
+)
+
+// ResourceRule 为一种资源的上限与自然变化规则。
+// 回复与衰减按毫秒累计，不足 1 点的部分保留到下一次 Update。
+type ResourceRule struct {
+	Type conf.ResourceType
+
+	MaxAttr   enum.AttrType // 上限取自该属性（为 Invalid 时使用 Max）
+	Max       int64         // 固定上限
+	StartFull bool          // 初始/复活后是否为满值（否则为 0）
+
+	RegenPerSec  int64 // 每秒固定回复
+	RegenRate    int64 // 每秒按上限比例回复（万分比）
+	DecayPerSec  int64 // 每秒衰减
+	DecayDelayMs int64 // 最后一次获得资源后多久开始衰减
+}
+
+// DefaultResourceRules 为默认的资源规则：
+//   - 法力：上限取 MaxMp，每秒回复 2% 上限
+//   - 怒气：上限 100，初始为 0，5 秒未获得怒气后每秒衰减 2 点
+//   - 能量：上限 100，每秒回复 10 点
+var DefaultResourceRules = []ResourceRule{
+	{Type: conf.ResourceType_Mp, MaxAttr: enum.AttrType_MaxMp, StartFull: true, RegenRate: 200},
+	{Type: conf.ResourceType_Rage, Max: 100, DecayPerSec: 2, DecayDelayMs: 5000},
+	{Type: conf.ResourceType_Energy, Max: 100, StartFull: true, RegenPerSec: 10},
+}
+
+// Resource 为一种资源的运行时数据。
+type Resource struct {
+	Rule *ResourceRule
+
+	Cur int64
+	Max int64
+
+	acc        int64 // 未满 1 点的回复/衰减累计（点×毫秒）
+	lastGainMs int64 // 最后一次获得资源的时间
+}
+
+// ResourceManager 资源管理器
+// 负责法力/怒气/能量等技能消耗资源的上限、扣除、返还与自然回复/衰减
+type ResourceManager struct {
+	owner *CombatManager
+
+	resources *container.LMap[conf.ResourceType, *Resource]
+
+	// OnChanged 资源数值变化时的回调
+	OnChanged func(res *Resource)
+}
+
+func newResourceManager(combatMgr *CombatManager, rules []ResourceRule) *ResourceManager {
+	m := &ResourceManager{
+		owner:     combatMgr,
+		resources: container.NewLMap[conf.ResourceType, *Resource](),
+	}
+	for i := range rules {
+		rule := &rules[i]
+		res := &Resource{Rule: rule, Max: m.maxOf(rule)}
+		if rule.StartFull {
+			res.Cur = res.Max
+		}
+		m.resources.Set(rule.Type, res)
+	}
+	return m
+}
+
+// maxOf 计算资源上限。
+func (m *ResourceManager) maxOf(rule *ResourceRule) int64 {
+	if rule.MaxAttr != enum.AttrType_Invalid {
+		return max(m.owner.attrs.GetValue(rule.MaxAttr), 0)
+	}
+	return max(rule.Max, 0)
+}
+
+// onAttrChange 属性变化时更新以该属性为上限的资源，当前值按原比例缩放。
+func (m *ResourceManager) onAttrChange(ty enum.AttrType) {
+	m.resources.ForEach(func(res *Resource) {
+		if res.Rule.MaxAttr != ty {
+			return
+		}
+		oldMax := res.Max
+		res.Max = m.maxOf(res.Rule)
+		m.set(res, scaleCur(res.Cur, oldMax, res.Max))
+	})
+}
+
+// Update 按规则回复/衰减资源，死亡期间不变化。
+func (m *ResourceManager) Update(deltaMs int64) {
+	if deltaMs <= 0 || m.owner.dead {
+		return
+	}
+	nowMs := m.owner.nowMs()
+
+	m.resources.ForEach(func(res *Resource) {
+		rule := res.Rule
+		rate := rule.RegenPerSec + res.Max*rule.RegenRate/RatePrecision
+		if rule.DecayPerSec > 0 && nowMs-res.lastGainMs >= rule.DecayDelayMs {
+			rate -= rule.DecayPerSec
+		}
+		if rate == 0 {
+			res.acc = 0
+			return
+		}
+
+		res.acc += rate * deltaMs
+		delta := res.acc / 1000
+		if delta == 0 {
+			return
+		}
+		res.acc -= delta * 1000
+		m.set(res, res.Cur+delta)
+		if res.Cur == 0 || res.Cur == res.Max {
+			res.acc = 0
+		}
+	})
+}
+
+// set 设置资源当前值（限制在 [0, Max]），变化时触发回调。
+func (m *ResourceManager) set(res *Resource, val int64) {
+	val = min(max(val, 0), res.Max)
+	if val == res.Cur {
+		return
+	}
+	res.Cur = val
+	if m.OnChanged != nil {
+		m.OnChanged(res)
+	}
+}
+
+// Get 获取资源，实体没有该资源时返回 nil。
+func (m *ResourceManager) Get(t conf.ResourceType) *Resource {
+	res, _ := m.resources.Get(t)
+	return res
+}
+
+// GetCur 获取资源当前值。
+func (m *ResourceManager) GetCur(t conf.ResourceType) int64 {
+	if res := m.Get(t); res != nil {
+		return res.Cur
+	}
+	return 0
+}
+
+// GetMax 获取资源上限。
+func (m *ResourceManager) GetMax(t conf.ResourceType) int64 {
+	if res := m.Get(t); res != nil {
+		return res.Max
+	}
+	return 0
+}
+
+// Has 判断资源是否足够，实体没有该资源时视为不足。
+func (m *ResourceManager) Has(t conf.ResourceType, amount int64) bool {
+	if amount <= 0 {
+		return true
+	}
+	res := m.Get(t)
+	return res != nil && res.Cur >= amount
+}
+
+// Cost 扣除资源，不足时不扣除并返回 false。
+func (m *ResourceManager) Cost(t conf.ResourceType, amount int64) bool {
+	if amount <= 0 {
+		return true
+	}
+	if !m.Has(t, amount) {
+		return false
+	}
+	res := m.Get(t)
+	m.set(res, res.Cur-amount)
+	return true
+}
+
+// Add 增加资源（如攻击获得怒气、返还消耗），返回实际增加量。
+func (m *ResourceManager) Add(t conf.ResourceType, amount int64) int64 {
+	res := m.Get(t)
+	if res == nil || amount <= 0 {
+		return 0
+	}
+	before := res.Cur
+	m.set(res, res.Cur+amount)
+	res.lastGainMs = m.owner.nowMs()
+	return res.Cur - before
+}
+
+// Restore 按上限比例（0~1）设置资源，用于复活等场景。
+func (m *ResourceManager) Restore(t conf.ResourceType, pct float64) {
+	res := m.Get(t)
+	if res == nil {
+		return
+	}
+	res.acc = 0
+	m.set(res, int64(float64(res.Max)*pct+0.5))
+}
+
+// Reset 将所有资源恢复为初始值（满值或 0）。
+func (m *ResourceManager) Reset() {
+	m.resources.ForEach(func(res *Resource) {
+		res.acc = 0
+		if res.Rule.StartFull {
+			m.set(res, res.Max)
+		} else {
+			m.set(res, 0)
+		}
+	})
+}
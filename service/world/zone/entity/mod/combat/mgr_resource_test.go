@@ -0,0 +1,169 @@
+package combat
+
+import (
+	"testing"
+
+	"server/data"
+	"server/data/conf"
+	"server/data/enum"
+	"server/lib/uid"
+	"server/pb"
+	"server/service/world/zone/entity/mod/combat/skill"
+)
+
+func TestResourceManager_RegenAndDecay(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	e := newTestEntity(z, 0, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100, enum.AttrType_MaxMp: 1000})
+	cm := GetCombatManager(e)
+	rm := cm.GetResourceManager()
+
+	if cm.GetMp() != 1000 || rm.GetCur(conf.ResourceType_Rage) != 0 || rm.GetCur(conf.ResourceType_Energy) != 100 {
+		t.Fatalf("Unexpected initial resources: mp=%d rage=%d energy=%d",
+			cm.GetMp(), rm.GetCur(conf.ResourceType_Rage), rm.GetCur(conf.ResourceType_Energy))
+	}
+
+	// 法力每秒回复 2% 上限（20 点），分多次 Update 累计不足 1 点的部分
+	rm.Cost(conf.ResourceType_Mp, 500)
+	for range 10 {
+		z.Advance(100)
+	}
+	if cm.GetMp() != 520 {
+		t.Errorf("Expected mp 520 after 1s regen, got %d", cm.GetMp())
+	}
+
+	// 能量每秒回复 10 点，不超过上限
+	rm.Cost(conf.ResourceType_Energy, 95)
+	z.Advance(1000)
+	if got := rm.GetCur(conf.ResourceType_Energy); got != 15 {
+		t.Errorf("Expected energy 15, got %d", got)
+	}
+	z.Advance(10000)
+	if got := rm.GetCur(conf.ResourceType_Energy); got != 100 {
+		t.Errorf("Expected energy capped at 100, got %d", got)
+	}
+
+	// 怒气获得后 5 秒内不衰减，之后每秒衰减 2 点
+	rm.Add(conf.ResourceType_Rage, 30)
+	z.Advance(4000)
+	if got := rm.GetCur(conf.ResourceType_Rage); got != 30 {
+		t.Errorf("Expected rage 30 before decay delay, got %d", got)
+	}
+	z.Advance(1000)
+	z.Advance(2000)
+	if got := rm.GetCur(conf.ResourceType_Rage); got != 24 {
+		t.Errorf("Expected rage 24 after decay, got %d", got)
+	}
+}
+
+func TestResourceManager_Cost(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	e := newTestEntity(z, 0, 0, map[enum.AttrType]int64{enum.AttrType_MaxMp: 100})
+	rm := GetCombatManager(e).GetResourceManager()
+
+	if rm.Cost(conf.ResourceType_Mp, 150) || rm.GetCur(conf.ResourceType_Mp) != 100 {
+		t.Error("Expected insufficient cost to fail without deducting")
+	}
+	if !rm.Cost(conf.ResourceType_Mp, 60) || rm.GetCur(conf.ResourceType_Mp) != 40 {
+		t.Error("Expected cost to deduct 60")
+	}
+	if rm.Has(conf.ResourceType(99), 1) {
+		t.Error("Expected unknown resource to be insufficient")
+	}
+}
+
+func TestSkill_ResourceCostAndRefund(t *testing.T) {
+	tables := loadTestTables()
+	z := newTestZone(tables)
+	caster := newTestEntity(z, 0, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100, enum.AttrType_MaxMp: 200})
+	target := newTestEntity(z, 5, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100})
+	setEnemies(caster, target)
+	cm := GetCombatManager(caster)
+	sm := cm.GetSkillManager()
+	req := &pb.ReqCastSkill{LockTarget: int64(target.GetId())}
+
+	// 火球术：吟唱 2 秒，消耗 150 法力；配表默认取消时不返还
+	sm.AddSkill(tables.GetSkill(1001))
+	if !sm.Cast(1001, req).Ok() || cm.GetMp() != 50 {
+		t.Fatalf("Expected cast to deduct 150 mp, got %d", cm.GetMp())
+	}
+	sm.Cancel(1001)
+	if cm.GetMp() != 50 {
+		t.Errorf("Expected no refund by default, got %d", cm.GetMp())
+	}
+
+	// 开启取消返还后再次施法（等待公共冷却结束并回满法力）
+	tables.GetSkill(1001).RefundOnCancel = true
+	rt, _ := sm.skills.Get(1001)
+	z.Advance(rt.GcdEndAt - z.NowMs())
+	cm.GetResourceManager().Add(conf.ResourceType_Mp, 200)
+	if !sm.Cast(1001, req).Ok() || cm.GetMp() != 50 {
+		t.Fatalf("Expected cast to deduct 150 mp, got %d", cm.GetMp())
+	}
+	sm.Cancel(1001)
+	if cm.GetMp() != 200 {
+		t.Errorf("Expected mp refunded on cancel, got %d", cm.GetMp())
+	}
+
+	cm.GetResourceManager().Cost(conf.ResourceType_Mp, 100)
+	if r := rt.StartCast(rt.GcdEndAt, skill.NewSkillContext(caster, req, 1)); r != skill.CastResult_NotEnoughResource {
+		t.Errorf("Expected NotEnoughResource, got %d", r)
+	}
+	if cm.GetMp() != 100 || rt.State != skill.RuntimeState_Idle {
+		t.Errorf("Expected failed cast to keep mp and stay idle, got mp=%d state=%d", cm.GetMp(), rt.State)
+	}
+}
+
+func TestCombatManager_AttrChange(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	e := newTestEntity(z, 0, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 1000, enum.AttrType_MaxMp: 1000})
+	cm := GetCombatManager(e)
+	changed := 0
+	cm.OnAttrChange = func(enum.AttrType, int64, int64) { changed++ }
+	cm.ApplyDamage(e, 400)
+	cm.GetResourceManager().Cost(conf.ResourceType_Mp, 500)
+
+	// 生命与法力上限变化时当前值按原比例缩放
+	src := uid.Gen()
+	cm.AddAttrModifier(data.AttrModifier{Source: src, Type: enum.AttrType_MaxHp, ModType: data.AttrModType_PctAdd, Value: -5000})
+	cm.AddAttrModifier(data.AttrModifier{Source: src, Type: enum.AttrType_MaxMp, ModType: data.AttrModType_Flat, Value: 1000})
+	if cm.GetMaxHp() != 500 || cm.GetHp() != 300 {
+		t.Errorf("Expected hp 300/500, got %d/%d", cm.GetHp(), cm.GetMaxHp())
+	}
+	if rm := cm.GetResourceManager(); rm.GetMax(conf.ResourceType_Mp) != 2000 || cm.GetMp() != 1000 {
+		t.Errorf("Expected mp 1000/2000, got %d/%d", cm.GetMp(), rm.GetMax(conf.ResourceType_Mp))
+	}
+
+	cm.RemoveAttrModifiers(src)
+	if cm.GetMaxHp() != 1000 || cm.GetHp() != 600 || cm.GetMp() != 500 || changed != 4 {
+		t.Errorf("Expected hp 600/1000 mp 500, got %d/%d mp %d, %d changes", cm.GetHp(), cm.GetMaxHp(), cm.GetMp(), changed)
+	}
+
+	// 存活时缩放后至少保留 1 点
+	if got := scaleCur(1, 1000, 10); got != 1 {
+		t.Errorf("Expected 1 hp kept, got %d", got)
+	}
+}
+
+func TestCombatManager_ClassDerives(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	// 职业 1：体质 ×10 → 最大生命，力量 ×2 → 物理攻击
+	attrs := data.Attrs{
+		{Type: enum.AttrType_MaxHp, Val: 100},
+		{Type: enum.AttrType_Constitution, Val: 50},
+		{Type: enum.AttrType_Strength, Val: 20},
+	}
+	e := newEntityAt(0, 0)
+	e.Init(z, data.EntityInitData{Attrs: &attrs, ClassId: 1})
+	cm := GetCombatManager(e)
+	if cm.GetMaxHp() != 600 || cm.GetHp() != 600 || cm.GetAttrs().GetValue(enum.AttrType_PhyAttack) != 40 {
+		t.Fatalf("Expected derived hp 600 and attack 40, got %d/%d attack %d",
+			cm.GetHp(), cm.GetMaxHp(), cm.GetAttrs().GetValue(enum.AttrType_PhyAttack))
+	}
+
+	// 主属性变化时重新计算次级属性，当前生命保持比例
+	cm.ApplyDamage(e, 300)
+	cm.GetAttrs().SetBase(enum.AttrType_Constitution, 100)
+	if cm.GetMaxHp() != 1100 || cm.GetHp() != 550 {
+		t.Errorf("Expected hp 550/1100, got %d/%d", cm.GetHp(), cm.GetMaxHp())
+	}
+}
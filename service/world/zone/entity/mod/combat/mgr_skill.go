@@ -9,6 +9,9 @@ import (
 	"server/service/world/zone/izone"
 )
 
+// DefaultRangeTolerance 为施法距离校验的默认容差（补偿客户端与服务器的位置误差）。
+const DefaultRangeTolerance = 1.0
+
 type SkillManager struct {
 	*CombatManager
 
@@ -16,7 +19,10 @@ type SkillManager struct {
 
 	skills *container.LMap[int64, *skill.Skill]
 
-	NowMs int64
+	rangeTolerance float64 // 施法距离容差
+
+	// OnCastFailed 吟唱结束时校验失败（如目标跑出施法距离）导致施法被打断的回调
+	OnCastFailed func(skillId int64, result skill.CastResult)
 }
 
 func newSkillManager(combatMgr *CombatManager) *SkillManager {
@@ -24,6 +30,8 @@ func newSkillManager(combatMgr *CombatManager) *SkillManager {
 		CombatManager: combatMgr,
 		owner:         combatMgr.owner,
 		skills:        container.NewLMap[int64, *skill.Skill](),
+
+		rangeTolerance: DefaultRangeTolerance,
 	}
 	ret.Init()
 
@@ -35,12 +43,9 @@ func (m *SkillManager) Init() {
 }
 
 func (m *SkillManager) Update(deltaMs int64) {
-	m.NowMs += deltaMs
-
+	nowMs := m.nowMs()
 	m.skills.ForEach(func(s *skill.Skill) {
-		s.Update(m.NowMs, func(stage skill.Stage, eff conf.EffectCfg, ctx *skill.SkillContext) {
-			m.execEffect(s, stage, eff, ctx)
-		})
+		s.Update(nowMs, m.execEffect)
 	})
 }
 
@@ -48,17 +53,151 @@ func (m *SkillManager) AddSkill(cfg *conf.CSkill) {
 	if cfg == nil {
 		return
 	}
-	m.skills.Set(cfg.Cid, skill.NewSkill(cfg))
+	rt := skill.NewSkill(cfg)
+	rt.FinishCheck = func(ctx *skill.SkillContext) skill.CastResult {
+		return m.checkFinish(rt, ctx)
+	}
+	m.skills.Set(cfg.Cid, rt)
+}
+
+// SetRangeTolerance 设置施法距离容差。
+func (m *SkillManager) SetRangeTolerance(tolerance float64) {
+	m.rangeTolerance = max(tolerance, 0)
 }
 
-func (m *SkillManager) Cast(skillId int64, req *pb.ReqCastSkill) bool {
+// Cast 尝试施放技能，返回施法结果（可通过 CastResult.ToRsp 返回给客户端）。
+// 校验顺序：施法者状态（死亡/眩晕/沉默）→ 技能是否存在 → 目标 → 技能自身（施法中/GCD/CD/充能/资源）。
+func (m *SkillManager) Cast(skillId int64, req *pb.ReqCastSkill) skill.CastResult {
+	if m.IsDead() {
+		return skill.CastResult_Dead
+	}
 	rt, ok := m.skills.Get(skillId)
 	if !ok {
-		return false
+		return skill.CastResult_UnknownSkill
+	}
+	m.refreshCfg(rt)
+
+	if m.HasControl(conf.CCType_Stun) && !rt.Cfg.CanCastWhileStunned {
+		return skill.CastResult_Stunned
+	}
+	if m.HasControl(conf.CCType_Silence) {
+		return skill.CastResult_Silenced
+	}
+	if r := m.checkTarget(&rt.Cfg.Target, req); !r.Ok() {
+		return r
+	}
+	if r := m.checkRange(rt.Cfg, req); !r.Ok() {
+		return r
 	}
 
 	ctx := skill.NewSkillContext(m.owner, req, 1)
-	return rt.StartCast(m.NowMs, ctx)
+	return rt.StartCast(m.nowMs(), ctx)
+}
+
+// checkTarget 校验技能目标：点选技能需指定位置；单体技能的锁定目标需存在于区域内，
+// 且满足选择器的过滤条件（敌友关系、存活、生命、Buff，见 matchTarget）。
+func (m *SkillManager) checkTarget(cfg *conf.TargetCfg, req *pb.ReqCastSkill) skill.CastResult {
+	if cfg.Mode == conf.TargetMode_Point && (req == nil || req.Pos == nil) {
+		return skill.CastResult_InvalidTarget
+	}
+	if cfg.Mode != conf.TargetMode_Unit || cfg.Relation == conf.TargetRelation_Self || cfg.Shape != conf.ShapeType_Single {
+		return skill.CastResult_Success
+	}
+	if req == nil || !uid.Uid(req.LockTarget).IsValid() {
+		return skill.CastResult_InvalidTarget
+	}
+	z := m.owner.GetZone()
+	if z == nil {
+		return skill.CastResult_InvalidTarget
+	}
+	target, ok := z.GetEntity(uid.Uid(req.LockTarget))
+	if !ok || !m.matchTarget(cfg, target) {
+		return skill.CastResult_InvalidTarget
+	}
+	return skill.CastResult_Success
+}
+
+// castPoint 获取需要校验施法距离的位置：单体（Single 形状）技能为锁定目标位置，点选技能为 Req.Pos；
+// check 为 false 表示该技能不校验施法距离（自身、无目标、以施法者为中心选取的区域技能）。
+func (m *SkillManager) castPoint(cfg *conf.TargetCfg, req *pb.ReqCastSkill) (point *pb.Vector, check bool) {
+	if cfg.Relation == conf.TargetRelation_Self {
+		return nil, false
+	}
+	switch cfg.Mode {
+	case conf.TargetMode_Unit:
+		if cfg.Shape != conf.ShapeType_Single {
+			return nil, false
+		}
+		z := m.owner.GetZone()
+		if req == nil || z == nil {
+			return nil, true
+		}
+		if target, ok := z.GetEntity(uid.Uid(req.LockTarget)); ok {
+			return target.GetPos(), true
+		}
+		return nil, true
+	case conf.TargetMode_Point:
+		if req == nil {
+			return nil, true
+		}
+		return req.Pos, true
+	}
+	return nil, false
+}
+
+// checkRange 校验施法者到目标/施法点的平面距离在 [RangeMin-容差, RangeMax+容差] 内，RangeMax 为 0 时不限制最大距离。
+// 需要校验但无法确定目标/施法点或施法者位置时视为超出施法距离。
+func (m *SkillManager) checkRange(cfg *conf.CSkill, req *pb.ReqCastSkill) skill.CastResult {
+	if cfg.RangeMin <= 0 && cfg.RangeMax <= 0 {
+		return skill.CastResult_Success
+	}
+	point, check := m.castPoint(&cfg.Target, req)
+	if !check {
+		return skill.CastResult_Success
+	}
+	pos := m.owner.GetPos()
+	if point == nil || pos == nil {
+		return skill.CastResult_OutOfRange
+	}
+
+	dist := pos.Distance2D(point)
+	if cfg.RangeMax > 0 && dist > float64(cfg.RangeMax)+m.rangeTolerance {
+		return skill.CastResult_OutOfRange
+	}
+	if cfg.RangeMin > 0 && dist < float64(cfg.RangeMin)-m.rangeTolerance {
+		return skill.CastResult_OutOfRange
+	}
+	return skill.CastResult_Success
+}
+
+// checkFinish 吟唱结束时重新校验目标与施法距离，失败时通知 OnCastFailed。
+func (m *SkillManager) checkFinish(rt *skill.Skill, ctx *skill.SkillContext) skill.CastResult {
+	if ctx == nil {
+		return skill.CastResult_Success
+	}
+	r := m.checkTarget(&rt.Cfg.Target, ctx.Req)
+	if r.Ok() {
+		r = m.checkRange(rt.Cfg, ctx.Req)
+	}
+	if !r.Ok() && m.OnCastFailed != nil {
+		m.OnCastFailed(rt.Cfg.Cid, r)
+	}
+	return r
+}
+
+// refreshCfg 若区域配置已热更，则让技能在下次空闲时切换到新配置。
+func (m *SkillManager) refreshCfg(rt *skill.Skill) {
+	z := m.owner.GetZone()
+	if z == nil {
+		return
+	}
+	tables := z.GetTables()
+	if tables == nil {
+		return
+	}
+	if cfg := tables.GetSkill(rt.Cfg.Cid); cfg != nil {
+		rt.Reload(cfg)
+	}
 }
 
 func (m *SkillManager) Cancel(skillId int64) {
@@ -66,33 +205,61 @@ func (m *SkillManager) Cancel(skillId int64) {
 	if !ok {
 		return
 	}
-	rt.Cancel(m.NowMs)
+	rt.Cancel(m.nowMs())
+}
+
+// GetChargeState 获取技能的充能状态（用于同步客户端），非充能技能或技能不存在时返回 nil。
+func (m *SkillManager) GetChargeState(skillId int64) *skill.ChargeState {
+	rt, ok := m.skills.Get(skillId)
+	if !ok {
+		return nil
+	}
+	return rt.GetChargeState(m.nowMs())
+}
+
+// CancelAll 打断所有吟唱/引导中的技能（如死亡时），已出手的 Effect 仍按计划结算。
+func (m *SkillManager) CancelAll() {
+	m.skills.ForEach(func(s *skill.Skill) {
+		s.Cancel(m.nowMs())
+	})
 }
 
-func (m *SkillManager) execEffect(s *skill.Skill, stage skill.Stage, eff conf.EffectCfg, ctx *skill.SkillContext) {
+// InterruptByControl 受到控制时打断吟唱/引导中的技能：只打断配置了 InterruptibleByCC 的技能，
+// 眩晕时不打断可在眩晕中施放（CanCastWhileStunned）的技能。
+func (m *SkillManager) InterruptByControl(t conf.CCType) {
+	m.skills.ForEach(func(s *skill.Skill) {
+		if !s.Cfg.InterruptibleByCC || (t == conf.CCType_Stun && s.Cfg.CanCastWhileStunned) {
+			return
+		}
+		s.Cancel(m.nowMs())
+	})
+}
+
+// execEffect 结算一个到期的 Effect，目标选择与结算均使用调度时记录的技能配置与上下文。
+func (m *SkillManager) execEffect(se *skill.ScheduledEffect) {
 	if m.CombatManager == nil {
 		return
 	}
 
-	targetCfg := m.selectTargetCfg(s, stage)
+	targetCfg := m.selectTargetCfg(se.Cfg, se.Stage)
 	if targetCfg == nil {
 		return
 	}
 
-	targets := m.selectTargets(targetCfg, ctx)
+	targets := m.selectTargets(targetCfg, se.Ctx)
 	if len(targets) == 0 {
 		return
 	}
 
-	m.CombatManager.ExecuteEffect(eff, ctx, m.owner, targets)
+	m.CombatManager.ExecuteEffect(se.Effect, se.Ctx, m.owner, targets)
 }
 
-func (m *SkillManager) selectTargetCfg(s *skill.Skill, stage skill.Stage) *conf.TargetCfg {
-	if s == nil || s.Cfg == nil {
+func (m *SkillManager) selectTargetCfg(cfg *conf.CSkill, stage skill.Stage) *conf.TargetCfg {
+	if cfg == nil {
 		return nil
 	}
 
-	selectors := s.Cfg.Selectors
+	selectors := cfg.Selectors
 	switch stage {
 	case skill.Stage_CastStart:
 		if selectors.OnCastStart != nil {
@@ -116,36 +283,52 @@ func (m *SkillManager) selectTargetCfg(s *skill.Skill, stage skill.Stage) *conf.
 		}
 	}
 
-	return &s.Cfg.Target
+	return &cfg.Target
 }
 
+// selectTargets 按选择器选取目标：先按模式与形状选出候选实体，再依次过滤、排序、截断（见 filterTargets）。
 func (m *SkillManager) selectTargets(cfg *conf.TargetCfg, ctx *skill.SkillContext) []izone.IEntity {
 	if cfg == nil || ctx == nil {
 		return nil
 	}
 
+	var targets []izone.IEntity
+	center := m.owner.GetPos()
 	if cfg.Relation == conf.TargetRelation_Self {
-		return []izone.IEntity{m.owner}
-	}
+		targets = []izone.IEntity{m.owner}
+	} else {
+		z := m.owner.GetZone()
+		if z == nil {
+			return nil
+		}
 
-	z := m.owner.GetZone()
-	if z == nil {
-		return nil
+		switch cfg.Mode {
+		case conf.TargetMode_Unit:
+			targets = m.selectUnit(z, ctx, cfg)
+		case conf.TargetMode_NoTarget:
+			targets = m.selectNoTarget(z, ctx, cfg)
+		case conf.TargetMode_Point:
+			targets = m.selectPoint(z, ctx, cfg)
+			if ctx.Req != nil {
+				center = ctx.Req.Pos
+			}
+		default:
+			return nil
+		}
 	}
 
-	switch cfg.Mode {
-	case conf.TargetMode_Unit:
-		return m.selectUnit(z, ctx, cfg)
-	case conf.TargetMode_NoTarget:
-		return m.selectNoTarget(z, ctx, cfg)
-	case conf.TargetMode_Point:
-		return m.selectPoint(z, ctx, cfg)
-	default:
-		return nil
-	}
+	return m.filterTargets(cfg, center, targets)
 }
 
+// selectUnit 选取锁定目标；配置了区域形状的单体选择器（如“血量最低友方”）在施法者周围选取，由排序决定目标。
 func (m *SkillManager) selectUnit(z izone.IZone, ctx *skill.SkillContext, cfg *conf.TargetCfg) []izone.IEntity {
+	if cfg.Shape != conf.ShapeType_Single {
+		pos := m.owner.GetPos()
+		if pos == nil {
+			return nil
+		}
+		return m.selectArea(z, cfg, pos, m.facing(ctx, nil))
+	}
 	if ctx.Req == nil {
 		return nil
 	}
@@ -163,61 +346,53 @@ func (m *SkillManager) selectUnit(z izone.IZone, ctx *skill.SkillContext, cfg *c
 	return []izone.IEntity{entity}
 }
 
+// selectNoTarget 以施法者为中心选取区域内的目标，朝向取 Req.Dir 或施法者朝向。
 func (m *SkillManager) selectNoTarget(z izone.IZone, ctx *skill.SkillContext, cfg *conf.TargetCfg) []izone.IEntity {
 	if cfg.Shape == conf.ShapeType_Single {
 		return []izone.IEntity{m.owner}
 	}
 
-	if cfg.Shape == conf.ShapeType_Circle {
-		pos := m.owner.GetPos()
-		if pos == nil {
-			return nil
-		}
-
-		newCtx := *ctx
-		if newCtx.Req == nil {
-			newCtx.Req = &pb.ReqCastSkill{}
-		}
-		newCtx.Req.Pos = &pb.Vector{X: pos.X, Y: pos.Y, Z: pos.Z}
-
-		return m.selectPoint(z, &newCtx, cfg)
+	pos := m.owner.GetPos()
+	if pos == nil {
+		return nil
 	}
-
-	return nil
+	return m.selectArea(z, cfg, pos, m.facing(ctx, nil))
 }
 
+// selectPoint 以 Req.Pos 为中心选取区域内的目标，朝向取 Req.Dir 或施法者指向施法点的方向。
 func (m *SkillManager) selectPoint(z izone.IZone, ctx *skill.SkillContext, cfg *conf.TargetCfg) []izone.IEntity {
-	if cfg.Shape != conf.ShapeType_Circle {
+	if ctx.Req == nil || ctx.Req.Pos == nil {
 		return nil
 	}
+	return m.selectArea(z, cfg, ctx.Req.Pos, m.facing(ctx, ctx.Req.Pos))
+}
 
-	r := float64(cfg.Radius)
-	if r <= 0 {
-		return nil
+// facing 获取区域朝向：优先使用 Req.Dir；其次为施法者指向 point 的方向；最后为施法者朝向。
+func (m *SkillManager) facing(ctx *skill.SkillContext, point *pb.Vector) *pb.Vector {
+	if ctx.Req != nil && ctx.Req.Dir != nil && ctx.Req.Dir.LengthSq2D() > 0 {
+		return ctx.Req.Dir.Norm2D()
 	}
-
-	if ctx.Req == nil || ctx.Req.Pos == nil {
-		return nil
+	if pos := m.owner.GetPos(); point != nil && pos != nil {
+		if d := point.Sub2D(pos); d.LengthSq2D() > 0 {
+			return d.Norm2D()
+		}
 	}
+	return DirToVector(m.owner.GetDir())
+}
 
-	center := *ctx.Req.Pos
-	r2 := r * r
+// selectArea 通过区域的空间索引选取范围内的实体：先按形状的外接范围查询，再用 InShape 精确判定。
+func (m *SkillManager) selectArea(z izone.IZone, cfg *conf.TargetCfg, center, facing *pb.Vector) []izone.IEntity {
 	result := make([]izone.IEntity, 0)
-
-	z.ForEach(func(e izone.IEntity) {
-		if e == nil {
-			return
-		}
-		p := e.GetPos()
-		if p == nil {
-			return
-		}
-		dx := p.X - center.X
-		dy := p.Y - center.Y
-		if dx*dx+dy*dy <= r2 {
+	collect := func(e izone.IEntity) {
+		if e != nil && InShape(cfg, center, facing, e.GetPos()) {
 			result = append(result, e)
 		}
-	})
-
+	}
+	switch cfg.Shape {
+	case conf.ShapeType_Cone:
+		z.QueryCone(center, facing, float64(cfg.Length), float64(cfg.Angle), collect)
+	default:
+		z.QueryRadius(center, boundingRadius(cfg), collect)
+	}
 	return result
 }
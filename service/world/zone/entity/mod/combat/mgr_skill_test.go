@@ -0,0 +1,238 @@
+package combat
+
+import (
+	"testing"
+
+	"server/data"
+	"server/data/conf"
+	"server/data/enum"
+	"server/pb"
+	"server/service/world/zone/entity/mod/combat/skill"
+)
+
+func TestSkillManager_CastResults(t *testing.T) {
+	tables := loadTestTables()
+	z := newTestZone(tables)
+	caster := newTestEntity(z, 0, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100, enum.AttrType_MaxMp: 1000})
+	target := newTestEntity(z, 5, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100})
+	setEnemies(caster, target)
+	cm := GetCombatManager(caster)
+	sm := cm.GetSkillManager()
+	sm.AddSkill(tables.GetSkill(1001)) // 火球术：单体敌方，吟唱 2 秒，GCD 1.5 秒
+	req := &pb.ReqCastSkill{LockTarget: int64(target.GetId())}
+
+	expect := func(name string, want skill.CastResult, got skill.CastResult) {
+		t.Helper()
+		if got != want {
+			t.Errorf("%s: expected %s, got %s", name, want, got)
+		}
+	}
+
+	expect("unknown skill", skill.CastResult_UnknownSkill, sm.Cast(424242, req))
+	expect("no target", skill.CastResult_InvalidTarget, sm.Cast(1001, &pb.ReqCastSkill{}))
+	expect("missing target", skill.CastResult_InvalidTarget, sm.Cast(1001, &pb.ReqCastSkill{LockTarget: 424242}))
+
+	cm.AddControl(conf.CCType_Stun)
+	expect("stunned", skill.CastResult_Stunned, sm.Cast(1001, req))
+	cm.RemoveControl(conf.CCType_Stun)
+	cm.AddControl(conf.CCType_Silence)
+	expect("silenced", skill.CastResult_Silenced, sm.Cast(1001, req))
+	cm.RemoveControl(conf.CCType_Silence)
+
+	expect("success", skill.CastResult_Success, sm.Cast(1001, req))
+	expect("casting", skill.CastResult_Casting, sm.Cast(1001, req))
+
+	// 眩晕打断吟唱，GCD 仍在
+	cm.AddControl(conf.CCType_Stun)
+	cm.RemoveControl(conf.CCType_Stun)
+	expect("gcd", skill.CastResult_Gcd, sm.Cast(1001, req))
+
+	z.Advance(1500)
+	expect("after gcd", skill.CastResult_Success, sm.Cast(1001, req))
+	z.Advance(2000)
+	expect("cooldown", skill.CastResult_Cooldown, sm.Cast(1001, req))
+
+	GetCombatManager(target).Kill(nil)
+	z.Advance(10000)
+	expect("dead target", skill.CastResult_InvalidTarget, sm.Cast(1001, req))
+}
+
+func TestSkillManager_CastWhileStunned(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newTestEntity(z, 0, 0, nil)
+	cm := GetCombatManager(caster)
+	sm := cm.GetSkillManager()
+	sm.AddSkill(&conf.CSkill{Cid: 1, CanCastWhileStunned: true})
+
+	cm.AddControl(conf.CCType_Stun)
+	if r := sm.Cast(1, nil); !r.Ok() {
+		t.Errorf("Expected cast while stunned, got %s", r)
+	}
+}
+
+func TestSkillManager_InterruptByControl(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newTestEntity(z, 0, 0, nil)
+	cm := GetCombatManager(caster)
+	sm := cm.GetSkillManager()
+	sm.AddSkill(&conf.CSkill{Cid: 1, CastTimeMs: 2000, InterruptibleByCC: true})
+	sm.AddSkill(&conf.CSkill{Cid: 2, CastTimeMs: 2000})
+	sm.AddSkill(&conf.CSkill{Cid: 3, CastTimeMs: 2000, InterruptibleByCC: true, CanCastWhileStunned: true})
+	casting := func(id int64) bool {
+		rt, _ := sm.skills.Get(id)
+		return rt.State == skill.RuntimeState_Casting
+	}
+
+	for _, id := range []int64{1, 2, 3} {
+		rt, _ := sm.skills.Get(id)
+		rt.StartCast(z.NowMs(), skill.NewSkillContext(caster, nil, 1))
+	}
+	cm.AddControl(conf.CCType_Stun)
+	if casting(1) || !casting(2) || !casting(3) {
+		t.Errorf("Expected stun to interrupt only skill 1, got %v %v %v", casting(1), casting(2), casting(3))
+	}
+	cm.AddControl(conf.CCType_Silence)
+	if !casting(2) || casting(3) {
+		t.Errorf("Expected silence to interrupt skill 3 only, got %v %v", casting(2), casting(3))
+	}
+	cm.AddControl(conf.CCType_Root)
+	if !casting(2) {
+		t.Error("Expected root not to interrupt")
+	}
+}
+
+func TestCastResult_ToRsp(t *testing.T) {
+	rsp := skill.CastResult_Cooldown.ToRsp(1001)
+	if rsp.Cid != 1001 || rsp.Err != pb.EErrorCode_Failed || rsp.Result != int32(skill.CastResult_Cooldown) {
+		t.Errorf("Unexpected rsp: %+v", rsp)
+	}
+	if skill.CastResult_Success.ToRsp(1).Err != pb.EErrorCode_Ok {
+		t.Error("Expected Ok error code on success")
+	}
+}
+
+func TestSkillManager_CastRange(t *testing.T) {
+	tables := loadTestTables()
+	z := newTestZone(tables)
+	caster := newTestEntity(z, 0, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100, enum.AttrType_MaxMp: 1000})
+	target := newTestEntity(z, 32, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100})
+	setEnemies(caster, target)
+	sm := GetCombatManager(caster).GetSkillManager()
+	sm.AddSkill(tables.GetSkill(1001)) // 火球术：RangeMax 30
+	req := &pb.ReqCastSkill{LockTarget: int64(target.GetId())}
+
+	if r := sm.Cast(1001, req); r != skill.CastResult_OutOfRange {
+		t.Errorf("Expected OutOfRange at 32, got %s", r)
+	}
+	// 容差 1 以内允许施法
+	target.SetPos(pb.NewVector(31, 0, 0))
+	if r := sm.Cast(1001, req); !r.Ok() {
+		t.Errorf("Expected cast within tolerance, got %s", r)
+	}
+
+	sm.SetRangeTolerance(0)
+	sm.AddSkill(&conf.CSkill{Cid: 2, RangeMin: 3, RangeMax: 10, Target: conf.TargetCfg{Mode: conf.TargetMode_Point, Relation: conf.TargetRelation_Enemy}})
+	cases := []struct {
+		pos  *pb.Vector
+		want skill.CastResult
+	}{
+		{nil, skill.CastResult_InvalidTarget},
+		{pb.NewVector(2.9, 0, 0), skill.CastResult_OutOfRange},
+		{pb.NewVector(0, 10.1, 0), skill.CastResult_OutOfRange},
+		{pb.NewVector(6, 8, 0), skill.CastResult_Success},
+	}
+	for _, c := range cases {
+		if r := sm.Cast(2, &pb.ReqCastSkill{Pos: c.pos}); r != c.want {
+			t.Errorf("Point %v: expected %s, got %s", c.pos, c.want, r)
+		}
+	}
+}
+
+func TestSkillManager_CastRangeAreaUnit(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newTestEntity(z, 0, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100})
+	far := newTestEntity(z, 50, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100})
+	sm := GetCombatManager(caster).GetSkillManager()
+	sm.SetRangeTolerance(0)
+
+	// 以施法者为中心选取的单体选择器不使用客户端的锁定目标校验距离
+	area := &conf.CSkill{Cid: 3, RangeMax: 10, Target: conf.TargetCfg{Mode: conf.TargetMode_Unit, Shape: conf.ShapeType_Circle, Relation: conf.TargetRelation_Ally, Radius: 10}}
+	sm.AddSkill(area)
+	if r := sm.Cast(3, &pb.ReqCastSkill{LockTarget: int64(far.GetId())}); !r.Ok() {
+		t.Errorf("Expected area unit cast to ignore LockTarget range, got %s", r)
+	}
+
+	// 无法确定施法点时视为超出距离
+	single := &conf.CSkill{Cid: 4, RangeMax: 10, Target: conf.TargetCfg{Mode: conf.TargetMode_Unit, Shape: conf.ShapeType_Single, Relation: conf.TargetRelation_Enemy}}
+	point := &conf.CSkill{Cid: 5, RangeMax: 10, Target: conf.TargetCfg{Mode: conf.TargetMode_Point, Relation: conf.TargetRelation_Enemy}}
+	if r := sm.checkRange(single, &pb.ReqCastSkill{}); r != skill.CastResult_OutOfRange {
+		t.Errorf("Expected OutOfRange without lock target, got %s", r)
+	}
+	if r := sm.checkRange(point, nil); r != skill.CastResult_OutOfRange {
+		t.Errorf("Expected OutOfRange without cast point, got %s", r)
+	}
+}
+
+func TestSkillManager_RangeRevalidatedOnCastFinish(t *testing.T) {
+	tables := loadTestTables()
+	z := newTestZone(tables)
+	caster := newTestEntity(z, 0, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 100, enum.AttrType_MaxMp: 1000})
+	target := newTestEntity(z, 10, 0, map[enum.AttrType]int64{enum.AttrType_MaxHp: 5000})
+	setEnemies(caster, target)
+	cm := GetCombatManager(caster)
+	sm := cm.GetSkillManager()
+	tables.GetSkill(1001).RefundOnCancel = true
+	sm.AddSkill(tables.GetSkill(1001))
+
+	var failed skill.CastResult
+	sm.OnCastFailed = func(skillId int64, r skill.CastResult) { failed = r }
+
+	if r := sm.Cast(1001, &pb.ReqCastSkill{LockTarget: int64(target.GetId())}); !r.Ok() {
+		t.Fatalf("Expected cast to start, got %s", r)
+	}
+
+	// 吟唱期间目标跑出施法距离
+	target.SetPos(pb.NewVector(40, 0, 0))
+	z.Advance(2000)
+
+	if failed != skill.CastResult_OutOfRange {
+		t.Errorf("Expected OutOfRange on cast finish, got %s", failed)
+	}
+	if hp := GetCombatManager(target).GetHp(); hp != 5000 {
+		t.Errorf("Expected no damage after interrupted cast, got hp %d", hp)
+	}
+	if cm.GetMp() != 1000 {
+		t.Errorf("Expected mp refunded, got %d", cm.GetMp())
+	}
+}
+
+func TestCombatManager_ZoneClock(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	a := newTestEntity(z, 0, 0, nil)
+	b := newTestEntity(z, 1, 0, nil)
+	ca, cb := GetCombatManager(a), GetCombatManager(b)
+
+	// 所有实体读取同一区域时钟，与各自 Update 的次数无关
+	z.Clock.Set(5000)
+	ca.Update(100)
+	if ca.nowMs() != 5000 || cb.nowMs() != 5000 {
+		t.Errorf("Expected zone time 5000, got %d and %d", ca.nowMs(), cb.nowMs())
+	}
+
+	eff := skill.CreateEffect(conf.EffectCfg{Type: conf.EffectType_ApplyAura, RefId: 2001})
+	rt := skill.NewEffectRuntime(eff, skill.NewSkillContext(a, nil, 1), a, nil)
+	rt.EndMs = 6000
+	ca.GetEffectManager().AddEffect(rt)
+	z.Advance(500)
+	if rt.StartMs != 5000 || ca.GetEffectManager().GetEffectProgress(rt.Id) != 0.5 {
+		t.Errorf("Expected effect started at zone time with half progress, got start %d", rt.StartMs)
+	}
+
+	// 区域时钟是唯一的时间来源：未加入区域的实体 Update 不会推进自己的时间
+	solo := newEntityAt(0, 0)
+	solo.Init(nil, data.EntityInitData{})
+	solo.Update(300)
+	if got := GetCombatManager(solo).nowMs(); got != 0 {
+		t.Errorf("Expected no local time without zone, got %d", got)
+	}
+}
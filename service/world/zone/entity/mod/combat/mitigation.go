@@ -0,0 +1,96 @@
+package combat
+
+import (
+	"fmt"
+
+	"server/data/conf"
+	"server/data/enum"
+	config "server/data/xls"
+)
+
+// MitigationTable 为伤害减免的基础参数。
+type MitigationTable struct {
+	DefenseConstant float64 // 防御减伤常数 K：减伤比例 = 防御 / (防御 + K)
+	MaxReduction    float64 // 减伤比例上限（防御减伤与属性减伤分别受此限制）
+}
+
+// DefaultMitigationTable 为默认减免参数。
+var DefaultMitigationTable = MitigationTable{
+	DefenseConstant: 1000,
+	MaxReduction:    0.8,
+}
+
+// mitigationAttrs 为不同伤害类型对应的防御/穿透/增伤/减伤属性。
+type mitigationAttrs struct {
+	defense, penetration, bonus, reduction enum.AttrType
+}
+
+var (
+	physicalMitigationAttrs = mitigationAttrs{enum.AttrType_PhyDefense, enum.AttrType_PhysicalDefensePenetrationRate, enum.AttrType_PhyDamageBonus, enum.AttrType_PhyDamageReduction}
+	magicMitigationAttrs    = mitigationAttrs{enum.AttrType_MagicDefense, enum.AttrType_MagicDefensePenetrationRate, enum.AttrType_MagicDamageBonus, enum.AttrType_MagicDamageReduction}
+)
+
+// MitigationResult 为减免阶段的明细。比例均为 0~1 的小数。
+type MitigationResult struct {
+	DamageType conf.DamageType
+
+	Defense          float64 // 目标防御
+	Penetration      float64 // 穿透比例（攻击者穿透属性 + 公式 IgnoreArmorPct）
+	EffectiveDefense float64 // 穿透后的有效防御
+	DefenseReduction float64 // 防御减伤比例
+	BonusPct         float64 // 攻击者增伤比例
+	ReductionPct     float64 // 目标减伤比例（负数为易伤，最低 -1）
+
+	Before float64 // 减免前伤害
+	After  float64 // 减免后伤害
+}
+
+func (r *MitigationResult) String() string {
+	return fmt.Sprintf("type=%d def=%.1f pen=%.2f eff=%.1f defRed=%.3f bonus=%.2f red=%.2f %.1f->%.1f",
+		r.DamageType, r.Defense, r.Penetration, r.EffectiveDefense, r.DefenseReduction, r.BonusPct, r.ReductionPct, r.Before, r.After)
+}
+
+// DamageTypeOf 获取公式的伤害类型（配置校验保证为物理/法术/真实之一）。
+func DamageTypeOf(f *config.DamageFormula) conf.DamageType {
+	return conf.DamageType(f.DamageType)
+}
+
+// Mitigate 按伤害类型对伤害进行减免，真实伤害不做任何处理。
+// 计算顺序：
+//  1. 穿透比例 = 攻击者穿透属性(万分比) + 公式 IgnoreArmorPct，上限 100%
+//  2. 有效防御 = 目标防御 × (1 - 穿透比例)
+//  3. 防御减伤 = 有效防御 / (有效防御 + K)，上限 MaxReduction
+//  4. 伤害 × (1 - 防御减伤) × (1 + 攻击者增伤) × (1 - 目标减伤)，目标减伤上限 MaxReduction
+func Mitigate(table *MitigationTable, f *config.DamageFormula, attacker, defender FormulaUnit, damage float64) *MitigationResult {
+	if table == nil {
+		table = &DefaultMitigationTable
+	}
+
+	r := &MitigationResult{
+		DamageType: DamageTypeOf(f),
+		Before:     damage,
+		After:      damage,
+	}
+
+	var attrs mitigationAttrs
+	switch r.DamageType {
+	case conf.DamageType_Physical:
+		attrs = physicalMitigationAttrs
+	case conf.DamageType_Magic:
+		attrs = magicMitigationAttrs
+	default:
+		return r
+	}
+
+	r.Defense = max(defender.attr(attrs.defense), 0)
+	r.Penetration = min(max(attacker.attr(attrs.penetration)/RatePrecision+f.IgnoreArmorPct, 0), 1)
+	r.EffectiveDefense = r.Defense * (1 - r.Penetration)
+	if r.EffectiveDefense > 0 && table.DefenseConstant > 0 {
+		r.DefenseReduction = min(r.EffectiveDefense/(r.EffectiveDefense+table.DefenseConstant), table.MaxReduction)
+	}
+	r.BonusPct = max(attacker.attr(attrs.bonus)/RatePrecision, 0)
+	r.ReductionPct = min(max(defender.attr(attrs.reduction)/RatePrecision, -1), table.MaxReduction)
+
+	r.After = damage * (1 - r.DefenseReduction) * (1 + r.BonusPct) * (1 - r.ReductionPct)
+	return r
+}
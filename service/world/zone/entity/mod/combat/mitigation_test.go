@@ -0,0 +1,91 @@
+package combat
+
+import (
+	"math"
+	"testing"
+
+	"server/data"
+	"server/data/conf"
+	"server/data/enum"
+	config "server/data/xls"
+)
+
+func TestMitigate(t *testing.T) {
+	attackerAttrs := data.Attrs{
+		{Type: enum.AttrType_PhysicalDefensePenetrationRate, Val: 5000},
+		{Type: enum.AttrType_PhyDamageBonus, Val: 2000},
+		{Type: enum.AttrType_MagicDamageBonus, Val: 1000},
+	}
+	defenderAttrs := data.Attrs{
+		{Type: enum.AttrType_PhyDefense, Val: 2000},
+		{Type: enum.AttrType_PhyDamageReduction, Val: 1000},
+		{Type: enum.AttrType_MagicDefense, Val: 1000},
+		{Type: enum.AttrType_MagicDamageReduction, Val: 9000},
+	}
+	attacker := FormulaUnit{Attrs: &attackerAttrs}
+	defender := FormulaUnit{Attrs: &defenderAttrs}
+	table := &MitigationTable{DefenseConstant: 1000, MaxReduction: 0.8}
+
+	cases := []struct {
+		name    string
+		f       config.DamageFormula
+		damage  float64
+		after   float64
+		defense float64
+	}{
+		// 有效防御 2000*(1-0.5)=1000，防御减伤 0.5；1000*0.5*1.2*0.9 = 540
+		{"physical", config.DamageFormula{DamageType: int(conf.DamageType_Physical)}, 1000, 540, 1000},
+		// 穿透 0.5 + IgnoreArmorPct 0.6 封顶 1：1000*1.2*0.9 = 1080
+		{"ignore armor", config.DamageFormula{DamageType: int(conf.DamageType_Physical), IgnoreArmorPct: 0.6}, 1000, 1080, 0},
+		// 防御减伤 0.5，减伤 0.9 封顶 0.8：1000*0.5*1.1*0.2 = 110
+		{"magic", config.DamageFormula{DamageType: int(conf.DamageType_Magic)}, 1000, 110, 1000},
+		{"true", config.DamageFormula{DamageType: int(conf.DamageType_True)}, 1000, 1000, 0},
+	}
+
+	for _, c := range cases {
+		r := Mitigate(table, &c.f, attacker, defender, c.damage)
+		if math.Abs(r.After-c.after) > 1e-9 {
+			t.Errorf("%s: expected %v, got %v (%s)", c.name, c.after, r.After, r)
+		}
+		if r.EffectiveDefense != c.defense {
+			t.Errorf("%s: expected effective defense %v, got %v", c.name, c.defense, r.EffectiveDefense)
+		}
+	}
+}
+
+func TestMitigate_DefenseCap(t *testing.T) {
+	defenderAttrs := data.Attrs{{Type: enum.AttrType_PhyDefense, Val: 1000000}}
+	f := &config.DamageFormula{DamageType: int(conf.DamageType_Physical)}
+	r := Mitigate(nil, f, FormulaUnit{}, FormulaUnit{Attrs: &defenderAttrs}, 1000)
+	if r.DefenseReduction != DefaultMitigationTable.MaxReduction {
+		t.Errorf("Expected defense reduction capped at %v, got %v", DefaultMitigationTable.MaxReduction, r.DefenseReduction)
+	}
+}
+
+func TestCombatManager_CalculateDamage_TrueDamage(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newTestEntity(z, 0, 0, nil)
+	target := newTestEntity(z, 1, 0, map[enum.AttrType]int64{enum.AttrType_PhyDefense: 5000, enum.AttrType_MagicDefense: 5000})
+	cm := GetCombatManager(caster)
+	cm.SetRand(fixedRand(0))
+
+	// 真实伤害公式：不会未命中，也不受防御影响
+	if r := cm.CalculateDamage(nil, caster, target, 1009); r.Damage != 1000 {
+		t.Errorf("Expected 1000 true damage, got %s", r)
+	}
+}
+
+func TestCombatManager_CalculateDamage_DefenderMitigationTable(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newTestEntity(z, 0, 0, nil)
+	target := newTestEntity(z, 1, 0, map[enum.AttrType]int64{enum.AttrType_PhyDefense: 1000})
+	cm := GetCombatManager(caster)
+	cm.SetRand(fixedRand(RatePrecision - 1))
+	cm.SetMitigationTable(&MitigationTable{DefenseConstant: 9000, MaxReduction: 0.8})
+	GetCombatManager(target).SetMitigationTable(&MitigationTable{DefenseConstant: 1000, MaxReduction: 0.8})
+
+	// 使用目标的减免参数：1000 / (1000 + 1000) = 0.5
+	if r := cm.CalculateDamage(nil, caster, target, 1004); r.Mitigation.DefenseReduction != 0.5 {
+		t.Errorf("Expected defender mitigation table, got %s", r.Mitigation)
+	}
+}
@@ -0,0 +1,134 @@
+package combat
+
+import (
+	"server/data"
+	"server/data/conf"
+	"server/lib/uid"
+	"server/service/world/zone/izone"
+)
+
+// maxOwnerDepth 为查找召唤者的最大层数（召唤物的召唤物），防止配置错误导致死循环。
+const maxOwnerDepth = 4
+
+// Relation 为两个实体之间的敌友关系。
+type Relation int32
+
+const (
+	Relation_Invalid Relation = 0
+	Relation_Self    Relation = 1 // 自身
+	Relation_Ally    Relation = 2 // 友方
+	Relation_Enemy   Relation = 3 // 敌方
+	Relation_Neutral Relation = 4 // 中立
+)
+
+// GetFaction 获取本实体的阵营数据。
+func (m *CombatManager) GetFaction() data.Faction {
+	return m.faction
+}
+
+// SetCamp 设置阵营ID。
+func (m *CombatManager) SetCamp(camp int32) {
+	m.faction.Camp = camp
+}
+
+// SetTeam 设置队伍ID（0 表示离开队伍）。
+func (m *CombatManager) SetTeam(teamId int64) {
+	m.faction.TeamId = teamId
+}
+
+// SetPvp 设置 PvP 开关。
+func (m *CombatManager) SetPvp(pvp bool) {
+	m.faction.Pvp = pvp
+}
+
+// SetOwner 设置召唤者ID（uid.Zero 表示不再是召唤物）。
+func (m *CombatManager) SetOwner(ownerId uid.Uid) {
+	m.faction.OwnerId = ownerId
+}
+
+// RelationTo 获取本实体对 other 的敌友关系。
+func (m *CombatManager) RelationTo(other izone.IEntity) Relation {
+	return RelationOf(m.owner, other)
+}
+
+// RelationOf 获取 a 对 b 的敌友关系：
+//  1. 同一实体为自身
+//  2. 召唤物按召唤者判定，同一召唤者（含召唤者本身）的实体互为友方
+//  3. 同队为友方
+//  4. 任一方无阵营为中立
+//  5. 不同阵营为敌方
+//  6. 同阵营时双方均开启 PvP 为敌方，否则为友方
+//
+// 未挂载战斗模块的实体与任何实体中立。
+func RelationOf(a, b izone.IEntity) Relation {
+	if a == nil || b == nil {
+		return Relation_Invalid
+	}
+	if a == b || a.GetId() == b.GetId() {
+		return Relation_Self
+	}
+
+	ma, mb := masterOf(a), masterOf(b)
+	if ma == nil || mb == nil {
+		return Relation_Neutral
+	}
+	if ma == mb {
+		return Relation_Ally
+	}
+
+	fa, fb := ma.faction, mb.faction
+	if fa.TeamId != 0 && fa.TeamId == fb.TeamId {
+		return Relation_Ally
+	}
+	if fa.Camp == 0 || fb.Camp == 0 {
+		return Relation_Neutral
+	}
+	if fa.Camp != fb.Camp {
+		return Relation_Enemy
+	}
+	if fa.Pvp && fb.Pvp {
+		return Relation_Enemy
+	}
+	return Relation_Ally
+}
+
+// masterOf 沿召唤关系找到最上层的召唤者的战斗模块，召唤者不在区域内时使用召唤物自身。
+func masterOf(e izone.IEntity) *CombatManager {
+	m := GetCombatManager(e)
+	if m == nil {
+		return nil
+	}
+	z := e.GetZone()
+	for range maxOwnerDepth {
+		if z == nil || !m.faction.OwnerId.IsValid() {
+			break
+		}
+		owner, ok := z.GetEntity(m.faction.OwnerId)
+		if !ok {
+			break
+		}
+		om := GetCombatManager(owner)
+		if om == nil || om == m {
+			break
+		}
+		m = om
+	}
+	return m
+}
+
+// MatchRelation 判断敌友关系是否满足选择器的目标关系：
+// 友方选择器包含自身，敌方选择器只包含敌方，All 包含所有实体。
+func MatchRelation(want conf.TargetRelation, r Relation) bool {
+	switch want {
+	case conf.TargetRelation_Self:
+		return r == Relation_Self
+	case conf.TargetRelation_Ally:
+		return r == Relation_Self || r == Relation_Ally
+	case conf.TargetRelation_Enemy:
+		return r == Relation_Enemy
+	case conf.TargetRelation_All:
+		return r != Relation_Invalid
+	default:
+		return false
+	}
+}
@@ -0,0 +1,138 @@
+package combat
+
+import (
+	"testing"
+
+	"server/data"
+	"server/data/conf"
+	"server/lib/uid"
+	"server/pb"
+	"server/service/world/zone/entity/mod/combat/skill"
+)
+
+// newFactionEntity 在区域内创建一个带阵营数据的测试实体。
+func newFactionEntity(z *testZone, x float64, faction data.Faction) *testEntity {
+	e := newEntityAt(x, 0)
+	e.Init(z, data.EntityInitData{Attrs: &data.Attrs{}, Faction: faction})
+	return e
+}
+
+func TestRelationOf(t *testing.T) {
+	z := newTestZone(nil)
+	player := newFactionEntity(z, 0, data.Faction{Camp: 1, TeamId: 7})
+	mate := newFactionEntity(z, 0, data.Faction{Camp: 1, TeamId: 7, Pvp: true})
+	citizen := newFactionEntity(z, 0, data.Faction{Camp: 1})
+	monster := newFactionEntity(z, 0, data.Faction{Camp: 2})
+	critter := newFactionEntity(z, 0, data.Faction{})
+	pet := newFactionEntity(z, 0, data.Faction{OwnerId: player.GetId()})
+	petOfPet := newFactionEntity(z, 0, data.Faction{Camp: 2, OwnerId: pet.GetId()})
+	orphan := newFactionEntity(z, 0, data.Faction{Camp: 2, OwnerId: 424242})
+
+	cases := []struct {
+		name string
+		a, b *testEntity
+		want Relation
+	}{
+		{"self", player, player, Relation_Self},
+		{"same team", player, mate, Relation_Ally},
+		{"same camp", player, citizen, Relation_Ally},
+		{"different camp", player, monster, Relation_Enemy},
+		{"no camp", player, critter, Relation_Neutral},
+		{"no camp reverse", critter, monster, Relation_Neutral},
+		{"owner and pet", player, pet, Relation_Ally},
+		{"pet follows owner", pet, monster, Relation_Enemy},
+		{"pet follows owner team", mate, pet, Relation_Ally},
+		{"nested summon", petOfPet, player, Relation_Ally},
+		{"nested summon enemy", petOfPet, monster, Relation_Enemy},
+		{"owner missing", orphan, monster, Relation_Ally},
+	}
+	for _, c := range cases {
+		if got := RelationOf(c.a, c.b); got != c.want {
+			t.Errorf("%s: expected %d, got %d", c.name, c.want, got)
+		}
+	}
+}
+
+func TestRelationOf_Pvp(t *testing.T) {
+	z := newTestZone(nil)
+	a := newFactionEntity(z, 0, data.Faction{Camp: 1})
+	b := newFactionEntity(z, 0, data.Faction{Camp: 1})
+	ma, mb := GetCombatManager(a), GetCombatManager(b)
+
+	ma.SetPvp(true)
+	if r := RelationOf(a, b); r != Relation_Ally {
+		t.Errorf("Expected ally when only one side is pvp, got %d", r)
+	}
+	mb.SetPvp(true)
+	if r := RelationOf(a, b); r != Relation_Enemy {
+		t.Errorf("Expected enemy when both sides are pvp, got %d", r)
+	}
+	ma.SetTeam(3)
+	mb.SetTeam(3)
+	if r := RelationOf(a, b); r != Relation_Ally {
+		t.Errorf("Expected teammates to stay allies, got %d", r)
+	}
+}
+
+func TestMatchRelation(t *testing.T) {
+	cases := []struct {
+		want conf.TargetRelation
+		r    Relation
+		ok   bool
+	}{
+		{conf.TargetRelation_Self, Relation_Self, true},
+		{conf.TargetRelation_Self, Relation_Ally, false},
+		{conf.TargetRelation_Ally, Relation_Self, true},
+		{conf.TargetRelation_Ally, Relation_Ally, true},
+		{conf.TargetRelation_Ally, Relation_Neutral, false},
+		{conf.TargetRelation_Enemy, Relation_Enemy, true},
+		{conf.TargetRelation_Enemy, Relation_Self, false},
+		{conf.TargetRelation_Enemy, Relation_Neutral, false},
+		{conf.TargetRelation_All, Relation_Neutral, true},
+		{conf.TargetRelation_All, Relation_Invalid, false},
+		{conf.TargetRelation_Invalid, Relation_Enemy, false},
+	}
+	for _, c := range cases {
+		if got := MatchRelation(c.want, c.r); got != c.ok {
+			t.Errorf("MatchRelation(%d, %d): expected %v, got %v", c.want, c.r, c.ok, got)
+		}
+	}
+}
+
+func TestSkillManager_SelectByRelation(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newFactionEntity(z, 0, data.Faction{Camp: 1})
+	ally := newFactionEntity(z, 2, data.Faction{Camp: 1})
+	enemy := newFactionEntity(z, 3, data.Faction{Camp: 2})
+	neutral := newFactionEntity(z, 4, data.Faction{})
+	sm := GetCombatManager(caster).GetSkillManager()
+	ctx := skill.NewSkillContext(caster, nil, 1)
+
+	area := func(rel conf.TargetRelation) []uid.Uid {
+		cfg := &conf.TargetCfg{Relation: rel, Mode: conf.TargetMode_NoTarget, Shape: conf.ShapeType_Circle, Radius: 10, IncludeCaster: true}
+		var ids []uid.Uid
+		for _, e := range sm.selectTargets(cfg, ctx) {
+			ids = append(ids, e.GetId())
+		}
+		return ids
+	}
+
+	if got := area(conf.TargetRelation_Ally); len(got) != 2 || got[0] != caster.GetId() || got[1] != ally.GetId() {
+		t.Errorf("Expected caster and ally, got %v", got)
+	}
+	if got := area(conf.TargetRelation_Enemy); len(got) != 1 || got[0] != enemy.GetId() {
+		t.Errorf("Expected enemy only, got %v", got)
+	}
+	if got := area(conf.TargetRelation_All); len(got) != 4 || got[3] != neutral.GetId() {
+		t.Errorf("Expected all entities, got %v", got)
+	}
+
+	// 单体敌方技能不能锁定友方
+	unit := &conf.TargetCfg{Relation: conf.TargetRelation_Enemy, Mode: conf.TargetMode_Unit, Shape: conf.ShapeType_Single}
+	if r := sm.checkTarget(unit, &pb.ReqCastSkill{LockTarget: int64(ally.GetId())}); r != skill.CastResult_InvalidTarget {
+		t.Errorf("Expected InvalidTarget on ally, got %s", r)
+	}
+	if r := sm.checkTarget(unit, &pb.ReqCastSkill{LockTarget: int64(enemy.GetId())}); !r.Ok() {
+		t.Errorf("Expected enemy target to be valid, got %s", r)
+	}
+}
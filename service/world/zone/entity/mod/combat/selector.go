@@ -0,0 +1,143 @@
+package combat
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"server/data/conf"
+	"server/pb"
+	"server/service/world/zone/entity/mod/combat/skill"
+	"server/service/world/zone/izone"
+)
+
+// filterTargets 对候选目标依次执行：
+//  1. 过滤：区域选取默认排除施法者（IncludeCaster 时保留），其余条件见 matchTarget
+//  2. 排序：按 Sort 稳定排序，相同时保持选取顺序
+//  3. 截断：保留前 MaxCount 个目标
+func (m *SkillManager) filterTargets(cfg *conf.TargetCfg, center *pb.Vector, targets []izone.IEntity) []izone.IEntity {
+	excludeCaster := cfg.Shape != conf.ShapeType_Single && cfg.Relation != conf.TargetRelation_Self && !cfg.IncludeCaster
+	targets = slices.DeleteFunc(targets, func(e izone.IEntity) bool {
+		if e == nil || (excludeCaster && e.GetId() == m.owner.GetId()) {
+			return true
+		}
+		return !m.matchTarget(cfg, e)
+	})
+
+	m.sortTargets(cfg.Sort, center, targets)
+
+	if cfg.MaxCount > 0 && len(targets) > int(cfg.MaxCount) {
+		targets = targets[:cfg.MaxCount]
+	}
+	return targets
+}
+
+// matchTarget 判断实体是否满足选择器的过滤条件：存活（IncludeDead 时除外）、敌友关系、生命区间与 Buff 要求。
+func (m *SkillManager) matchTarget(cfg *conf.TargetCfg, e izone.IEntity) bool {
+	if !cfg.IncludeDead && isDead(e) {
+		return false
+	}
+	if !MatchRelation(cfg.Relation, RelationOf(m.owner, e)) {
+		return false
+	}
+	if !matchHp(cfg, e) {
+		return false
+	}
+	if cfg.RequireBuffId != 0 && !HasBuff(e, cfg.RequireBuffId) {
+		return false
+	}
+	if cfg.ExcludeBuffId != 0 && HasBuff(e, cfg.ExcludeBuffId) {
+		return false
+	}
+	return true
+}
+
+// matchHp 判断实体生命是否在选择器的生命区间内，未挂载战斗模块的实体不满足任何生命条件。
+func matchHp(cfg *conf.TargetCfg, e izone.IEntity) bool {
+	if cfg.MinHp <= 0 && cfg.MaxHp <= 0 && cfg.MinHpPct <= 0 && cfg.MaxHpPct <= 0 {
+		return true
+	}
+	cm := GetCombatManager(e)
+	if cm == nil {
+		return false
+	}
+	if cfg.MinHp > 0 && cm.hp < cfg.MinHp {
+		return false
+	}
+	if cfg.MaxHp > 0 && cm.hp > cfg.MaxHp {
+		return false
+	}
+	pct := cm.hpPct()
+	if cfg.MinHpPct > 0 && pct < cfg.MinHpPct {
+		return false
+	}
+	if cfg.MaxHpPct > 0 && pct > cfg.MaxHpPct {
+		return false
+	}
+	return true
+}
+
+// sortTargets 按排序方式原地排序目标，距离类排序以 center 为基准（为空时不排序）。
+// 未挂载战斗模块的实体在生命/仇恨类排序中排在最后。
+func (m *SkillManager) sortTargets(sort conf.TargetSort, center *pb.Vector, targets []izone.IEntity) {
+	if len(targets) < 2 {
+		return
+	}
+
+	switch sort {
+	case conf.TargetSort_Nearest, conf.TargetSort_Farthest:
+		if center == nil {
+			return
+		}
+		dist := func(e izone.IEntity) float64 {
+			if pos := e.GetPos(); pos != nil {
+				return center.Sub2D(pos).LengthSq2D()
+			}
+			return math.MaxFloat64
+		}
+		slices.SortStableFunc(targets, func(a, b izone.IEntity) int {
+			if sort == conf.TargetSort_Farthest {
+				return cmp.Compare(dist(b), dist(a))
+			}
+			return cmp.Compare(dist(a), dist(b))
+		})
+	case conf.TargetSort_LowestHp:
+		sortByCombat(targets, func(cm *CombatManager) float64 { return float64(cm.hp) })
+	case conf.TargetSort_HighestHp:
+		sortByCombat(targets, func(cm *CombatManager) float64 { return -float64(cm.hp) })
+	case conf.TargetSort_LowestHpPct:
+		sortByCombat(targets, func(cm *CombatManager) float64 { return cm.hpPct() })
+	case conf.TargetSort_HighestThreat:
+		sortByCombat(targets, func(cm *CombatManager) float64 {
+			return -float64(m.threat.GetThreat(cm.owner.GetId()))
+		})
+	case conf.TargetSort_Random:
+		rng := m.rng
+		if rng == nil {
+			rng = globalRand{}
+		}
+		for i := len(targets) - 1; i > 0; i-- {
+			j := rng.IntN(i + 1)
+			targets[i], targets[j] = targets[j], targets[i]
+		}
+	}
+}
+
+// sortByCombat 按战斗模块数据升序稳定排序，未挂载战斗模块的实体排在最后。
+func sortByCombat(targets []izone.IEntity, key func(cm *CombatManager) float64) {
+	value := func(e izone.IEntity) float64 {
+		if cm := GetCombatManager(e); cm != nil {
+			return key(cm)
+		}
+		return math.Inf(1)
+	}
+	slices.SortStableFunc(targets, func(a, b izone.IEntity) int {
+		return cmp.Compare(value(a), value(b))
+	})
+}
+
+// HasBuff 判断实体身上是否有指定 Buff，未挂载 Buff 模块的实体没有 Buff。
+func HasBuff(e izone.IEntity, buffId int64) bool {
+	b := skill.BuffOf(e)
+	return b != nil && b.HasBuff(buffId)
+}
@@ -0,0 +1,166 @@
+package combat
+
+import (
+	"slices"
+	"testing"
+
+	"server/data"
+	"server/data/conf"
+	"server/data/enum"
+	"server/lib/uid"
+	"server/pb"
+	"server/service/world/zone/entity/mod/combat/skill"
+	"server/service/world/zone/izone"
+)
+
+// newSelectorUnit 创建指定阵营、位置与生命的测试实体。
+func newSelectorUnit(z *testZone, x float64, camp int32, hp, maxHp int64) *testEntity {
+	e := newEntityAt(x, 0)
+	attrs := data.Attrs{{Type: enum.AttrType_MaxHp, Val: maxHp}}
+	e.Init(z, data.EntityInitData{Attrs: &attrs, Faction: data.Faction{Camp: camp}})
+	GetCombatManager(e).hp = hp
+	return e
+}
+
+func idsOf(targets []izone.IEntity) []uid.Uid {
+	ids := make([]uid.Uid, 0, len(targets))
+	for _, e := range targets {
+		ids = append(ids, e.GetId())
+	}
+	return ids
+}
+
+func TestSkillManager_SelectorFilters(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newSelectorUnit(z, 0, 1, 100, 100)
+	a := newSelectorUnit(z, 1, 2, 900, 1000) // 90%
+	b := newSelectorUnit(z, 2, 2, 150, 1000) // 15%
+	c := newSelectorUnit(z, 3, 2, 100, 200)  // 50%
+	sm := GetCombatManager(caster).GetSkillManager()
+	ctx := skill.NewSkillContext(caster, nil, 1)
+	base := conf.TargetCfg{Relation: conf.TargetRelation_Enemy, Mode: conf.TargetMode_NoTarget, Shape: conf.ShapeType_Circle, Radius: 10}
+
+	cases := []struct {
+		name string
+		edit func(cfg *conf.TargetCfg)
+		want []uid.Uid
+	}{
+		{"no filter", func(cfg *conf.TargetCfg) {}, []uid.Uid{a.GetId(), b.GetId(), c.GetId()}},
+		{"min hp", func(cfg *conf.TargetCfg) { cfg.MinHp = 150 }, []uid.Uid{a.GetId(), b.GetId()}},
+		{"max hp", func(cfg *conf.TargetCfg) { cfg.MaxHp = 150 }, []uid.Uid{b.GetId(), c.GetId()}},
+		{"min hp pct", func(cfg *conf.TargetCfg) { cfg.MinHpPct = 0.5 }, []uid.Uid{a.GetId(), c.GetId()}},
+		{"max hp pct", func(cfg *conf.TargetCfg) { cfg.MaxHpPct = 0.5 }, []uid.Uid{b.GetId(), c.GetId()}},
+		{"hp pct range", func(cfg *conf.TargetCfg) { cfg.MinHpPct, cfg.MaxHpPct = 0.2, 0.6 }, []uid.Uid{c.GetId()}},
+		{"max count", func(cfg *conf.TargetCfg) { cfg.MaxCount = 2 }, []uid.Uid{a.GetId(), b.GetId()}},
+		{"farthest", func(cfg *conf.TargetCfg) { cfg.Sort = conf.TargetSort_Farthest }, []uid.Uid{c.GetId(), b.GetId(), a.GetId()}},
+		{"lowest hp", func(cfg *conf.TargetCfg) { cfg.Sort = conf.TargetSort_LowestHp }, []uid.Uid{c.GetId(), b.GetId(), a.GetId()}},
+		{"highest hp", func(cfg *conf.TargetCfg) { cfg.Sort = conf.TargetSort_HighestHp }, []uid.Uid{a.GetId(), b.GetId(), c.GetId()}},
+		{"lowest hp pct", func(cfg *conf.TargetCfg) { cfg.Sort = conf.TargetSort_LowestHpPct }, []uid.Uid{b.GetId(), c.GetId(), a.GetId()}},
+		{"sort then truncate", func(cfg *conf.TargetCfg) { cfg.Sort, cfg.MaxCount = conf.TargetSort_LowestHpPct, 1 }, []uid.Uid{b.GetId()}},
+	}
+	for _, tc := range cases {
+		cfg := base
+		tc.edit(&cfg)
+		if got := idsOf(sm.selectTargets(&cfg, ctx)); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestSkillManager_SelectorBuffFilter(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newSelectorUnit(z, 0, 1, 100, 100)
+	burning := newSelectorUnit(z, 1, 2, 100, 100)
+	clean := newSelectorUnit(z, 2, 2, 100, 100)
+	sm := GetCombatManager(caster).GetSkillManager()
+	ctx := skill.NewSkillContext(caster, nil, 1)
+
+	// Buff 存放在目标身上
+	skill.CreateEffect(conf.EffectCfg{Type: conf.EffectType_ApplyAura, RefId: 2001}).Begin(ctx, caster, []izone.IEntity{burning})
+	if GetCombatManager(caster).GetEffectManager().GetActiveEffectCount() != 0 {
+		t.Error("Expected no aura runtime on the caster")
+	}
+
+	if !HasBuff(burning, 2001) || HasBuff(clean, 2001) || HasBuff(burning, 2002) {
+		t.Error("Unexpected HasBuff result")
+	}
+
+	cfg := conf.TargetCfg{Relation: conf.TargetRelation_Enemy, Mode: conf.TargetMode_NoTarget, Shape: conf.ShapeType_Circle, Radius: 10, RequireBuffId: 2001}
+	if got := idsOf(sm.selectTargets(&cfg, ctx)); !slices.Equal(got, []uid.Uid{burning.GetId()}) {
+		t.Errorf("Expected burning target only, got %v", got)
+	}
+	cfg.RequireBuffId, cfg.ExcludeBuffId = 0, 2001
+	if got := idsOf(sm.selectTargets(&cfg, ctx)); !slices.Equal(got, []uid.Uid{clean.GetId()}) {
+		t.Errorf("Expected clean target only, got %v", got)
+	}
+}
+
+func TestSkillManager_SelectorThreatAndRandom(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newSelectorUnit(z, 0, 1, 100, 100)
+	a := newSelectorUnit(z, 1, 2, 100, 100)
+	b := newSelectorUnit(z, 2, 2, 100, 100)
+	c := newSelectorUnit(z, 3, 2, 100, 100)
+	cm := GetCombatManager(caster)
+	sm := cm.GetSkillManager()
+	ctx := skill.NewSkillContext(caster, nil, 1)
+	cfg := conf.TargetCfg{Relation: conf.TargetRelation_Enemy, Mode: conf.TargetMode_NoTarget, Shape: conf.ShapeType_Circle, Radius: 10, Sort: conf.TargetSort_HighestThreat}
+
+	cm.GetThreatTable().AddThreat(b.GetId(), 300, 0)
+	cm.GetThreatTable().AddThreat(c.GetId(), 100, 0)
+	if got := idsOf(sm.selectTargets(&cfg, ctx)); !slices.Equal(got, []uid.Uid{b.GetId(), c.GetId(), a.GetId()}) {
+		t.Errorf("Expected threat order b, c, a, got %v", got)
+	}
+
+	// 固定掷骰 0：每次与第一个交换，[a b c] → [b c a]
+	cm.SetRand(fixedRand(0))
+	cfg.Sort = conf.TargetSort_Random
+	if got := idsOf(sm.selectTargets(&cfg, ctx)); !slices.Equal(got, []uid.Uid{b.GetId(), c.GetId(), a.GetId()}) {
+		t.Errorf("Expected shuffled order b, c, a, got %v", got)
+	}
+}
+
+func TestSkillManager_ConfiguredSelectors(t *testing.T) {
+	tables := loadTestTables()
+	z := newTestZone(tables)
+	caster := newSelectorUnit(z, 0, 1, 100, 100)
+	newSelectorUnit(z, 5, 1, 300, 1000) // 范围内但生命较高
+	hurt := newSelectorUnit(z, 30, 1, 200, 1000)
+	newSelectorUnit(z, 50, 1, 10, 1000) // 生命最低但超出范围
+	sm := GetCombatManager(caster).GetSkillManager()
+	ctx := skill.NewSkillContext(caster, nil, 1)
+
+	// 血量最低友方：40 码内生命最低的友方，不需要锁定目标
+	lowest := tables.GetTargetCfg(110)
+	if r := sm.checkTarget(lowest, &pb.ReqCastSkill{}); !r.Ok() {
+		t.Errorf("Expected smart selector without lock target, got %s", r)
+	}
+	if got := idsOf(sm.selectTargets(lowest, ctx)); !slices.Equal(got, []uid.Uid{hurt.GetId()}) {
+		t.Errorf("Expected lowest hp ally in range, got %v", got)
+	}
+
+	// 最近 5 个敌人
+	enemies := make([]uid.Uid, 0)
+	for i := range 7 {
+		enemies = append(enemies, newSelectorUnit(z, float64(20-i), 2, 100, 100).GetId())
+	}
+	nearest := tables.GetTargetCfg(111)
+	ctx = skill.NewSkillContext(caster, &pb.ReqCastSkill{Pos: pb.NewVector(10, 0, 0)}, 1)
+	got := idsOf(sm.selectTargets(nearest, ctx))
+	want := []uid.Uid{enemies[6], enemies[5], enemies[4], enemies[3], enemies[2]}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected 5 nearest enemies %v, got %v", want, got)
+	}
+
+	// 斩杀目标：锁定目标生命需低于 20%
+	execute := tables.GetTargetCfg(112)
+	target := newSelectorUnit(z, 3, 2, 300, 1000)
+	req := &pb.ReqCastSkill{LockTarget: int64(target.GetId())}
+	if r := sm.checkTarget(execute, req); r != skill.CastResult_InvalidTarget {
+		t.Errorf("Expected InvalidTarget above 20%% hp, got %s", r)
+	}
+	GetCombatManager(target).hp = 200
+	if r := sm.checkTarget(execute, req); !r.Ok() {
+		t.Errorf("Expected valid execute target at 20%% hp, got %s", r)
+	}
+}
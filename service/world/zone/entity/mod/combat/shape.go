@@ -0,0 +1,77 @@
+package combat
+
+import (
+	"math"
+
+	"server/data/conf"
+	"server/pb"
+	"server/service/world/zone/spatial"
+)
+
+// shapeEpsilon 为形状边界判定的浮点容差，边界上的点视为在范围内。
+const shapeEpsilon = spatial.Epsilon
+
+// InShape 判断点 p 是否在以 center 为原点、facing 为朝向的区域内（只考虑 XOY 平面）。
+//   - Circle: 到 center 的距离不超过 Radius
+//   - Cone:   距离不超过 Length，且与朝向的夹角不超过 Angle/2（center 上的点视为命中）
+//   - Rect:   从 center 沿朝向延伸 Length、左右各 Width/2 的矩形
+//   - Ring:   距离在 [InnerRadius, Radius] 内
+//
+// facing 为空或长度为 0 时使用 ForwardVector。
+func InShape(cfg *conf.TargetCfg, center, facing, p *pb.Vector) bool {
+	if cfg == nil || center == nil || p == nil {
+		return false
+	}
+	d := p.Sub2D(center)
+	distSq := d.LengthSq2D()
+
+	switch cfg.Shape {
+	case conf.ShapeType_Circle:
+		return inRadius(distSq, float64(cfg.Radius))
+	case conf.ShapeType_Ring:
+		inner := float64(cfg.InnerRadius)
+		return inRadius(distSq, float64(cfg.Radius)) && distSq >= inner*inner-shapeEpsilon
+	case conf.ShapeType_Cone:
+		return spatial.InCone(center, facing, p, float64(cfg.Length), float64(cfg.Angle))
+	case conf.ShapeType_Rect:
+		f := facingOf(facing)
+		forward := d.Dot2D(f)
+		side := d.Dot2D(f.Orthogonal2D())
+		return forward >= -shapeEpsilon && forward <= float64(cfg.Length)+shapeEpsilon &&
+			math.Abs(side) <= float64(cfg.Width)/2+shapeEpsilon
+	default:
+		return false
+	}
+}
+
+// boundingRadius 获取形状到中心的最大距离，用于空间索引的粗筛。
+func boundingRadius(cfg *conf.TargetCfg) float64 {
+	switch cfg.Shape {
+	case conf.ShapeType_Circle, conf.ShapeType_Ring:
+		return float64(cfg.Radius)
+	case conf.ShapeType_Cone:
+		return float64(cfg.Length)
+	case conf.ShapeType_Rect:
+		return math.Hypot(float64(cfg.Length), float64(cfg.Width)/2)
+	default:
+		return 0
+	}
+}
+
+// inRadius 判断平方距离是否在半径内（含边界）。
+func inRadius(distSq, radius float64) bool {
+	return radius > 0 && distSq <= radius*radius+shapeEpsilon
+}
+
+// facingOf 获取单位朝向向量。
+func facingOf(facing *pb.Vector) *pb.Vector {
+	if facing == nil {
+		return pb.ForwardVector
+	}
+	return facing.Norm2D()
+}
+
+// DirToVector 将实体朝向（与 ForwardVector 在 XOY 平面的角度）转换为单位向量。
+func DirToVector(dir int32) *pb.Vector {
+	return pb.ForwardVector.RotateAngle2D(float64(dir))
+}
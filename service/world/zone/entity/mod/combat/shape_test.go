@@ -0,0 +1,115 @@
+package combat
+
+import (
+	"math"
+	"testing"
+
+	"server/data/conf"
+	"server/pb"
+	"server/service/world/zone/entity/mod/combat/skill"
+)
+
+func TestInShape(t *testing.T) {
+	circle := &conf.TargetCfg{Shape: conf.ShapeType_Circle, Radius: 5}
+	ring := &conf.TargetCfg{Shape: conf.ShapeType_Ring, InnerRadius: 5, Radius: 10}
+	cone := &conf.TargetCfg{Shape: conf.ShapeType_Cone, Angle: 90, Length: 10}
+	rect := &conf.TargetCfg{Shape: conf.ShapeType_Rect, Width: 4, Length: 10}
+	origin := pb.NewVector(0, 0, 0)
+	up := pb.NewVector(0, 1, 0)
+	diag := math.Sqrt2 / 2 * 10
+
+	cases := []struct {
+		name   string
+		cfg    *conf.TargetCfg
+		facing *pb.Vector
+		p      *pb.Vector
+		want   bool
+	}{
+		{"circle edge", circle, nil, pb.NewVector(3, 4, 0), true},
+		{"circle outside", circle, nil, pb.NewVector(3, 4.01, 0), false},
+		{"circle zero radius", &conf.TargetCfg{Shape: conf.ShapeType_Circle}, nil, origin, false},
+
+		{"ring inner edge", ring, nil, pb.NewVector(5, 0, 0), true},
+		{"ring outer edge", ring, nil, pb.NewVector(0, -10, 0), true},
+		{"ring hole", ring, nil, pb.NewVector(4.99, 0, 0), false},
+		{"ring outside", ring, nil, pb.NewVector(10.01, 0, 0), false},
+
+		{"cone apex", cone, nil, origin, true},
+		{"cone forward edge", cone, nil, pb.NewVector(10, 0, 0), true},
+		{"cone too far", cone, nil, pb.NewVector(10.01, 0, 0), false},
+		{"cone angle edge", cone, nil, pb.NewVector(diag, diag, 0), true},
+		{"cone outside angle", cone, nil, pb.NewVector(5, 5.1, 0), false},
+		{"cone behind", cone, nil, pb.NewVector(-1, 0, 0), false},
+		{"cone facing up", cone, up, pb.NewVector(0, 8, 0), true},
+		{"cone facing up miss", cone, up, pb.NewVector(8, 0, 0), false},
+		{"cone full circle", &conf.TargetCfg{Shape: conf.ShapeType_Cone, Angle: 360, Length: 10}, nil, pb.NewVector(-9, 0, 0), true},
+
+		{"rect origin", rect, nil, origin, true},
+		{"rect far corner", rect, nil, pb.NewVector(10, -2, 0), true},
+		{"rect too long", rect, nil, pb.NewVector(10.01, 0, 0), false},
+		{"rect too wide", rect, nil, pb.NewVector(5, 2.01, 0), false},
+		{"rect behind", rect, nil, pb.NewVector(-0.01, 0, 0), false},
+		{"rect facing up", rect, up, pb.NewVector(-2, 10, 0), true},
+		{"rect facing up miss", rect, up, pb.NewVector(5, 0, 0), false},
+
+		{"single not area", &conf.TargetCfg{Shape: conf.ShapeType_Single, Radius: 5}, nil, origin, false},
+		{"nil point", circle, nil, nil, false},
+	}
+
+	for _, c := range cases {
+		if got := InShape(c.cfg, origin, c.facing, c.p); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestDirToVector(t *testing.T) {
+	if v := DirToVector(90); !v.ApproximatelyEqual2D(pb.NewVector(0, 1, 0)) {
+		t.Errorf("Expected (0,1) for 90 degrees, got %s", v.StringF())
+	}
+	if v := DirToVector(90); math.Abs(v.ToAngle2D()-90) > 1e-9 {
+		t.Errorf("Expected round trip angle 90, got %v", v.ToAngle2D())
+	}
+}
+
+func TestSkillManager_SelectShapes(t *testing.T) {
+	z := newTestZone(loadTestTables())
+	caster := newTestEntity(z, 0, 0, nil)
+	front := newTestEntity(z, 5, 0, nil)
+	side := newTestEntity(z, 0, 5, nil)
+	back := newTestEntity(z, -5, 0, nil)
+	sm := GetCombatManager(caster).GetSkillManager()
+	cone := &conf.TargetCfg{Relation: conf.TargetRelation_All, Mode: conf.TargetMode_NoTarget, Shape: conf.ShapeType_Cone, Angle: 60, Length: 10, IncludeCaster: true}
+
+	// 默认朝向 X 轴正方向
+	ctx := skill.NewSkillContext(caster, nil, 1)
+	if targets := sm.selectTargets(cone, ctx); len(targets) != 2 || targets[1] != front {
+		t.Errorf("Expected caster and front entity, got %d targets", len(targets))
+	}
+
+	// 实体朝向 90 度
+	caster.SetDir(90)
+	if targets := sm.selectTargets(cone, ctx); len(targets) != 2 || targets[1] != side {
+		t.Errorf("Expected caster and side entity, got %d targets", len(targets))
+	}
+
+	// Req.Dir 优先于实体朝向
+	ctx = skill.NewSkillContext(caster, &pb.ReqCastSkill{Dir: pb.NewVector(-1, 0, 0)}, 1)
+	if targets := sm.selectTargets(cone, ctx); len(targets) != 2 || targets[1] != back {
+		t.Errorf("Expected caster and back entity, got %d targets", len(targets))
+	}
+
+	// 点选矩形：朝向为施法者指向施法点
+	rect := &conf.TargetCfg{Relation: conf.TargetRelation_All, Mode: conf.TargetMode_Point, Shape: conf.ShapeType_Rect, Width: 2, Length: 10}
+	ctx = skill.NewSkillContext(caster, &pb.ReqCastSkill{Pos: pb.NewVector(0, 3, 0)}, 1)
+	if targets := sm.selectTargets(rect, ctx); len(targets) != 1 || targets[0] != side {
+		t.Errorf("Expected side entity only, got %d targets", len(targets))
+	}
+
+	// 点选环形
+	ring := &conf.TargetCfg{Relation: conf.TargetRelation_All, Mode: conf.TargetMode_Point, Shape: conf.ShapeType_Ring, InnerRadius: 4, Radius: 6}
+	ctx = skill.NewSkillContext(caster, &pb.ReqCastSkill{Pos: pb.NewVector(0, 0, 0)}, 1)
+	if targets := sm.selectTargets(ring, ctx); len(targets) != 3 {
+		t.Errorf("Expected 3 entities in ring, got %d", len(targets))
+	}
+}
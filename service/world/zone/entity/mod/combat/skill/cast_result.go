@@ -0,0 +1,71 @@
+package skill
+
+import (
+	"fmt"
+
+	"server/pb"
+)
+
+// CastResult 为施法校验/开始施法的结果，可写入 pb.RspCastSkill 返回给客户端。
+type CastResult int32
+
+const (
+	CastResult_Invalid           CastResult = 0
+	CastResult_Success           CastResult = 1  // 成功
+	CastResult_UnknownSkill      CastResult = 2  // 技能不存在（未学会）
+	CastResult_Casting           CastResult = 3  // 正在吟唱/引导
+	CastResult_Gcd               CastResult = 4  // 公共CD中
+	CastResult_Cooldown          CastResult = 5  // 技能CD中
+	CastResult_NoCharges         CastResult = 6  // 充能不足
+	CastResult_NotEnoughResource CastResult = 7  // 资源不足
+	CastResult_OutOfRange        CastResult = 8  // 超出施法距离
+	CastResult_InvalidTarget     CastResult = 9  // 目标不存在或不可选
+	CastResult_Dead              CastResult = 10 // 施法者已死亡
+	CastResult_Stunned           CastResult = 11 // 被眩晕
+	CastResult_Silenced          CastResult = 12 // 被沉默
+)
+
+var castResultNames = map[CastResult]string{
+	CastResult_Invalid:           "Invalid",
+	CastResult_Success:           "Success",
+	CastResult_UnknownSkill:      "UnknownSkill",
+	CastResult_Casting:           "Casting",
+	CastResult_Gcd:               "Gcd",
+	CastResult_Cooldown:          "Cooldown",
+	CastResult_NoCharges:         "NoCharges",
+	CastResult_NotEnoughResource: "NotEnoughResource",
+	CastResult_OutOfRange:        "OutOfRange",
+	CastResult_InvalidTarget:     "InvalidTarget",
+	CastResult_Dead:              "Dead",
+	CastResult_Stunned:           "Stunned",
+	CastResult_Silenced:          "Silenced",
+}
+
+func (r CastResult) String() string {
+	if name, ok := castResultNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("CastResult(%d)", int32(r))
+}
+
+// Ok 判断是否施法成功。
+func (r CastResult) Ok() bool {
+	return r == CastResult_Success
+}
+
+// ErrorCode 映射为通用错误码：成功为 Ok，其余为 Failed（详细原因见 RspCastSkill.Result）。
+func (r CastResult) ErrorCode() pb.EErrorCode_T {
+	if r.Ok() {
+		return pb.EErrorCode_Ok
+	}
+	return pb.EErrorCode_Failed
+}
+
+// ToRsp 生成施法响应。
+func (r CastResult) ToRsp(cid int64) *pb.RspCastSkill {
+	return &pb.RspCastSkill{
+		Cid:    cid,
+		Err:    r.ErrorCode(),
+		Result: int32(r),
+	}
+}
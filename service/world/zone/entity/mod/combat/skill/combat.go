@@ -0,0 +1,79 @@
+package skill
+
+import (
+	"server/data"
+	"server/data/conf"
+	"server/lib/uid"
+	"server/service/world/zone/izone"
+)
+
+// ICombat 为 Effect 结算伤害/治疗所需的战斗接口，由实体的战斗模块实现。
+// skill 包不能直接依赖 combat 包，Effect 通过施法者的模块取得该接口。
+type ICombat interface {
+	// DealDamage 按公式计算并对 target 造成伤害，threat 为额外仇恨，返回实际扣除的生命值
+	DealDamage(ctx *SkillContext, target izone.IEntity, formulaId int64, threat int64) int64
+	// DealHeal 按公式计算并对 target 进行治疗，返回实际恢复的生命值
+	DealHeal(ctx *SkillContext, target izone.IEntity, formulaId int64) int64
+	// CalculateHeal 按公式计算 healer 对 target 的治疗量（也用于护盾吸收量），不结算
+	CalculateHeal(ctx *SkillContext, healer izone.IEntity, target izone.IEntity, formulaId int64) int64
+}
+
+// getCombat 获取施法者的战斗接口，未挂载战斗模块时返回 nil。
+func getCombat(causer izone.IEntity) ICombat {
+	if causer == nil {
+		return nil
+	}
+	c, _ := causer.GetModule(izone.ModuleType_Combat).(ICombat)
+	return c
+}
+
+// IResource 为技能消耗资源所需的接口，由实体的战斗模块实现。
+type IResource interface {
+	// HasResource 判断资源是否足够
+	HasResource(t conf.ResourceType, amount int64) bool
+	// CostResource 扣除资源，不足时不扣除并返回 false
+	CostResource(t conf.ResourceType, amount int64) bool
+	// RefundResource 返还资源（不超过上限）
+	RefundResource(t conf.ResourceType, amount int64)
+}
+
+// getResource 获取施法者的资源接口，未挂载战斗模块时返回 nil（视为无需消耗）。
+func getResource(owner izone.IEntity) IResource {
+	if owner == nil {
+		return nil
+	}
+	r, _ := owner.GetModule(izone.ModuleType_Combat).(IResource)
+	return r
+}
+
+// IStatus 为 Buff 效果修改目标控制状态、移动速度与属性所需的接口，由实体的战斗模块实现。
+type IStatus interface {
+	IsDead() bool
+	AddControl(t conf.CCType)
+	RemoveControl(t conf.CCType)
+	AddMoveSpeedPct(pct float64)
+	RemoveMoveSpeedPct(pct float64)
+	AddAttrModifier(mod data.AttrModifier)
+	RemoveAttrModifiers(source uid.Uid)
+}
+
+// IBuff 为施加与查询 Buff 所需的接口，由实体的 Buff 模块实现（Buff 存放在目标身上）。
+type IBuff interface {
+	// AddBuff 由 caster 对本实体施加 Buff，stacks/durationMs 为 0 时使用配表值，返回是否施加成功
+	AddBuff(caster izone.IEntity, ctx *SkillContext, buffId int64, stacks int32, durationMs int64) bool
+	// HasBuff 判断本实体身上是否有指定 Buff
+	HasBuff(buffId int64) bool
+	// RemoveOnDeath 本实体死亡时移除 Buff（带 KeepOnDeath 标签的保留）
+	RemoveOnDeath()
+	// AbsorbDamage 由本实体身上的护盾吸收 school 系别的伤害，返回吸收量
+	AbsorbDamage(damage int64, school conf.DamageSchool) int64
+}
+
+// BuffOf 获取实体的 Buff 接口，未挂载 Buff 模块时返回 nil。
+func BuffOf(e izone.IEntity) IBuff {
+	if e == nil {
+		return nil
+	}
+	b, _ := e.GetModule(izone.ModuleType_Buff).(IBuff)
+	return b
+}
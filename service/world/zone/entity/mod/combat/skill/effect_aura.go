@@ -14,11 +14,19 @@ func NewAuraEffect(cfg conf.EffectCfg) *AuraEffect {
 	return &AuraEffect{cfg: cfg}
 }
 
+// BuffId 获取施加的 Buff 配置ID。
+func (e *AuraEffect) BuffId() int64 {
+	return e.cfg.RefId
+}
+
+// Begin 对每个目标施加 Buff，RefId 为 BuffID，P1 为层数，P2 为持续时间（毫秒），为 0 时使用 Buff 配表值。
+// Buff 由目标的 Buff 模块管理，未挂载 Buff 模块的目标忽略。
 func (e *AuraEffect) Begin(ctx *SkillContext, causer izone.IEntity, targets []izone.IEntity) {
-	_ = ctx
-	_ = causer
-	_ = targets
-	_ = e.cfg
+	for _, target := range targets {
+		if b := BuffOf(target); b != nil {
+			b.AddBuff(causer, ctx, e.cfg.RefId, int32(e.cfg.P1), e.cfg.P2)
+		}
+	}
 }
 
 func (e *AuraEffect) Update(ctx *SkillContext, delta time.Duration) {
@@ -14,11 +14,15 @@ func NewDamageEffect(cfg conf.EffectCfg) *DamageEffect {
 	return &DamageEffect{cfg: cfg}
 }
 
+// Begin 对每个目标结算一次伤害，RefId 为伤害公式ID，P1 为额外仇恨。
 func (e *DamageEffect) Begin(ctx *SkillContext, causer izone.IEntity, targets []izone.IEntity) {
-	_ = ctx
-	_ = causer
-	_ = targets
-	_ = e.cfg
+	c := getCombat(causer)
+	if c == nil {
+		return
+	}
+	for _, target := range targets {
+		c.DealDamage(ctx, target, e.cfg.RefId, e.cfg.P1)
+	}
 }
 
 func (e *DamageEffect) Update(ctx *SkillContext, delta time.Duration) {
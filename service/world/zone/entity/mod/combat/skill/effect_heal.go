@@ -14,11 +14,15 @@ func NewHealEffect(cfg conf.EffectCfg) *HealEffect {
 	return &HealEffect{cfg: cfg}
 }
 
+// Begin 对每个目标结算一次治疗，RefId 为治疗公式ID。
 func (e *HealEffect) Begin(ctx *SkillContext, causer izone.IEntity, targets []izone.IEntity) {
-	_ = ctx
-	_ = causer
-	_ = targets
-	_ = e.cfg
+	c := getCombat(causer)
+	if c == nil {
+		return
+	}
+	for _, target := range targets {
+		c.DealHeal(ctx, target, e.cfg.RefId)
+	}
 }
 
 func (e *HealEffect) Update(ctx *SkillContext, delta time.Duration) {
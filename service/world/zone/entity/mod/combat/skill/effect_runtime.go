@@ -201,7 +201,7 @@ func (r *EffectRuntime) GetRemainingMs(nowMs int64) int64 {
 
 // GetProgress 获取进度（0.0 - 1.0）
 func (r *EffectRuntime) GetProgress(nowMs int64) float32 {
-	if r.EndMs <= 0 || r.StartMs <= 0 {
+	if r.EndMs <= 0 || r.State == EffectState_Pending {
 		return 0.0
 	}
 
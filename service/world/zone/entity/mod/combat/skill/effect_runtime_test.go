@@ -0,0 +1,20 @@
+package skill
+
+import "testing"
+
+func TestEffectRuntime_ProgressFromTimeZero(t *testing.T) {
+	rt := NewEffectRuntime(nil, nil, nil, nil)
+	rt.EndMs = 1000
+	if p := rt.GetProgress(500); p != 0 {
+		t.Errorf("Expected 0 progress before activation, got %v", p)
+	}
+
+	// 在时间 0 激活（区域第一帧创建的效果）
+	rt.Activate(0)
+	if p := rt.GetProgress(500); p != 0.5 {
+		t.Errorf("Expected 0.5 progress, got %v", p)
+	}
+	if p := rt.GetProgress(1500); p != 1 {
+		t.Errorf("Expected full progress after end, got %v", p)
+	}
+}
@@ -26,12 +26,15 @@ const (
 
 // ScheduledEffect 为延迟执行的 Effect。
 // 用于支持多段结算（Times/IntervalMs）、以及未来的弹道/延迟命中等。
+// 调度时记录当时的技能配置与上下文，执行时不受之后的热更或新一次施法影响。
 type ScheduledEffect struct {
 	At     int64
 	Stage  Stage
 	Effect conf.EffectCfg
-	Index  int32 // 执行索引（同一 EffectCfg 配置的第几次执行，从 0 开始）
-	Seq    int32 // 全局序列号（整个技能所有 Effect 的执行顺序，从 0 开始递增）
+	Cfg    *conf.CSkill  // 调度时的技能配置（目标选择器等）
+	Ctx    *SkillContext // 调度时的技能上下文
+	Index  int32         // 执行索引（同一 EffectCfg 配置的第几次执行，从 0 开始）
+	Seq    int32         // 全局序列号（整个技能所有 Effect 的执行顺序，从 0 开始递增）
 }
 
 // RuntimeState 为技能运行时状态（是否正在吟唱/引导）。
@@ -56,6 +59,11 @@ type Skill struct {
 	CdEndAt  int64
 	GcdEndAt int64
 
+	// Charges/RechargeEndAt 为充能技能的当前充能数与下一层充能完成时间（充满时为 0）。
+	// 充能技能不使用 CdEndAt，CD 起算时机改为消耗一层充能。
+	Charges       int32
+	RechargeEndAt int64
+
 	// State/CastEndAt/ChannelEndAt 用于推进吟唱/引导流程。
 	State        RuntimeState
 	CastEndAt    int64
@@ -64,35 +72,166 @@ type Skill struct {
 	// Ctx 为当前技能上下文；Pending 为待执行的 Effect 队列。
 	Ctx     *SkillContext
 	Pending []ScheduledEffect
+
+	// FinishCheck 为吟唱结束时的校验（如重新校验施法距离），返回失败时打断施法。
+	FinishCheck func(ctx *SkillContext) CastResult
+
+	// paidCost 为本次吟唱已扣除的资源（吟唱结束后清零），取消时按配置返还。
+	paidCost int64
+
+	// nextCfg 为配置热更后待生效的新配置，在技能空闲（无吟唱/引导）时或下次施法开始时切换。
+	nextCfg *conf.CSkill
 }
 
 // NewSkill 创建技能运行时实例。
 func NewSkill(cfg *conf.CSkill) *Skill {
-	return &Skill{Cfg: cfg}
+	s := &Skill{Cfg: cfg}
+	s.syncCharges()
+	return s
+}
+
+// Reload 设置热更后的新配置。
+// 吟唱/引导中的技能继续使用开始施法时的配置，在下次施法开始时切换；
+// 已调度的 Effect 保存了调度时的技能配置与上下文，切换后仍按旧配置执行。
+func (s *Skill) Reload(cfg *conf.CSkill) {
+	if s == nil || cfg == nil || cfg == s.Cfg {
+		return
+	}
+	s.nextCfg = cfg
+	s.applyNextCfg()
+}
+
+// applyNextCfg 在技能空闲（施法边界）时切换到待生效的新配置。
+func (s *Skill) applyNextCfg() {
+	if s.nextCfg == nil || s.State != RuntimeState_Idle {
+		return
+	}
+	s.Cfg = s.nextCfg
+	s.nextCfg = nil
+	s.syncCharges()
+}
+
+// ChargeState 为充能技能的状态，用于同步给客户端。
+type ChargeState struct {
+	Charges       int32 // 当前充能数
+	MaxCharges    int32 // 最大充能数
+	RechargeEndAt int64 // 下一层充能完成时间（充满时为 0）
+	RechargeMs    int64 // 每层充能时间
+}
+
+// maxCharges 获取最大充能数，0 表示无充能机制。
+func (s *Skill) maxCharges() int32 {
+	if s.Cfg == nil || s.Cfg.Charges <= 1 {
+		return 0
+	}
+	return s.Cfg.Charges
+}
+
+// rechargeMs 获取每层充能时间，未配置 RechargeMs 时使用 CooldownMs。
+func (s *Skill) rechargeMs() int64 {
+	if s.Cfg.RechargeMs > 0 {
+		return int64(s.Cfg.RechargeMs)
+	}
+	return int64(s.Cfg.CooldownMs)
+}
+
+// syncCharges 在配置变化后修正充能状态：未在充能中时充满，最大充能减少时截断。
+func (s *Skill) syncCharges() {
+	maxCharges := s.maxCharges()
+	switch {
+	case maxCharges == 0:
+		s.Charges = 0
+		s.RechargeEndAt = 0
+	case s.Charges >= maxCharges || s.RechargeEndAt == 0:
+		s.Charges = maxCharges
+		s.RechargeEndAt = 0
+	}
+}
+
+// refreshCharges 结算到 now 为止完成的充能，每层充能依次计时。
+func (s *Skill) refreshCharges(now int64) {
+	maxCharges := s.maxCharges()
+	if maxCharges == 0 {
+		return
+	}
+	for s.RechargeEndAt > 0 && now >= s.RechargeEndAt {
+		s.Charges++
+		if s.Charges >= maxCharges {
+			s.Charges = maxCharges
+			s.RechargeEndAt = 0
+			break
+		}
+		s.RechargeEndAt += max(s.rechargeMs(), 1)
+	}
+}
+
+// startCooldown 在 CD 起算时机开始冷却：充能技能消耗一层充能并开始充能计时，普通技能设置 CdEndAt。
+func (s *Skill) startCooldown(now int64) {
+	if s.maxCharges() == 0 {
+		if s.Cfg.CooldownMs > 0 {
+			s.CdEndAt = now + int64(s.Cfg.CooldownMs)
+		}
+		return
+	}
+
+	s.refreshCharges(now)
+	if s.Charges > 0 {
+		s.Charges--
+	}
+	if s.RechargeEndAt == 0 && s.Charges < s.maxCharges() {
+		s.RechargeEndAt = now + s.rechargeMs()
+	}
+	s.refreshCharges(now)
 }
 
-// CanCast 判定当前是否允许施放（Idle + CD/GCD 到期）。
-func (s *Skill) CanCast(now int64) bool {
+// GetChargeState 获取 now 时刻的充能状态，非充能技能返回 nil。
+func (s *Skill) GetChargeState(now int64) *ChargeState {
+	if s == nil || s.maxCharges() == 0 {
+		return nil
+	}
+	s.refreshCharges(now)
+	return &ChargeState{
+		Charges:       s.Charges,
+		MaxCharges:    s.maxCharges(),
+		RechargeEndAt: s.RechargeEndAt,
+		RechargeMs:    s.rechargeMs(),
+	}
+}
+
+// CanCast 判定当前是否允许施放：Idle、GCD/CD 到期，充能技能需至少剩余一层充能。
+func (s *Skill) CanCast(now int64) CastResult {
 	if s == nil || s.Cfg == nil {
-		return false
+		return CastResult_UnknownSkill
 	}
 	if s.State != RuntimeState_Idle {
-		return false
+		return CastResult_Casting
 	}
 	if s.GcdEndAt > now {
-		return false
+		return CastResult_Gcd
 	}
 	if s.CdEndAt > now {
-		return false
+		return CastResult_Cooldown
 	}
-	return true
+	if s.maxCharges() > 0 {
+		s.refreshCharges(now)
+		if s.Charges <= 0 {
+			return CastResult_NoCharges
+		}
+	}
+	return CastResult_Success
 }
 
 // StartCast 尝试开始施法。
-// 成功后会触发 OnCastStart，并进入 Casting（若有吟唱）或直接 finishCast（瞬发）。
-func (s *Skill) StartCast(now int64, ctx *SkillContext) bool {
-	if !s.CanCast(now) {
-		return false
+// 校验通过后扣除资源，触发 OnCastStart，并进入 Casting（若有吟唱）或直接 finishCast（瞬发）。
+func (s *Skill) StartCast(now int64, ctx *SkillContext) CastResult {
+	if s != nil {
+		s.applyNextCfg()
+	}
+	if r := s.CanCast(now); !r.Ok() {
+		return r
+	}
+	if !s.payCost(ctx) {
+		return CastResult_NotEnoughResource
 	}
 
 	s.Ctx = ctx
@@ -109,8 +248,8 @@ func (s *Skill) StartCast(now int64, ctx *SkillContext) bool {
 	if gcdStartAt == conf.TimingPoint_CastStart && s.Cfg.GcdMs > 0 {
 		s.GcdEndAt = now + int64(s.Cfg.GcdMs)
 	}
-	if cdStartAt == conf.TimingPoint_CastStart && s.Cfg.CooldownMs > 0 {
-		s.CdEndAt = now + int64(s.Cfg.CooldownMs)
+	if cdStartAt == conf.TimingPoint_CastStart {
+		s.startCooldown(now)
 	}
 
 	s.scheduleList(Stage_CastStart, now, 0, s.Cfg.Effects.OnCastStart)
@@ -118,13 +257,42 @@ func (s *Skill) StartCast(now int64, ctx *SkillContext) bool {
 	if s.Cfg.CastTimeMs > 0 {
 		s.State = RuntimeState_Casting
 		s.CastEndAt = now + int64(s.Cfg.CastTimeMs)
-		return true
+		return CastResult_Success
 	}
 
 	s.finishCast(now)
+	return CastResult_Success
+}
+
+// payCost 扣除技能消耗，施法者没有资源模块时视为无需消耗。
+func (s *Skill) payCost(ctx *SkillContext) bool {
+	s.paidCost = 0
+	if s.Cfg.CostType == conf.ResourceType_None || s.Cfg.Cost <= 0 || ctx == nil {
+		return true
+	}
+	res := getResource(ctx.Owner)
+	if res == nil {
+		return true
+	}
+	if !res.CostResource(s.Cfg.CostType, s.Cfg.Cost) {
+		return false
+	}
+	s.paidCost = s.Cfg.Cost
 	return true
 }
 
+// refundCost 吟唱被取消时按配置返还已扣除的资源。
+func (s *Skill) refundCost() {
+	paid := s.paidCost
+	s.paidCost = 0
+	if paid <= 0 || !s.Cfg.RefundOnCancel || s.Ctx == nil {
+		return
+	}
+	if res := getResource(s.Ctx.Owner); res != nil {
+		res.RefundResource(s.Cfg.CostType, paid)
+	}
+}
+
 // Cancel 取消/打断施法或引导。
 func (s *Skill) Cancel(now int64) {
 	if s == nil || s.Cfg == nil {
@@ -133,6 +301,9 @@ func (s *Skill) Cancel(now int64) {
 	if s.State == RuntimeState_Idle {
 		return
 	}
+	if s.State == RuntimeState_Casting {
+		s.refundCost()
+	}
 
 	s.State = RuntimeState_Idle
 	s.CastEndAt = 0
@@ -152,14 +323,19 @@ func (s *Skill) TriggerHit(now int64, ctx *SkillContext) {
 }
 
 // Update 推进技能运行时，并在时间到达时执行 Pending 队列。
-// exec 回调由上层实现，用来处理"实际结算"（伤害/治疗/施加 Buff 等）。
-func (s *Skill) Update(now int64, exec func(Stage, conf.EffectCfg, *SkillContext)) {
+// exec 回调由上层实现，用来处理"实际结算"（伤害/治疗/施加 Buff 等），应使用 se 中记录的配置与上下文。
+func (s *Skill) Update(now int64, exec func(se *ScheduledEffect)) {
 	if s == nil || s.Cfg == nil {
 		return
 	}
 
+	s.refreshCharges(now)
 	if s.State == RuntimeState_Casting && s.CastEndAt > 0 && now >= s.CastEndAt {
-		s.finishCast(now)
+		if s.FinishCheck != nil && !s.FinishCheck(s.Ctx).Ok() {
+			s.Cancel(now)
+		} else {
+			s.finishCast(now)
+		}
 	}
 	if s.State == RuntimeState_Channeling && s.ChannelEndAt > 0 && now >= s.ChannelEndAt {
 		s.State = RuntimeState_Idle
@@ -182,10 +358,10 @@ func (s *Skill) Update(now int64, exec func(Stage, conf.EffectCfg, *SkillContext
 		se := s.Pending[idx]
 		if exec != nil {
 			// 设置当前 Effect 的全局序列号
-			if s.Ctx != nil {
-				s.Ctx.CurrentEffectSeq = se.Seq
+			if se.Ctx != nil {
+				se.Ctx.CurrentEffectSeq = se.Seq
 			}
-			exec(se.Stage, se.Effect, s.Ctx)
+			exec(&se)
 		}
 		idx++
 	}
@@ -200,6 +376,7 @@ func (s *Skill) Update(now int64, exec func(Stage, conf.EffectCfg, *SkillContext
 func (s *Skill) finishCast(now int64) {
 	s.State = RuntimeState_Idle
 	s.CastEndAt = 0
+	s.paidCost = 0
 
 	gcdStartAt := s.Cfg.GcdStartAt
 	if gcdStartAt == conf.TimingPoint_Invalid {
@@ -213,8 +390,8 @@ func (s *Skill) finishCast(now int64) {
 	if gcdStartAt == conf.TimingPoint_CastFinish && s.Cfg.GcdMs > 0 {
 		s.GcdEndAt = now + int64(s.Cfg.GcdMs)
 	}
-	if cdStartAt == conf.TimingPoint_CastFinish && s.Cfg.CooldownMs > 0 {
-		s.CdEndAt = now + int64(s.Cfg.CooldownMs)
+	if cdStartAt == conf.TimingPoint_CastFinish {
+		s.startCooldown(now)
 	}
 
 	s.scheduleList(Stage_CastFinish, now, 0, s.Cfg.Effects.OnCastFinish)
@@ -245,7 +422,7 @@ func (s *Skill) scheduleList(stage Stage, startAt int64, endAt int64, list []con
 }
 
 // scheduleEffect 将单个 EffectCfg 调度为 1 次或多次执行。
-// 若 eff.Times > 1，则按 eff.IntervalMs 间隔追加多条 ScheduledEffect。
+// 若 eff.Times > 1，则按 eff.IntervalMs 间隔追加多条 ScheduledEffect；首次执行整体后移 eff.DelayMs。
 func (s *Skill) scheduleEffect(stage Stage, startAt int64, endAt int64, eff conf.EffectCfg) {
 	times := eff.Times
 	if times <= 1 {
@@ -267,6 +444,10 @@ func (s *Skill) scheduleEffect(stage Stage, startAt int64, endAt int64, eff conf
 		interval = s.Cfg.ChannelTickMs
 	}
 
+	if eff.DelayMs > 0 {
+		startAt += int64(eff.DelayMs)
+	}
+
 	for i := int32(0); i < times; i++ {
 		at := startAt + int64(i)*int64(interval)
 		if endAt > 0 && at > endAt {
@@ -277,6 +458,8 @@ func (s *Skill) scheduleEffect(stage Stage, startAt int64, endAt int64, eff conf
 			At:     at,
 			Stage:  stage,
 			Effect: eff,
+			Cfg:    s.Cfg,
+			Ctx:    s.Ctx,
 			Index:  i,   // 当前配置的第几次执行
 			Seq:    seq, // 全局执行顺序
 		})
@@ -41,6 +41,7 @@ type EffectResult struct {
 
 	// 常用数据字段（覆盖大部分场景）
 	Damage    int64            // 造成的伤害
+	Absorbed  int64            // 被目标护盾吸收的伤害
 	Heal      int64            // 治疗量
 	IsCrit    bool             // 是否暴击
 	Targets   []izone2.IEntity // 命中的目标
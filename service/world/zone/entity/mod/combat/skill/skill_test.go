@@ -0,0 +1,170 @@
+package skill
+
+import (
+	"testing"
+
+	"server/data/conf"
+)
+
+func TestSkill_ReloadDeferredWhileCasting(t *testing.T) {
+	oldCfg := &conf.CSkill{Cid: 1, CastTimeMs: 1000, CooldownMs: 5000}
+	newCfg := &conf.CSkill{Cid: 1, CastTimeMs: 500, CooldownMs: 2000}
+
+	s := NewSkill(oldCfg)
+	if !s.StartCast(0, NewSkillContext(nil, nil, 1)).Ok() {
+		t.Fatal("Expected StartCast to succeed")
+	}
+
+	s.Reload(newCfg)
+	if s.Cfg != oldCfg {
+		t.Error("Expected casting skill to keep its original config")
+	}
+
+	s.Update(1000, nil)
+	if s.CdEndAt != 5000 {
+		t.Errorf("Expected cooldown from original config, got CdEndAt=%d", s.CdEndAt)
+	}
+
+	if !s.StartCast(5000, NewSkillContext(nil, nil, 1)).Ok() {
+		t.Fatal("Expected StartCast to succeed after cooldown")
+	}
+	if s.Cfg != newCfg {
+		t.Error("Expected new config to be applied on next cast")
+	}
+	if s.CastEndAt != 5500 || s.CdEndAt != 7000 {
+		t.Errorf("Expected new timings, got CastEndAt=%d CdEndAt=%d", s.CastEndAt, s.CdEndAt)
+	}
+}
+
+func TestSkill_ReloadIdleAppliesImmediately(t *testing.T) {
+	s := NewSkill(&conf.CSkill{Cid: 1})
+	newCfg := &conf.CSkill{Cid: 1, GcdMs: 1500}
+	s.Reload(newCfg)
+	if s.Cfg != newCfg {
+		t.Error("Expected idle skill to switch config immediately")
+	}
+}
+
+func TestSkill_ReloadAppliesAtCastBoundaryWithPending(t *testing.T) {
+	oldEff := conf.EffectCfg{Type: conf.EffectType_Damage, DelayMs: 1000, Times: 5, IntervalMs: 1000}
+	oldCfg := &conf.CSkill{Cid: 1, Target: conf.TargetCfg{Radius: 5}, Effects: conf.SkillEffects{OnCastFinish: []conf.EffectCfg{oldEff}}}
+	newCfg := &conf.CSkill{Cid: 1, GcdMs: 100, Target: conf.TargetCfg{Radius: 30}}
+
+	s := NewSkill(oldCfg)
+	s.StartCast(0, NewSkillContext(nil, nil, 1))
+	s.Reload(newCfg)
+	if s.Cfg != newCfg {
+		t.Error("Expected idle skill with pending effects to switch config immediately")
+	}
+
+	s = NewSkill(oldCfg)
+	firstCtx := NewSkillContext(nil, nil, 1)
+	s.StartCast(0, firstCtx)
+	s.Update(1000, nil)
+	s.Reload(newCfg)
+	nextCtx := NewSkillContext(nil, nil, 1)
+	if !s.StartCast(1500, nextCtx).Ok() {
+		t.Fatal("Expected StartCast to succeed")
+	}
+	if s.Cfg != newCfg || s.Ctx != nextCtx || s.GcdEndAt != 1600 {
+		t.Errorf("Expected new config and context on next cast, got GcdEndAt=%d", s.GcdEndAt)
+	}
+
+	// 先前施法调度的 Effect 仍使用其施法时的配置（目标选择器）与上下文
+	fired := 0
+	s.Update(2000, func(se *ScheduledEffect) {
+		if se.Cfg != oldCfg || se.Cfg.Target.Radius != 5 || se.Ctx != firstCtx || firstCtx.CurrentEffectSeq != se.Seq {
+			t.Errorf("Expected pending effect to keep the original config and context, got radius %v", se.Cfg.Target.Radius)
+		}
+		fired++
+	})
+	if fired != 1 {
+		t.Errorf("Expected 1 pending effect from the earlier cast, got %d", fired)
+	}
+}
+
+func TestSkill_DelayMs(t *testing.T) {
+	cfg := &conf.CSkill{
+		Cid: 1,
+		Effects: conf.SkillEffects{
+			OnCastFinish: []conf.EffectCfg{{Type: conf.EffectType_Damage, DelayMs: 500, Times: 2, IntervalMs: 100}},
+		},
+	}
+	s := NewSkill(cfg)
+	s.StartCast(0, NewSkillContext(nil, nil, 1))
+
+	var fired []int64
+	for _, now := range []int64{0, 499, 500, 599, 600} {
+		s.Update(now, func(*ScheduledEffect) {
+			fired = append(fired, now)
+		})
+	}
+	if len(fired) != 2 || fired[0] != 500 || fired[1] != 600 {
+		t.Errorf("Expected effects at 500 and 600, got %v", fired)
+	}
+}
+
+func TestSkill_Charges(t *testing.T) {
+	s := NewSkill(&conf.CSkill{Cid: 1, Charges: 2, RechargeMs: 1000, CooldownMs: 5000})
+	if st := s.GetChargeState(0); st == nil || st.Charges != 2 || st.MaxCharges != 2 {
+		t.Fatalf("Expected full charges, got %+v", st)
+	}
+
+	for i := range 2 {
+		if !s.StartCast(int64(i), NewSkillContext(nil, nil, 1)).Ok() {
+			t.Fatalf("Expected cast %d to succeed", i)
+		}
+	}
+	if r := s.CanCast(2); r != CastResult_NoCharges {
+		t.Errorf("Expected NoCharges, got %s", r)
+	}
+	if s.CdEndAt != 0 {
+		t.Errorf("Expected charge skill not to use CdEndAt, got %d", s.CdEndAt)
+	}
+
+	// 第一层在 0+1000 完成，第二层接着计时到 2000
+	st := s.GetChargeState(999)
+	if st.Charges != 0 || st.RechargeEndAt != 1000 {
+		t.Errorf("Expected recharging at 1000, got %+v", st)
+	}
+	if !s.CanCast(1000).Ok() {
+		t.Error("Expected cast after one charge recharged")
+	}
+	if st := s.GetChargeState(1500); st.Charges != 1 || st.RechargeEndAt != 2000 {
+		t.Errorf("Expected 1 charge recharging at 2000, got %+v", st)
+	}
+	if st := s.GetChargeState(5000); st.Charges != 2 || st.RechargeEndAt != 0 {
+		t.Errorf("Expected full charges, got %+v", st)
+	}
+}
+
+func TestSkill_ChargesCooldownStartAtCastFinish(t *testing.T) {
+	s := NewSkill(&conf.CSkill{Cid: 1, Charges: 2, RechargeMs: 1000, CastTimeMs: 500, CooldownStartAt: conf.TimingPoint_CastFinish})
+
+	s.StartCast(0, NewSkillContext(nil, nil, 1))
+	s.Cancel(100)
+	if st := s.GetChargeState(100); st.Charges != 2 {
+		t.Errorf("Expected cancelled cast to keep charges, got %+v", st)
+	}
+
+	s.StartCast(100, NewSkillContext(nil, nil, 1))
+	s.Update(600, nil)
+	if st := s.GetChargeState(600); st.Charges != 1 || st.RechargeEndAt != 1600 {
+		t.Errorf("Expected charge consumed at cast finish, got %+v", st)
+	}
+}
+
+func TestSkill_ChargesReload(t *testing.T) {
+	s := NewSkill(&conf.CSkill{Cid: 1, CooldownMs: 1000})
+	if s.GetChargeState(0) != nil {
+		t.Error("Expected no charge state for normal skill")
+	}
+	s.Reload(&conf.CSkill{Cid: 1, Charges: 3, RechargeMs: 1000})
+	if st := s.GetChargeState(0); st == nil || st.Charges != 3 {
+		t.Errorf("Expected full charges after reload, got %+v", st)
+	}
+	s.Reload(&conf.CSkill{Cid: 1, Charges: 2, RechargeMs: 1000})
+	if st := s.GetChargeState(0); st.Charges != 2 {
+		t.Errorf("Expected charges truncated to 2, got %+v", st)
+	}
+}
@@ -0,0 +1,109 @@
+package combat
+
+import (
+	"server/lib/container"
+	"server/lib/uid"
+)
+
+// ThreatEntry 为单个来源对本实体的仇恨与伤害统计。
+type ThreatEntry struct {
+	Source uid.Uid // 来源实体ID
+	Threat int64   // 仇恨值
+	Damage int64   // 累计造成的伤害
+	Heal   int64   // 累计造成的治疗
+	LastMs int64   // 最后一次产生仇恨的时间
+
+	LastDamageMs int64 // 最后一次造成伤害的时间（用于助攻判定）
+}
+
+// ThreatTable 为实体的仇恨表，按来源首次出现的顺序保存。
+// 伤害来源用于仇恨排序、击杀归属（最后一击与助攻）与战斗日志。
+type ThreatTable struct {
+	entries *container.LMap[uid.Uid, *ThreatEntry]
+}
+
+func newThreatTable() *ThreatTable {
+	return &ThreatTable{
+		entries: container.NewLMap[uid.Uid, *ThreatEntry](),
+	}
+}
+
+// entry 获取来源的仇恨记录，不存在时创建。
+func (t *ThreatTable) entry(source uid.Uid) *ThreatEntry {
+	e, ok := t.entries.Get(source)
+	if !ok {
+		e = &ThreatEntry{Source: source}
+		t.entries.Set(source, e)
+	}
+	return e
+}
+
+// AddDamage 记录来源造成的伤害，伤害等量计入仇恨。
+func (t *ThreatTable) AddDamage(source uid.Uid, damage int64, nowMs int64) {
+	e := t.entry(source)
+	e.Damage += damage
+	e.Threat += damage
+	e.LastMs = nowMs
+	e.LastDamageMs = nowMs
+}
+
+// AddHeal 记录来源造成的治疗（只做统计，不计入仇恨）。
+func (t *ThreatTable) AddHeal(source uid.Uid, heal int64, nowMs int64) {
+	e := t.entry(source)
+	e.Heal += heal
+	e.LastMs = nowMs
+}
+
+// AddThreat 直接修改来源的仇恨值，仇恨不会低于 0。
+func (t *ThreatTable) AddThreat(source uid.Uid, threat int64, nowMs int64) {
+	e := t.entry(source)
+	e.Threat = max(e.Threat+threat, 0)
+	e.LastMs = nowMs
+}
+
+// Get 获取来源的仇恨记录。
+func (t *ThreatTable) Get(source uid.Uid) (*ThreatEntry, bool) {
+	return t.entries.Get(source)
+}
+
+// GetThreat 获取来源的仇恨值，无记录时为 0。
+func (t *ThreatTable) GetThreat(source uid.Uid) int64 {
+	if e, ok := t.entries.Get(source); ok {
+		return e.Threat
+	}
+	return 0
+}
+
+// Highest 获取仇恨最高的来源，仇恨相同时先进入仇恨表者优先。
+func (t *ThreatTable) Highest() (uid.Uid, bool) {
+	var top *ThreatEntry
+	t.entries.ForEach(func(e *ThreatEntry) {
+		if top == nil || e.Threat > top.Threat {
+			top = e
+		}
+	})
+	if top == nil {
+		return uid.Zero, false
+	}
+	return top.Source, true
+}
+
+// Entries 按进入仇恨表的顺序返回全部记录。
+func (t *ThreatTable) Entries() []*ThreatEntry {
+	return t.entries.Values()
+}
+
+// Remove 移除来源的仇恨记录（如来源离开区域）。
+func (t *ThreatTable) Remove(source uid.Uid) {
+	t.entries.Delete(source)
+}
+
+// Clear 清空仇恨表。
+func (t *ThreatTable) Clear() {
+	t.entries.Clear()
+}
+
+// Len 获取仇恨表中的来源数量。
+func (t *ThreatTable) Len() int {
+	return t.entries.Len()
+}
@@ -0,0 +1,237 @@
+package move
+
+import (
+	"math"
+
+	"server/data"
+	"server/data/conf"
+	"server/data/enum"
+	"server/pb"
+	"server/service/world/zone/entity/mod/combat"
+	"server/service/world/zone/izone"
+)
+
+var _ izone.IModule = (*MoveManager)(nil)
+
+// SpeedPrecision 为速度属性精度：AttrType_Speed 为 100 时每秒移动 1 个距离单位。
+const SpeedPrecision = 100
+
+// Config 为移动校验参数。
+type Config struct {
+	Tolerance   float64 // 距离容差，吸收网络抖动与浮点误差
+	MaxBudgetMs int64   // 最多累计的移动时间，防止长时间不上报后一次性瞬移
+}
+
+// DefaultConfig 为默认移动校验参数。
+var DefaultConfig = Config{
+	Tolerance:   0.5,
+	MaxBudgetMs: 1000,
+}
+
+// MoveManager 为实体的移动模块，服务端权威：
+//   - 角色：校验客户端上报的位置，距离超出速度允许范围、死亡或被控制时拉回（OnSnap）
+//   - NPC：服务端沿路点按速度逐帧插值移动（MoveTo / MovePath）
+//
+// 速度 = AttrType_Speed / SpeedPrecision * 减速倍率（CombatManager.MoveSpeedRate），
+// 挂载战斗模块时 AttrType_Speed 取自其属性集（含 Buff 修正）。
+// 客户端每次上报消耗移动额度，额度按速度随帧累计、上限为 MaxBudgetMs 内可移动的距离，
+// 因此中途被减速或定身时允许的距离随之减少。
+type MoveManager struct {
+	owner izone.IEntity
+	attrs data.IAttrs
+	cfg   Config
+
+	budget float64      // 当前可移动的距离
+	seq    int64        // 最近处理的客户端移动序号
+	path   []*pb.Vector // 服务端驱动移动的剩余路点
+
+	// OnSnap 拒绝客户端移动并拉回权威位置时的回调，由网络层下发给移动者
+	OnSnap func(dsp *pb.DspMoveSnap)
+	// OnArrive 服务端驱动移动到达终点时的回调
+	OnArrive func()
+}
+
+func (m *MoveManager) Init(owner izone.IEntity, initData data.EntityInitData) {
+	m.owner = owner
+	if cm := combat.GetCombatManager(owner); cm != nil {
+		m.attrs = cm.GetAttrs()
+	} else if initData.Attrs != nil {
+		m.attrs = initData.Attrs
+	}
+	m.cfg = DefaultConfig
+	m.budget = m.maxBudget(m.Speed())
+}
+
+// Update 累计移动额度，并推进服务端驱动的移动。
+func (m *MoveManager) Update(duration int64) {
+	if duration <= 0 {
+		return
+	}
+	speed := m.Speed()
+	m.budget = min(m.budget+speed*float64(duration)/1000, m.maxBudget(speed))
+	if len(m.path) > 0 && speed > 0 {
+		m.step(speed * float64(duration) / 1000)
+	}
+}
+
+// SetConfig 设置移动校验参数。
+func (m *MoveManager) SetConfig(cfg Config) {
+	m.cfg = cfg
+	m.budget = min(m.budget, m.maxBudget(m.Speed()))
+}
+
+func (m *MoveManager) maxBudget(speed float64) float64 {
+	return speed * float64(m.cfg.MaxBudgetMs) / 1000
+}
+
+// Speed 获取当前移动速度（距离单位/秒），死亡、眩晕、定身时为 0。
+func (m *MoveManager) Speed() float64 {
+	if m.check() != MoveResult_Success || m.attrs == nil {
+		return 0
+	}
+	speed := float64(m.attrs.GetValue(enum.AttrType_Speed)) / SpeedPrecision
+	if cm := combat.GetCombatManager(m.owner); cm != nil {
+		speed *= cm.MoveSpeedRate()
+	}
+	return max(speed, 0)
+}
+
+// check 校验实体当前能否移动。
+func (m *MoveManager) check() MoveResult {
+	cm := combat.GetCombatManager(m.owner)
+	if cm == nil {
+		return MoveResult_Success
+	}
+	if cm.IsDead() {
+		return MoveResult_Dead
+	}
+	if cm.HasControl(conf.CCType_Stun) {
+		return MoveResult_Stunned
+	}
+	if cm.HasControl(conf.CCType_Root) {
+		return MoveResult_Rooted
+	}
+	return MoveResult_Success
+}
+
+// HandleMove 处理客户端移动请求：
+//  1. 序号不大于已处理序号的请求忽略
+//  2. 死亡、眩晕、定身时位置有变化则拉回
+//  3. 移动距离（含垂直方向）超出移动额度与容差之和时拉回
+//
+// 接受的移动会取消服务端驱动的移动。
+func (m *MoveManager) HandleMove(req *pb.ReqMove) MoveResult {
+	cur := m.owner.GetPos()
+	if req == nil || req.Pos == nil || cur == nil {
+		return MoveResult_BadRequest
+	}
+	if req.Seq <= m.seq {
+		return MoveResult_Stale
+	}
+	m.seq = req.Seq
+
+	dist := cur.Distance(req.Pos)
+	if r := m.check(); r != MoveResult_Success {
+		if dist > m.cfg.Tolerance {
+			m.snap(req.Seq)
+		}
+		return r
+	}
+	if dist > m.budget+m.cfg.Tolerance {
+		m.snap(req.Seq)
+		return MoveResult_TooFast
+	}
+
+	m.budget = max(m.budget-dist, 0)
+	m.path = nil
+	m.owner.SetPos(req.Pos.Copy())
+	m.owner.SetDir(req.Dir)
+	return MoveResult_Success
+}
+
+// snap 将客户端拉回服务端位置。
+func (m *MoveManager) snap(seq int64) {
+	if m.OnSnap == nil {
+		return
+	}
+	m.OnSnap(&pb.DspMoveSnap{
+		Id:  int64(m.owner.GetId()),
+		Seq: seq,
+		Pos: m.owner.GetPos().Copy(),
+		Dir: m.owner.GetDir(),
+	})
+}
+
+// MoveTo 服务端驱动移动到目标位置。
+func (m *MoveManager) MoveTo(dest *pb.Vector) {
+	if dest == nil {
+		return
+	}
+	m.MovePath([]*pb.Vector{dest})
+}
+
+// MovePath 服务端驱动沿路点依次移动，替换当前路径；被控制时暂停，解除后继续。
+func (m *MoveManager) MovePath(points []*pb.Vector) {
+	m.path = m.path[:0]
+	for _, p := range points {
+		if p != nil {
+			m.path = append(m.path, p.Copy())
+		}
+	}
+}
+
+// Stop 停止服务端驱动的移动。
+func (m *MoveManager) Stop() {
+	m.path = nil
+}
+
+// IsMoving 判断是否正在服务端驱动移动。
+func (m *MoveManager) IsMoving() bool {
+	return len(m.path) > 0
+}
+
+// step 沿路点前进 dist 距离，朝向转为最后一段的移动方向。
+func (m *MoveManager) step(dist float64) {
+	pos := m.owner.GetPos()
+	if pos == nil {
+		m.path = nil
+		return
+	}
+	var dir *pb.Vector
+	for dist > 0 && len(m.path) > 0 {
+		target := m.path[0]
+		seg := target.Sub2D(pos)
+		d := seg.Length2D()
+		if d > 0 {
+			dir = seg
+		}
+		if d <= dist {
+			pos = target.CopyNewZ(pos.Z)
+			dist -= d
+			m.path = m.path[1:]
+			continue
+		}
+		pos = pos.Add2D(seg.Norm2D().Mul2D(dist))
+		dist = 0
+	}
+
+	m.owner.SetPos(pos)
+	if dir != nil {
+		m.owner.SetDir(int32(math.Round(dir.ToAngle2D())))
+	}
+	if len(m.path) == 0 {
+		m.path = nil
+		if m.OnArrive != nil {
+			m.OnArrive()
+		}
+	}
+}
+
+// GetMoveManager 获取实体的移动模块，未挂载时返回 nil。
+func GetMoveManager(e izone.IEntity) *MoveManager {
+	if e == nil {
+		return nil
+	}
+	m, _ := e.GetModule(izone.ModuleType_Move).(*MoveManager)
+	return m
+}
@@ -0,0 +1,200 @@
+package move
+
+import (
+	"testing"
+
+	"server/data"
+	"server/data/conf"
+	"server/data/enum"
+	"server/lib/uid"
+	"server/pb"
+	"server/service/world/zone/entity/mod/combat"
+	"server/service/world/zone/izone"
+)
+
+// testEntity 为测试用的最小 IEntity 实现，挂载战斗与移动模块，不加入区域。
+type testEntity struct {
+	id      uid.Uid
+	pos     *pb.Vector
+	dir     int32
+	modules [izone.ModuleType_Max]izone.IModule
+}
+
+func (e *testEntity) Init(_ izone.IZone, initData data.EntityInitData) {
+	e.id = uid.Gen()
+	e.modules[izone.ModuleType_Combat] = &combat.CombatManager{}
+	e.modules[izone.ModuleType_Move] = &MoveManager{}
+	for _, m := range e.modules {
+		if m != nil {
+			m.Init(e, initData)
+		}
+	}
+}
+
+func (e *testEntity) Update(duration int64) {
+	for _, m := range e.modules {
+		if m != nil {
+			m.Update(duration)
+		}
+	}
+}
+
+func (e *testEntity) GetZone() izone.IZone                       { return nil }
+func (e *testEntity) GetId() uid.Uid                             { return e.id }
+func (e *testEntity) GetType() enum.EntityType                   { return enum.EntityType_Role }
+func (e *testEntity) GetPos() *pb.Vector                         { return e.pos }
+func (e *testEntity) SetPos(pos *pb.Vector)                      { e.pos = pos }
+func (e *testEntity) GetDir() int32                              { return e.dir }
+func (e *testEntity) SetDir(dir int32)                           { e.dir = dir }
+func (e *testEntity) GetModule(t izone.ModuleType) izone.IModule { return e.modules[t] }
+
+// newMover 创建速度为 speed（距离单位/秒）的实体。
+func newMover(speed float64) (*testEntity, *MoveManager) {
+	attrs := data.Attrs{
+		{Type: enum.AttrType_MaxHp, Val: 100},
+		{Type: enum.AttrType_Speed, Val: int64(speed * SpeedPrecision)},
+	}
+	e := &testEntity{pos: pb.NewVector(0, 0, 0)}
+	e.Init(nil, data.EntityInitData{Attrs: &attrs})
+	return e, GetMoveManager(e)
+}
+
+func TestMoveManager_HandleMove(t *testing.T) {
+	e, m := newMover(10)
+	var snaps []*pb.DspMoveSnap
+	m.OnSnap = func(dsp *pb.DspMoveSnap) { snaps = append(snaps, dsp) }
+
+	// 初始额度为 MaxBudgetMs 内的距离：10
+	if r := m.HandleMove(&pb.ReqMove{Seq: 1, Pos: pb.NewVector(8, 0, 0), Dir: 90}); r != MoveResult_Success {
+		t.Fatalf("Expected Success, got %v", r)
+	}
+	if e.pos.X != 8 || e.dir != 90 {
+		t.Errorf("Expected pos (8,0) dir 90, got %s dir %d", e.pos.StringF(), e.dir)
+	}
+
+	// 剩余额度 2 + 容差 0.5，移动 5 被拉回
+	if r := m.HandleMove(&pb.ReqMove{Seq: 2, Pos: pb.NewVector(13, 0, 0)}); r != MoveResult_TooFast {
+		t.Errorf("Expected TooFast, got %v", r)
+	}
+	if len(snaps) != 1 || snaps[0].Seq != 2 || snaps[0].Pos.X != 8 || e.pos.X != 8 {
+		t.Errorf("Expected snap back to x=8, got %+v", snaps)
+	}
+
+	// 过期序号忽略
+	if r := m.HandleMove(&pb.ReqMove{Seq: 2, Pos: pb.NewVector(8, 1, 0)}); r != MoveResult_Stale {
+		t.Errorf("Expected Stale, got %v", r)
+	}
+
+	// 300ms 后额度恢复到 2 + 3 = 5
+	e.Update(300)
+	if r := m.HandleMove(&pb.ReqMove{Seq: 3, Pos: pb.NewVector(13, 0, 0)}); r != MoveResult_Success {
+		t.Errorf("Expected Success after budget refill, got %v", r)
+	}
+
+	// 长时间不上报，额度最多累计 MaxBudgetMs
+	e.Update(5000)
+	if r := m.HandleMove(&pb.ReqMove{Seq: 4, Pos: pb.NewVector(33, 0, 0)}); r != MoveResult_TooFast {
+		t.Errorf("Expected TooFast beyond capped budget, got %v", r)
+	}
+}
+
+func TestMoveManager_HandleMoveVertical(t *testing.T) {
+	e, m := newMover(10)
+
+	// 平面不动、垂直方向瞬移同样受移动额度限制
+	if r := m.HandleMove(&pb.ReqMove{Seq: 1, Pos: pb.NewVector(0, 0, 50)}); r != MoveResult_TooFast {
+		t.Errorf("Expected TooFast for vertical teleport, got %v", r)
+	}
+	if e.pos.Z != 0 {
+		t.Errorf("Expected z unchanged, got %s", e.pos.StringF())
+	}
+
+	// 额度按三维距离扣除：(6, 0, 8) 距离 10
+	if r := m.HandleMove(&pb.ReqMove{Seq: 2, Pos: pb.NewVector(6, 0, 8)}); r != MoveResult_Success {
+		t.Errorf("Expected Success within budget, got %v", r)
+	}
+	if r := m.HandleMove(&pb.ReqMove{Seq: 3, Pos: pb.NewVector(6, 0, 9)}); r != MoveResult_TooFast {
+		t.Errorf("Expected TooFast after budget spent, got %v", r)
+	}
+}
+
+func TestMoveManager_SlowAndControl(t *testing.T) {
+	e, m := newMover(10)
+	cm := combat.GetCombatManager(e)
+	snapped := 0
+	m.OnSnap = func(*pb.DspMoveSnap) { snapped++ }
+
+	// 减速 50%：额度上限降为 5
+	cm.AddMoveSpeedPct(-0.5)
+	e.Update(100)
+	if s := m.Speed(); s != 5 {
+		t.Errorf("Expected slowed speed 5, got %v", s)
+	}
+	if r := m.HandleMove(&pb.ReqMove{Seq: 1, Pos: pb.NewVector(7, 0, 0)}); r != MoveResult_TooFast {
+		t.Errorf("Expected TooFast while slowed, got %v", r)
+	}
+	cm.RemoveMoveSpeedPct(-0.5)
+
+	cm.AddControl(conf.CCType_Root)
+	if r := m.HandleMove(&pb.ReqMove{Seq: 2, Pos: pb.NewVector(1, 0, 0)}); r != MoveResult_Rooted {
+		t.Errorf("Expected Rooted, got %v", r)
+	}
+	// 定身时原地转向不拉回
+	if r := m.HandleMove(&pb.ReqMove{Seq: 3, Pos: pb.NewVector(0, 0, 0), Dir: 45}); r != MoveResult_Rooted {
+		t.Errorf("Expected Rooted, got %v", r)
+	}
+	cm.RemoveControl(conf.CCType_Root)
+
+	cm.AddControl(conf.CCType_Stun)
+	if r := m.HandleMove(&pb.ReqMove{Seq: 4, Pos: pb.NewVector(1, 0, 0)}); r != MoveResult_Stunned {
+		t.Errorf("Expected Stunned, got %v", r)
+	}
+	cm.RemoveControl(conf.CCType_Stun)
+
+	cm.Kill(nil)
+	if r := m.HandleMove(&pb.ReqMove{Seq: 5, Pos: pb.NewVector(1, 0, 0)}); r != MoveResult_Dead {
+		t.Errorf("Expected Dead, got %v", r)
+	}
+	if snapped != 4 || e.pos.X != 0 {
+		t.Errorf("Expected 4 snaps and no movement, got %d snaps at %s", snapped, e.pos.StringF())
+	}
+}
+
+func TestMoveManager_MovePath(t *testing.T) {
+	e, m := newMover(10)
+	arrived := 0
+	m.OnArrive = func() { arrived++ }
+	m.MovePath([]*pb.Vector{pb.NewVector(3, 0, 0), pb.NewVector(3, 4, 0)})
+
+	// 每 100ms 前进 1
+	e.Update(100)
+	if !e.pos.ApproximatelyEqual2D(pb.NewVector(1, 0, 0)) || e.dir != 0 {
+		t.Errorf("Expected (1,0) facing 0, got %s dir %d", e.pos.StringF(), e.dir)
+	}
+	// 跨过路点后朝向转为下一段
+	e.Update(300)
+	if !e.pos.ApproximatelyEqual2D(pb.NewVector(3, 1, 0)) || e.dir != 90 {
+		t.Errorf("Expected (3,1) facing 90, got %s dir %d", e.pos.StringF(), e.dir)
+	}
+
+	// 定身时暂停，解除后继续
+	cm := combat.GetCombatManager(e)
+	cm.AddControl(conf.CCType_Root)
+	e.Update(100)
+	if !e.pos.ApproximatelyEqual2D(pb.NewVector(3, 1, 0)) {
+		t.Errorf("Expected no movement while rooted, got %s", e.pos.StringF())
+	}
+	cm.RemoveControl(conf.CCType_Root)
+
+	e.Update(1000)
+	if !e.pos.ApproximatelyEqual2D(pb.NewVector(3, 4, 0)) || m.IsMoving() || arrived != 1 {
+		t.Errorf("Expected arrival at (3,4), got %s moving=%v arrived=%d", e.pos.StringF(), m.IsMoving(), arrived)
+	}
+
+	// 接受客户端移动会取消服务端驱动的移动
+	m.MoveTo(pb.NewVector(10, 4, 0))
+	m.HandleMove(&pb.ReqMove{Seq: 1, Pos: pb.NewVector(3, 5, 0)})
+	if m.IsMoving() {
+		t.Errorf("Expected client move to cancel server path")
+	}
+}
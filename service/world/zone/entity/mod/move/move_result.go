@@ -0,0 +1,40 @@
+package move
+
+import "fmt"
+
+// MoveResult 为处理客户端移动请求的结果。
+type MoveResult int32
+
+const (
+	MoveResult_Invalid    MoveResult = 0
+	MoveResult_Success    MoveResult = 1 // 成功
+	MoveResult_BadRequest MoveResult = 2 // 请求缺少位置或实体尚未放置
+	MoveResult_Stale      MoveResult = 3 // 序号过期（乱序或重复），忽略
+	MoveResult_Dead       MoveResult = 4 // 已死亡
+	MoveResult_Stunned    MoveResult = 5 // 被眩晕
+	MoveResult_Rooted     MoveResult = 6 // 被定身
+	MoveResult_TooFast    MoveResult = 7 // 移动距离超出速度允许范围
+)
+
+var moveResultNames = map[MoveResult]string{
+	MoveResult_Invalid:    "Invalid",
+	MoveResult_Success:    "Success",
+	MoveResult_BadRequest: "BadRequest",
+	MoveResult_Stale:      "Stale",
+	MoveResult_Dead:       "Dead",
+	MoveResult_Stunned:    "Stunned",
+	MoveResult_Rooted:     "Rooted",
+	MoveResult_TooFast:    "TooFast",
+}
+
+func (r MoveResult) String() string {
+	if name, ok := moveResultNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("MoveResult(%d)", int32(r))
+}
+
+// Ok 判断移动是否被接受。
+func (r MoveResult) Ok() bool {
+	return r == MoveResult_Success
+}
@@ -0,0 +1,163 @@
+package frame
+
+import (
+	"time"
+)
+
+// DefaultHz 为默认帧率。
+const DefaultHz = 20
+
+// MaxHz 为最大帧率，保证每帧至少推进 1 毫秒逻辑时间。
+const MaxHz = 1000
+
+// MaxCatchUp 为落后时单次最多补帧数，超出部分直接丢弃（记入 Stats.Dropped），避免雪崩。
+const MaxCatchUp = 5
+
+// Stats 为帧循环的运行统计。
+type Stats struct {
+	Frames   uint64        // 已执行帧数
+	Overruns uint64        // 单帧耗时超过帧间隔的次数
+	Dropped  uint64        // 落后过多被丢弃的帧数
+	LastCost time.Duration // 最近一帧耗时
+	MaxCost  time.Duration // 最大单帧耗时
+}
+
+// Loop 为固定帧率的帧循环：每帧按帧间隔推进逻辑时间并调用 frame，保证逻辑推进与墙钟时间无关。
+// 帧间隔不是整毫秒时，不足 1 毫秒的部分累计到后续帧（30Hz 时依次为 33/33/34ms），每 hz 帧恰好推进 1 秒，逻辑时间不会漂移。
+// Loop 自身不启动协程：Start 后由所属服务的定时器在服务协程中周期调用 Poll 执行到期的帧，
+// 执行较慢导致落后时会补帧（最多 MaxCatchUp 帧），仍落后则丢弃多余的帧。
+//
+// 注意：非线程安全，所有方法只在同一协程（服务协程）中调用。
+type Loop struct {
+	interval time.Duration
+	deltaMs  int64
+	frame    func(deltaMs int64)
+
+	hz    int64
+	phase int64 // 当前秒内已执行的帧数
+
+	running bool
+	paused  bool
+	next    time.Time // 下一帧的到期时间
+
+	stats Stats
+}
+
+// NewLoop 创建帧循环，hz <= 0 时使用 DefaultHz，超过 MaxHz 时使用 MaxHz。
+func NewLoop(hz int, frame func(deltaMs int64)) *Loop {
+	if hz <= 0 {
+		hz = DefaultHz
+	}
+	hz = min(hz, MaxHz)
+	interval := time.Second / time.Duration(hz)
+	return &Loop{
+		interval: interval,
+		deltaMs:  interval.Milliseconds(),
+		hz:       int64(hz),
+		frame:    frame,
+	}
+}
+
+// Interval 获取帧间隔。
+func (l *Loop) Interval() time.Duration {
+	return l.interval
+}
+
+// DeltaMs 获取每帧推进的基准逻辑时间（毫秒，向下取整），累计的余数会使部分帧多推进 1 毫秒。
+func (l *Loop) DeltaMs() int64 {
+	return l.deltaMs
+}
+
+// Start 从 now 开始按固定帧率计时，第一帧在 now 之后一个帧间隔到期。
+func (l *Loop) Start(now time.Time) {
+	l.running = true
+	l.next = now.Add(l.interval)
+}
+
+// Stop 停止计时，之后 Poll 不再执行帧。
+func (l *Loop) Stop() {
+	l.running = false
+}
+
+// Running 判断帧循环是否已启动。
+func (l *Loop) Running() bool {
+	return l.running
+}
+
+// Poll 执行截至 now 已到期的帧，返回执行的帧数。未启动或暂停时不执行帧，暂停期间从当前时间重新计时（恢复后不补帧）。
+func (l *Loop) Poll(now time.Time) int {
+	if !l.running {
+		return 0
+	}
+	if l.paused {
+		l.next = now.Add(l.interval)
+		return 0
+	}
+	n := 0
+	for !now.Before(l.next) {
+		if n == MaxCatchUp {
+			dropped := uint64(now.Sub(l.next)/l.interval) + 1
+			l.next = l.next.Add(time.Duration(dropped) * l.interval)
+			l.stats.Dropped += dropped
+			break
+		}
+		l.runFrame()
+		l.next = l.next.Add(l.interval)
+		n++
+	}
+	return n
+}
+
+// Step 立即同步执行 n 帧（用于测试或暂停时单步调试），帧循环运行中（已启动且未暂停）时不执行并返回 false。
+func (l *Loop) Step(n int) bool {
+	if l.running && !l.paused {
+		return false
+	}
+	for range n {
+		l.runFrame()
+	}
+	return true
+}
+
+// runFrame 执行一帧并记录耗时。
+func (l *Loop) runFrame() {
+	// 按整数比例分配每秒的 1000 毫秒，每 hz 帧恰好推进 1 秒
+	l.phase++
+	deltaMs := l.phase*1000/l.hz - (l.phase-1)*1000/l.hz
+	if l.phase == l.hz {
+		l.phase = 0
+	}
+
+	start := time.Now()
+	if l.frame != nil {
+		l.frame(deltaMs)
+	}
+	cost := time.Since(start)
+
+	l.stats.Frames++
+	l.stats.LastCost = cost
+	l.stats.MaxCost = max(l.stats.MaxCost, cost)
+	if cost > l.interval {
+		l.stats.Overruns++
+	}
+}
+
+// Pause 暂停帧循环。
+func (l *Loop) Pause() {
+	l.paused = true
+}
+
+// Resume 恢复帧循环。
+func (l *Loop) Resume() {
+	l.paused = false
+}
+
+// Paused 判断帧循环是否已暂停。
+func (l *Loop) Paused() bool {
+	return l.paused
+}
+
+// Stats 获取运行统计快照。
+func (l *Loop) Stats() Stats {
+	return l.stats
+}
@@ -0,0 +1,113 @@
+package frame
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoop_Step(t *testing.T) {
+	var total int64
+	var frames int
+	l := NewLoop(20, func(deltaMs int64) {
+		total += deltaMs
+		frames++
+	})
+	if l.DeltaMs() != 50 || l.Interval() != 50*time.Millisecond {
+		t.Errorf("Expected 50ms frames at 20Hz, got %dms", l.DeltaMs())
+	}
+
+	l.Step(3)
+	if frames != 3 || total != 150 {
+		t.Errorf("Expected 3 frames advancing 150ms, got %d frames %dms", frames, total)
+	}
+	if s := l.Stats(); s.Frames != 3 || s.Overruns != 0 {
+		t.Errorf("Unexpected stats: %+v", s)
+	}
+}
+
+func TestLoop_DefaultHz(t *testing.T) {
+	if l := NewLoop(0, nil); l.Interval() != time.Second/DefaultHz {
+		t.Errorf("Expected default interval, got %v", l.Interval())
+	}
+}
+
+func TestLoop_FractionalInterval(t *testing.T) {
+	var deltas []int64
+	var total int64
+	l := NewLoop(30, func(deltaMs int64) {
+		deltas = append(deltas, deltaMs)
+		total += deltaMs
+	})
+	l.Step(30)
+	if deltas[0] != 33 || deltas[1] != 33 || deltas[2] != 34 {
+		t.Errorf("Expected 33/33/34ms frames at 30Hz, got %v", deltas[:3])
+	}
+	if total != 1000 {
+		t.Errorf("Expected exactly 1000ms after 30 frames, got %d", total)
+	}
+
+	if l := NewLoop(2000, nil); l.Interval() != time.Millisecond || l.DeltaMs() != 1 {
+		t.Errorf("Expected hz capped at MaxHz, got %v", l.Interval())
+	}
+}
+
+func TestLoop_Overrun(t *testing.T) {
+	l := NewLoop(100, func(int64) { time.Sleep(15 * time.Millisecond) })
+	l.Step(1)
+	s := l.Stats()
+	if s.Overruns != 1 || s.LastCost < 15*time.Millisecond || s.MaxCost != s.LastCost {
+		t.Errorf("Expected overrun to be recorded, got %+v", s)
+	}
+}
+
+func TestLoop_PollAndPause(t *testing.T) {
+	var deltas []int64
+	l := NewLoop(200, func(deltaMs int64) { deltas = append(deltas, deltaMs) })
+	t0 := time.Unix(1000, 0)
+
+	// 未启动时不执行帧，Step 可用
+	if l.Poll(t0.Add(time.Second)) != 0 || !l.Step(1) {
+		t.Fatal("Expected Poll idle and Step allowed before Start")
+	}
+
+	l.Start(t0)
+	if n := l.Poll(t0.Add(4 * time.Millisecond)); n != 0 {
+		t.Errorf("Expected no frame before the first interval, got %d", n)
+	}
+	if n := l.Poll(t0.Add(15 * time.Millisecond)); n != 3 {
+		t.Errorf("Expected 3 due frames, got %d", n)
+	}
+	// 运行中拒绝单步
+	if l.Step(1) {
+		t.Error("Expected Step rejected while running")
+	}
+
+	// 暂停期间不执行帧且允许单步，恢复后从当前时间重新计时
+	l.Pause()
+	if l.Poll(t0.Add(100*time.Millisecond)) != 0 || !l.Step(1) {
+		t.Error("Expected no frames and Step allowed while paused")
+	}
+	l.Resume()
+	if n := l.Poll(t0.Add(104 * time.Millisecond)); n != 0 {
+		t.Errorf("Expected no catch-up after resume, got %d", n)
+	}
+	if n := l.Poll(t0.Add(105 * time.Millisecond)); n != 1 {
+		t.Errorf("Expected 1 frame after resume, got %d", n)
+	}
+
+	// 落后过多时最多补 MaxCatchUp 帧，其余丢弃
+	if n := l.Poll(t0.Add(155 * time.Millisecond)); n != MaxCatchUp || l.Stats().Dropped != 10-MaxCatchUp {
+		t.Errorf("Expected %d frames and %d dropped, got %d and %+v", MaxCatchUp, 10-MaxCatchUp, n, l.Stats())
+	}
+
+	l.Stop()
+	if l.Poll(t0.Add(time.Second)) != 0 || l.Running() {
+		t.Error("Expected no frames after Stop")
+	}
+	for _, d := range deltas {
+		if d != 5 {
+			t.Errorf("Expected constant 5ms delta, got %v", deltas)
+			break
+		}
+	}
+}
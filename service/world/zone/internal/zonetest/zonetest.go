@@ -0,0 +1,175 @@
+// Package zonetest 提供区域逻辑单元测试共用的最小 IZone/IEntity 实现，只应在测试中使用。
+package zonetest
+
+import (
+	"path/filepath"
+	"runtime"
+
+	"server/data"
+	"server/data/conf"
+	"server/data/enum"
+	"server/lib/container"
+	"server/lib/uid"
+	"server/pb"
+	"server/service/world/zone/clock"
+	"server/service/world/zone/izone"
+	"server/service/world/zone/spatial"
+)
+
+// StartMs 为测试区域时钟的起始时间（非 0，确保逻辑不依赖时间从 0 开始）。
+const StartMs = 1_000_000
+
+var (
+	_ izone.IZone   = (*Zone)(nil)
+	_ izone.IEntity = (*Entity)(nil)
+)
+
+// Zone 为测试用的最小 IZone 实现：实体立即加入/移除，空间查询使用真实的网格索引，时钟可手动设置。
+type Zone struct {
+	entities *container.LMap[uid.Uid, izone.IEntity]
+	grid     *spatial.Grid
+
+	Clock  *clock.Fake
+	Tables *conf.Tables
+}
+
+// NewZone 创建测试区域，tables 可以为 nil。
+func NewZone(tables *conf.Tables) *Zone {
+	return &Zone{
+		entities: container.NewLMap[uid.Uid, izone.IEntity](),
+		grid:     spatial.NewGrid(spatial.DefaultCellSize),
+		Clock:    clock.NewFake(StartMs),
+		Tables:   tables,
+	}
+}
+
+func (z *Zone) Init() {}
+
+func (z *Zone) AddEntity(e izone.IEntity) {
+	z.entities.Set(e.GetId(), e)
+	z.grid.Add(e)
+}
+
+func (z *Zone) RemoveEntity(id uid.Uid) {
+	z.entities.Delete(id)
+	z.grid.Remove(id)
+}
+
+func (z *Zone) GetEntity(id uid.Uid) (izone.IEntity, bool) { return z.entities.Get(id) }
+
+func (z *Zone) ForEach(fn func(e izone.IEntity)) { z.entities.ForEach(fn) }
+
+func (z *Zone) GetTables() *conf.Tables { return z.Tables }
+
+func (z *Zone) NowMs() int64 { return z.Clock.NowMs() }
+
+// Advance 模拟区域帧：推进时钟后更新所有实体。
+func (z *Zone) Advance(deltaMs int64) {
+	z.Clock.Advance(deltaMs)
+	z.entities.ForEach(func(e izone.IEntity) { e.Update(deltaMs) })
+}
+
+func (z *Zone) UpdateEntityPos(e izone.IEntity) { z.grid.Update(e) }
+
+func (z *Zone) QueryRadius(center *pb.Vector, radius float64, fn func(e izone.IEntity)) {
+	z.grid.QueryRadius(center, radius, fn)
+}
+
+func (z *Zone) QueryBox(lo, hi *pb.Vector, fn func(e izone.IEntity)) {
+	z.grid.QueryBox(lo, hi, fn)
+}
+
+func (z *Zone) QueryCone(center, facing *pb.Vector, radius, angle float64, fn func(e izone.IEntity)) {
+	z.grid.QueryCone(center, facing, radius, angle, fn)
+}
+
+// Modules 为测试实体挂载的模块构造函数（按模块类型），由调用方提供以避免本包依赖具体模块。
+type Modules [izone.ModuleType_Max]func() izone.IModule
+
+// Entity 为测试用的最小 IEntity 实现，类型为 NPC。
+type Entity struct {
+	id      uid.Uid
+	zone    izone.IZone
+	pos     *pb.Vector
+	dir     int32
+	mods    Modules
+	modules [izone.ModuleType_Max]izone.IModule
+}
+
+// NewEntity 创建位于 pos 的测试实体，Init 时按 mods 挂载模块并加入区域。
+func NewEntity(pos *pb.Vector, mods Modules) *Entity {
+	return &Entity{pos: pos, mods: mods}
+}
+
+func (e *Entity) Init(zone izone.IZone, initData data.EntityInitData) {
+	e.zone = zone
+	e.id = uid.Gen()
+	if zone != nil {
+		zone.AddEntity(e)
+	}
+	for t, newModule := range e.mods {
+		if newModule != nil {
+			e.modules[t] = newModule()
+		}
+	}
+	for _, m := range e.modules {
+		if m != nil {
+			m.Init(e, initData)
+		}
+	}
+}
+
+func (e *Entity) Update(duration int64) {
+	for _, m := range e.modules {
+		if m != nil {
+			m.Update(duration)
+		}
+	}
+}
+
+func (e *Entity) GetZone() izone.IZone { return e.zone }
+
+func (e *Entity) GetId() uid.Uid { return e.id }
+
+func (e *Entity) GetType() enum.EntityType { return enum.EntityType_Npc }
+
+func (e *Entity) GetPos() *pb.Vector { return e.pos }
+
+func (e *Entity) SetPos(pos *pb.Vector) {
+	e.pos = pos
+	if e.zone != nil {
+		e.zone.UpdateEntityPos(e)
+	}
+}
+
+func (e *Entity) GetDir() int32 { return e.dir }
+
+func (e *Entity) SetDir(dir int32) { e.dir = dir }
+
+func (e *Entity) GetModule(t izone.ModuleType) izone.IModule { return e.modules[t] }
+
+// NewAttrs 将属性表转换为实体初始化属性。
+func NewAttrs(attrs map[enum.AttrType]int64) *data.Attrs {
+	as := data.Attrs{}
+	for ty, val := range attrs {
+		as = append(as, &data.Attr{Type: ty, Val: val})
+	}
+	return &as
+}
+
+// LoadTables 加载仓库中的 conf/all.json，失败时 panic。
+func LoadTables() *conf.Tables {
+	_, file, _, _ := runtime.Caller(0)
+	tables, err := conf.LoadTables(filepath.Join(filepath.Dir(file), "../../../../../conf/all.json"))
+	if err != nil {
+		panic(err)
+	}
+	return tables
+}
+
+// FixedRand 总是返回固定掷骰值的随机源。
+type FixedRand int
+
+func (r FixedRand) IntN(n int) int {
+	return min(int(r), n-1)
+}
@@ -2,18 +2,25 @@ package izone
 
 import (
 	"server/data"
+	"server/data/enum"
 	"server/lib/uid"
 	"server/pb"
 )
 
 type IEntity interface {
 	Init(zone IZone, initData data.EntityInitData)
+	// Update 推进实体逻辑，由区域帧循环每帧调用。
+	Update(duration int64)
 
 	GetZone() IZone
 
 	GetId() uid.Uid
+	GetType() enum.EntityType
 	GetPos() *pb.Vector
 	SetPos(pos *pb.Vector)
 	GetDir() int32
 	SetDir(dir int32)
+
+	// GetModule 获取实体模块，未挂载时返回 nil。
+	GetModule(t ModuleType) IModule
 }
@@ -2,6 +2,16 @@ package izone
 
 import "server/data"
 
+// ModuleType 实体模块类型，同时作为实体模块数组的下标。
+type ModuleType = int
+
+const (
+	ModuleType_Combat ModuleType = iota
+	ModuleType_Move
+	ModuleType_Buff
+	ModuleType_Max
+)
+
 type IModule interface {
 	Init(owner IEntity, initData data.EntityInitData)
 	Update(duration int64)
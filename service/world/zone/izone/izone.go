@@ -1,6 +1,10 @@
 package izone
 
-import "server/lib/uid"
+import (
+	"server/data/conf"
+	"server/lib/uid"
+	"server/pb"
+)
 
 type IZone interface {
 	Init()
@@ -8,4 +12,17 @@ type IZone interface {
 	RemoveEntity(id uid.Uid)
 	GetEntity(id uid.Uid) (IEntity, bool)
 	ForEach(fn func(e IEntity))
+	GetTables() *conf.Tables
+
+	// NowMs 获取区域逻辑时间（毫秒），区域内所有技能、效果、Buff、冷却统一读取该时间。
+	NowMs() int64
+
+	// UpdateEntityPos 实体位置变化后更新空间索引，由实体的 SetPos 调用。
+	UpdateEntityPos(e IEntity)
+	// QueryRadius 遍历与 center 平面距离不超过 radius 的实体。
+	QueryRadius(center *pb.Vector, radius float64, fn func(e IEntity))
+	// QueryBox 遍历位于轴对齐矩形 [lo, hi] 内的实体。
+	QueryBox(lo, hi *pb.Vector, fn func(e IEntity))
+	// QueryCone 遍历以 center 为顶点、沿 facing 展开、半径 radius、总角度 angle（度）的扇形内的实体。
+	QueryCone(center, facing *pb.Vector, radius, angle float64, fn func(e IEntity))
 }
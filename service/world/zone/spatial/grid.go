@@ -0,0 +1,234 @@
+package spatial
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"server/lib/uid"
+	"server/pb"
+	"server/service/world/zone/izone"
+)
+
+// DefaultCellSize 为默认格子边长（与常见技能范围同一量级）。
+const DefaultCellSize = 10.0
+
+// Epsilon 为范围边界判定的浮点容差，边界上的点视为在范围内。
+const Epsilon = 1e-6
+
+// cellKey 为格子坐标。
+type cellKey struct {
+	x, y int32
+}
+
+// item 为实体在索引中的记录。
+type item struct {
+	e      izone.IEntity
+	seq    uint64  // 加入索引的顺序，查询结果按此排序以保证确定性
+	cell   cellKey // 所在格子
+	index  int     // 在格子列表中的下标
+	placed bool    // 是否在格子中（位置为空的实体不参与空间查询）
+}
+
+// Grid 为 XOY 平面上的均匀网格空间索引。
+// 实体按位置落入边长为 cellSize 的格子，范围查询只遍历与查询范围包围盒相交的格子。
+// 查询结果按实体加入索引的顺序回调，与 ForEach 的顺序一致。
+// 注意：非线程安全，只在区域逻辑线程内使用。
+type Grid struct {
+	cellSize float64
+	seq      uint64
+	items    map[uid.Uid]*item
+	cells    map[cellKey][]*item
+}
+
+// NewGrid 创建网格索引，cellSize <= 0 时使用 DefaultCellSize。
+func NewGrid(cellSize float64) *Grid {
+	if cellSize <= 0 {
+		cellSize = DefaultCellSize
+	}
+	return &Grid{
+		cellSize: cellSize,
+		items:    make(map[uid.Uid]*item),
+		cells:    make(map[cellKey][]*item),
+	}
+}
+
+// Len 获取索引中的实体数量。
+func (g *Grid) Len() int {
+	return len(g.items)
+}
+
+// keyOf 计算坐标所在的格子。
+func (g *Grid) keyOf(x, y float64) cellKey {
+	return cellKey{int32(math.Floor(x / g.cellSize)), int32(math.Floor(y / g.cellSize))}
+}
+
+// Add 将实体加入索引，已存在时等同于 Update。
+func (g *Grid) Add(e izone.IEntity) {
+	if e == nil {
+		return
+	}
+	if _, ok := g.items[e.GetId()]; ok {
+		g.Update(e)
+		return
+	}
+	g.seq++
+	it := &item{e: e, seq: g.seq}
+	g.items[e.GetId()] = it
+	g.place(it)
+}
+
+// Remove 将实体移出索引。
+func (g *Grid) Remove(id uid.Uid) {
+	it, ok := g.items[id]
+	if !ok {
+		return
+	}
+	g.unplace(it)
+	delete(g.items, id)
+}
+
+// Update 实体位置变化后更新所在格子，未加入索引的实体忽略。
+func (g *Grid) Update(e izone.IEntity) {
+	if e == nil {
+		return
+	}
+	it, ok := g.items[e.GetId()]
+	if !ok {
+		return
+	}
+	pos := e.GetPos()
+	if it.placed && pos != nil && g.keyOf(pos.X, pos.Y) == it.cell {
+		return
+	}
+	g.unplace(it)
+	g.place(it)
+}
+
+// place 将实体放入当前位置所在的格子。
+func (g *Grid) place(it *item) {
+	pos := it.e.GetPos()
+	if pos == nil {
+		return
+	}
+	it.cell = g.keyOf(pos.X, pos.Y)
+	it.placed = true
+	it.index = len(g.cells[it.cell])
+	g.cells[it.cell] = append(g.cells[it.cell], it)
+}
+
+// unplace 将实体从所在格子中移除（与末尾元素交换后删除），空格子会被回收。
+func (g *Grid) unplace(it *item) {
+	if !it.placed {
+		return
+	}
+	it.placed = false
+	cell := g.cells[it.cell]
+	last := len(cell) - 1
+	if it.index != last {
+		cell[it.index] = cell[last]
+		cell[it.index].index = it.index
+	}
+	cell[last] = nil
+	if last == 0 {
+		delete(g.cells, it.cell)
+	} else {
+		g.cells[it.cell] = cell[:last]
+	}
+}
+
+// query 遍历与包围盒相交的格子，按加入顺序回调满足 match 的实体。
+func (g *Grid) query(minX, minY, maxX, maxY float64, match func(p *pb.Vector) bool, fn func(e izone.IEntity)) {
+	if fn == nil || minX > maxX || minY > maxY {
+		return
+	}
+	lo, hi := g.keyOf(minX, minY), g.keyOf(maxX, maxY)
+
+	var found []*item
+	if int64(hi.x-lo.x+1)*int64(hi.y-lo.y+1) > int64(len(g.cells)) {
+		// 查询范围覆盖的格子数多于非空格子数时直接遍历非空格子
+		for key, cell := range g.cells {
+			if key.x < lo.x || key.x > hi.x || key.y < lo.y || key.y > hi.y {
+				continue
+			}
+			found = appendMatched(found, cell, match)
+		}
+	} else {
+		for x := lo.x; x <= hi.x; x++ {
+			for y := lo.y; y <= hi.y; y++ {
+				if cell, ok := g.cells[cellKey{x, y}]; ok {
+					found = appendMatched(found, cell, match)
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(found, func(a, b *item) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+	for _, it := range found {
+		fn(it.e)
+	}
+}
+
+func appendMatched(found []*item, cell []*item, match func(p *pb.Vector) bool) []*item {
+	for _, it := range cell {
+		if match(it.e.GetPos()) {
+			found = append(found, it)
+		}
+	}
+	return found
+}
+
+// QueryRadius 遍历与 center 平面距离不超过 radius 的实体。
+func (g *Grid) QueryRadius(center *pb.Vector, radius float64, fn func(e izone.IEntity)) {
+	if center == nil || radius < 0 {
+		return
+	}
+	rSq := radius*radius + Epsilon
+	g.query(center.X-radius, center.Y-radius, center.X+radius, center.Y+radius, func(p *pb.Vector) bool {
+		return p.DistanceSq2D(center) <= rSq
+	}, fn)
+}
+
+// QueryBox 遍历位于轴对齐矩形 [lo, hi] 内的实体（含边界）。
+func (g *Grid) QueryBox(lo, hi *pb.Vector, fn func(e izone.IEntity)) {
+	if lo == nil || hi == nil {
+		return
+	}
+	g.query(lo.X, lo.Y, hi.X, hi.Y, func(p *pb.Vector) bool {
+		return p.X >= lo.X && p.X <= hi.X && p.Y >= lo.Y && p.Y <= hi.Y
+	}, fn)
+}
+
+// QueryCone 遍历以 center 为顶点、沿 facing 展开、半径 radius、总角度 angle（度）的扇形内的实体。
+func (g *Grid) QueryCone(center, facing *pb.Vector, radius, angle float64, fn func(e izone.IEntity)) {
+	if center == nil || radius < 0 {
+		return
+	}
+	g.query(center.X-radius, center.Y-radius, center.X+radius, center.Y+radius, func(p *pb.Vector) bool {
+		return InCone(center, facing, p, radius, angle)
+	}, fn)
+}
+
+// InCone 判断点 p 是否在以 center 为顶点、沿 facing 展开、半径 radius、总角度 angle（度）的扇形内。
+// center 上的点视为命中；facing 为空或长度为 0 时使用 ForwardVector。
+func InCone(center, facing, p *pb.Vector, radius, angle float64) bool {
+	if center == nil || p == nil || radius <= 0 {
+		return false
+	}
+	d := p.Sub2D(center)
+	distSq := d.LengthSq2D()
+	if distSq > radius*radius+Epsilon {
+		return false
+	}
+	if distSq <= Epsilon {
+		return true
+	}
+	dir := pb.ForwardVector
+	if facing != nil {
+		dir = facing.Norm2D()
+	}
+	half := angle / 2 * math.Pi / 180
+	return d.Norm2D().Dot2D(dir) >= math.Cos(half)-Epsilon
+}
@@ -0,0 +1,94 @@
+package spatial
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"server/lib/container"
+	"server/lib/uid"
+	"server/pb"
+	"server/service/world/zone/izone"
+)
+
+// Benchmark: Grid 范围查询 vs LMap 全量遍历（1000x1000 场景内随机分布的实体）
+
+const benchWorldSize = 1000
+
+func benchEntities(n int) []*testEntity {
+	rng := rand.New(rand.NewPCG(1, 2))
+	es := make([]*testEntity, n)
+	for i := range es {
+		es[i] = newEntity(int64(i+1), rng.Float64()*benchWorldSize, rng.Float64()*benchWorldSize)
+	}
+	return es
+}
+
+func benchGrid(n int) *Grid {
+	g := NewGrid(DefaultCellSize)
+	for _, e := range benchEntities(n) {
+		g.Add(e)
+	}
+	return g
+}
+
+func benchLMap(n int) *container.LMap[uid.Uid, izone.IEntity] {
+	m := container.NewLMap[uid.Uid, izone.IEntity]()
+	for _, e := range benchEntities(n) {
+		m.Set(e.id, e)
+	}
+	return m
+}
+
+func benchmarkGridQueryRadius(b *testing.B, n int) {
+	g := benchGrid(n)
+	center := pb.NewVector(benchWorldSize/2, benchWorldSize/2, 0)
+	count := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.QueryRadius(center, 30, func(e izone.IEntity) { count++ })
+	}
+}
+
+func benchmarkLMapScanRadius(b *testing.B, n int) {
+	m := benchLMap(n)
+	center := pb.NewVector(benchWorldSize/2, benchWorldSize/2, 0)
+	count := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.ForEach(func(e izone.IEntity) {
+			if e.GetPos().DistanceSq2D(center) <= 30*30 {
+				count++
+			}
+		})
+	}
+}
+
+func BenchmarkGrid_QueryRadius_1000(b *testing.B)  { benchmarkGridQueryRadius(b, 1000) }
+func BenchmarkLMap_ScanRadius_1000(b *testing.B)   { benchmarkLMapScanRadius(b, 1000) }
+func BenchmarkGrid_QueryRadius_10000(b *testing.B) { benchmarkGridQueryRadius(b, 10000) }
+func BenchmarkLMap_ScanRadius_10000(b *testing.B)  { benchmarkLMapScanRadius(b, 10000) }
+
+func BenchmarkGrid_QueryCone_10000(b *testing.B) {
+	g := benchGrid(10000)
+	center := pb.NewVector(benchWorldSize/2, benchWorldSize/2, 0)
+	facing := pb.NewVector(1, 0, 0)
+	count := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.QueryCone(center, facing, 30, 60, func(e izone.IEntity) { count++ })
+	}
+}
+
+func BenchmarkGrid_Update(b *testing.B) {
+	es := benchEntities(10000)
+	g := NewGrid(DefaultCellSize)
+	for _, e := range es {
+		g.Add(e)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e := es[i%len(es)]
+		e.pos.X = float64((int(e.pos.X) + 3) % benchWorldSize)
+		g.Update(e)
+	}
+}
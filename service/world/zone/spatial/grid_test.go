@@ -0,0 +1,158 @@
+package spatial
+
+import (
+	"slices"
+	"testing"
+
+	"server/data"
+	"server/data/enum"
+	"server/lib/uid"
+	"server/pb"
+	"server/service/world/zone/izone"
+)
+
+// testEntity 为测试用的最小 IEntity 实现。
+type testEntity struct {
+	id  uid.Uid
+	pos *pb.Vector
+}
+
+func (e *testEntity) Init(izone.IZone, data.EntityInitData)    {}
+func (e *testEntity) Update(int64)                             {}
+func (e *testEntity) GetZone() izone.IZone                     { return nil }
+func (e *testEntity) GetType() enum.EntityType                 { return enum.EntityType_Npc }
+func (e *testEntity) GetId() uid.Uid                           { return e.id }
+func (e *testEntity) GetPos() *pb.Vector                       { return e.pos }
+func (e *testEntity) SetPos(pos *pb.Vector)                    { e.pos = pos }
+func (e *testEntity) GetDir() int32                            { return 0 }
+func (e *testEntity) SetDir(int32)                             {}
+func (e *testEntity) GetModule(izone.ModuleType) izone.IModule { return nil }
+
+func newEntity(id int64, x, y float64) *testEntity {
+	return &testEntity{id: uid.Uid(id), pos: pb.NewVector(x, y, 0)}
+}
+
+func collect(query func(fn func(e izone.IEntity))) []uid.Uid {
+	var ids []uid.Uid
+	query(func(e izone.IEntity) { ids = append(ids, e.GetId()) })
+	return ids
+}
+
+func TestGrid_QueryRadius(t *testing.T) {
+	g := NewGrid(10)
+	// 加入顺序与 id 不一致，结果应按加入顺序
+	g.Add(newEntity(3, 25, 0))
+	g.Add(newEntity(1, 3, 4))
+	g.Add(newEntity(2, -9, -9))
+	g.Add(newEntity(4, 5, 0.1))
+	g.Add(&testEntity{id: 5}) // 无位置的实体不参与空间查询
+
+	got := collect(func(fn func(e izone.IEntity)) { g.QueryRadius(pb.NewVector(0, 0, 0), 5, fn) })
+	if !slices.Equal(got, []uid.Uid{1}) {
+		t.Errorf("Expected [1] within radius 5, got %v", got)
+	}
+	got = collect(func(fn func(e izone.IEntity)) { g.QueryRadius(pb.NewVector(0, 0, 0), 30, fn) })
+	if !slices.Equal(got, []uid.Uid{3, 1, 2, 4}) {
+		t.Errorf("Expected insertion order [3 1 2 4], got %v", got)
+	}
+	if g.Len() != 5 {
+		t.Errorf("Expected 5 entities, got %d", g.Len())
+	}
+}
+
+func TestGrid_UpdateAndRemove(t *testing.T) {
+	g := NewGrid(10)
+	e := newEntity(1, 0, 0)
+	g.Add(e)
+	origin := pb.NewVector(0, 0, 0)
+
+	// 跨格移动后旧位置查不到
+	e.SetPos(pb.NewVector(95, -42, 0))
+	g.Update(e)
+	if got := collect(func(fn func(e izone.IEntity)) { g.QueryRadius(origin, 5, fn) }); len(got) != 0 {
+		t.Errorf("Expected no entity at old position, got %v", got)
+	}
+	if got := collect(func(fn func(e izone.IEntity)) { g.QueryRadius(pb.NewVector(95, -42, 0), 1, fn) }); !slices.Equal(got, []uid.Uid{1}) {
+		t.Errorf("Expected entity at new position, got %v", got)
+	}
+
+	// 位置清空后不参与查询，恢复后重新加入
+	e.SetPos(nil)
+	g.Update(e)
+	if len(g.cells) != 0 {
+		t.Errorf("Expected empty cells to be released, got %d", len(g.cells))
+	}
+	e.SetPos(pb.NewVector(1, 1, 0))
+	g.Update(e)
+	if got := collect(func(fn func(e izone.IEntity)) { g.QueryRadius(origin, 2, fn) }); !slices.Equal(got, []uid.Uid{1}) {
+		t.Errorf("Expected entity after position restored, got %v", got)
+	}
+
+	g.Remove(1)
+	if got := collect(func(fn func(e izone.IEntity)) { g.QueryRadius(origin, 100, fn) }); len(got) != 0 || g.Len() != 0 {
+		t.Errorf("Expected empty grid after remove, got %v", got)
+	}
+	g.Update(e) // 已移除的实体不会被重新加入
+	if g.Len() != 0 {
+		t.Error("Expected Update to ignore removed entity")
+	}
+}
+
+func TestGrid_QueryBox(t *testing.T) {
+	g := NewGrid(4)
+	g.Add(newEntity(1, 0, 0))
+	g.Add(newEntity(2, 10, 5))
+	g.Add(newEntity(3, 10.01, 5))
+	g.Add(newEntity(4, -3, 2))
+
+	got := collect(func(fn func(e izone.IEntity)) { g.QueryBox(pb.NewVector(-3, 0, 0), pb.NewVector(10, 5, 0), fn) })
+	if !slices.Equal(got, []uid.Uid{1, 2, 4}) {
+		t.Errorf("Expected [1 2 4] inside box, got %v", got)
+	}
+	if got := collect(func(fn func(e izone.IEntity)) { g.QueryBox(pb.NewVector(5, 5, 0), pb.NewVector(0, 0, 0), fn) }); len(got) != 0 {
+		t.Errorf("Expected inverted box to be empty, got %v", got)
+	}
+}
+
+func TestGrid_QueryCone(t *testing.T) {
+	g := NewGrid(10)
+	g.Add(newEntity(1, 0, 0))   // 顶点
+	g.Add(newEntity(2, 8, 0))   // 正前方
+	g.Add(newEntity(3, 5, 5))   // 45 度边界
+	g.Add(newEntity(4, 5, 5.1)) // 超出角度
+	g.Add(newEntity(5, -5, 0))  // 后方
+	g.Add(newEntity(6, 10.1, 0))
+
+	got := collect(func(fn func(e izone.IEntity)) { g.QueryCone(pb.NewVector(0, 0, 0), pb.NewVector(1, 0, 0), 10, 90, fn) })
+	if !slices.Equal(got, []uid.Uid{1, 2, 3}) {
+		t.Errorf("Expected [1 2 3] inside cone, got %v", got)
+	}
+	got = collect(func(fn func(e izone.IEntity)) { g.QueryCone(pb.NewVector(0, 0, 0), pb.NewVector(-1, 0, 0), 10, 90, fn) })
+	if !slices.Equal(got, []uid.Uid{1, 5}) {
+		t.Errorf("Expected [1 5] inside reversed cone, got %v", got)
+	}
+}
+
+func TestGrid_MatchesLinearScan(t *testing.T) {
+	g := NewGrid(7)
+	var all []*testEntity
+	for i := range 500 {
+		e := newEntity(int64(i+1), float64(i*37%211)-100, float64(i*53%197)-100)
+		all = append(all, e)
+		g.Add(e)
+	}
+
+	for _, q := range []struct{ x, y, r float64 }{{0, 0, 15}, {-80, 60, 40}, {99, -99, 3}, {0, 0, 500}} {
+		center := pb.NewVector(q.x, q.y, 0)
+		var want []uid.Uid
+		for _, e := range all {
+			if e.pos.DistanceSq2D(center) <= q.r*q.r+Epsilon {
+				want = append(want, e.id)
+			}
+		}
+		got := collect(func(fn func(e izone.IEntity)) { g.QueryRadius(center, q.r, fn) })
+		if !slices.Equal(got, want) {
+			t.Errorf("Query %v: expected %d entities, got %d", q, len(want), len(got))
+		}
+	}
+}
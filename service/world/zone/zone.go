@@ -2,24 +2,106 @@ package zone
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"server/data/conf"
 	"server/lib/container"
 	"server/lib/uid"
+	"server/pb"
+	"server/service/world/zone/aoi"
+	"server/service/world/zone/clock"
+	"server/service/world/zone/frame"
 	"server/service/world/zone/izone"
+	"server/service/world/zone/spatial"
 
 	"github.com/gmbytes/snow/routines/node"
 )
 
 var _ izone.IZone = (*Zone)(nil)
 
+// confPath 为技能/Buff 等战斗配表路径（相对于进程工作目录）。
+const confPath = "conf/all.json"
+
 // Zone 基于 snow node.Service 的区服逻辑服务，可被 RPC/HTTP 调用。
 type Zone struct {
 	node.Service
 	entities *container.LMap[uid.Uid, izone.IEntity]
+	grid     *spatial.Grid // 实体空间索引，用于范围查询
+	aoi      *aoi.Manager  // 角色视野管理
+	tables   atomic.Pointer[conf.Tables]
+
+	clock  clock.IClock // 区域逻辑时钟，每帧推进
+	tickHz int          // 帧率，Init 前设置有效
+	loop   *frame.Loop  // 帧循环，由服务定时器在服务协程中驱动
 }
 
 func (ss *Zone) Init() {
 	ss.entities = container.NewLMap[uid.Uid, izone.IEntity]()
+	ss.grid = spatial.NewGrid(spatial.DefaultCellSize)
+	ss.aoi = aoi.NewManager(ss, aoi.DefaultConfig)
+	if ss.clock == nil {
+		ss.clock = clock.New(time.Now().UnixMilli())
+	}
+	ss.loop = frame.NewLoop(ss.tickHz, ss.tick)
+}
+
+// GetAoi 获取视野管理器，由网络层设置 OnSync 将视野变化下发给客户端。
+func (ss *Zone) GetAoi() *aoi.Manager {
+	return ss.aoi
+}
+
+// SetTickHz 设置帧率，需在 Init 前调用，<= 0 时使用 frame.DefaultHz。
+func (ss *Zone) SetTickHz(hz int) {
+	ss.tickHz = hz
+}
+
+// SetClock 设置区域逻辑时钟（如测试时注入 clock.Fake），需在 Init 前调用，未设置时从当前墙钟时间开始计时。
+func (ss *Zone) SetClock(c clock.IClock) {
+	ss.clock = c
+}
+
+// GetLoop 获取帧循环（用于暂停/单步与读取帧耗时统计）。
+func (ss *Zone) GetLoop() *frame.Loop {
+	return ss.loop
+}
+
+// NowMs 获取区域逻辑时间。
+func (ss *Zone) NowMs() int64 {
+	return ss.clock.NowMs()
+}
+
+// tick 执行一帧：推进逻辑时钟，再以相同的 deltaMs 推进所有实体，最后合并下发本帧的视野变化。
+func (ss *Zone) tick(deltaMs int64) {
+	ss.clock.Advance(deltaMs)
+
+	ss.entities.ForEach(func(e izone.IEntity) {
+		e.Update(deltaMs)
+	})
+	ss.aoi.Update()
+}
+
+// startLoop 启动帧循环：服务定时器按帧间隔在服务协程中调用 pollLoop 执行到期的帧，
+// 帧与 RPC 等消息处理串行执行，区域状态无需加锁。
+func (ss *Zone) startLoop() {
+	ss.loop.Start(time.Now())
+	ss.Tick(ss.loop.Interval(), ss.pollLoop)
+	ss.Infof("zone tick loop started: %v per frame", ss.loop.Interval())
+}
+
+func (ss *Zone) pollLoop() {
+	ss.loop.Poll(time.Now())
+}
+
+// stopLoop 停止帧循环并输出运行统计。
+func (ss *Zone) stopLoop() {
+	if ss.loop == nil || !ss.loop.Running() {
+		return
+	}
+	ss.loop.Stop()
+	stats := ss.loop.Stats()
+	ss.Infof("zone tick loop stopped: %d frames, %d overruns, %d dropped, max cost %v",
+		stats.Frames, stats.Overruns, stats.Dropped, stats.MaxCost)
 }
 
 func (ss *Zone) AddEntity(e izone.IEntity) {
@@ -27,10 +109,16 @@ func (ss *Zone) AddEntity(e izone.IEntity) {
 		return
 	}
 	ss.entities.Set(e.GetId(), e)
+	ss.grid.Add(e)
+	if aoi.IsWatcher(e) {
+		ss.aoi.AddWatcher(e)
+	}
 }
 
 func (ss *Zone) RemoveEntity(id uid.Uid) {
 	ss.entities.Delete(id)
+	ss.grid.Remove(id)
+	ss.aoi.RemoveWatcher(id)
 }
 
 func (ss *Zone) GetEntity(id uid.Uid) (izone.IEntity, bool) {
@@ -44,17 +132,68 @@ func (ss *Zone) ForEach(fn func(e izone.IEntity)) {
 	ss.entities.ForEach(fn)
 }
 
+func (ss *Zone) UpdateEntityPos(e izone.IEntity) {
+	ss.grid.Update(e)
+}
+
+func (ss *Zone) QueryRadius(center *pb.Vector, radius float64, fn func(e izone.IEntity)) {
+	ss.grid.QueryRadius(center, radius, fn)
+}
+
+func (ss *Zone) QueryBox(lo, hi *pb.Vector, fn func(e izone.IEntity)) {
+	ss.grid.QueryBox(lo, hi, fn)
+}
+
+func (ss *Zone) QueryCone(center, facing *pb.Vector, radius, angle float64, fn func(e izone.IEntity)) {
+	ss.grid.QueryCone(center, facing, radius, angle, fn)
+}
+
+// GetTables 返回当前生效的配置快照。
+// 快照只读；热更时整体替换指针，已持有旧快照的逻辑不受影响。
+func (ss *Zone) GetTables() *conf.Tables {
+	return ss.tables.Load()
+}
+
+// loadTables 加载并校验战斗配表，失败时返回错误（校验失败为 conf.ValidationErrors）。
+func (ss *Zone) loadTables() error {
+	tables, err := conf.LoadTables(confPath)
+	if err != nil {
+		return err
+	}
+	ss.tables.Store(tables)
+	ss.Infof("zone config loaded: %d skills, %d buffs", len(tables.Skills), len(tables.Buffs))
+	return nil
+}
+
+// ReloadTables 热更配表：新配置校验通过后原子替换，返回与旧配置的差异摘要。
+// 校验失败时保留旧配置。新施放的技能使用新配置，施法中的技能与运行中的效果沿用旧配置直至结束。
+func (ss *Zone) ReloadTables() (*conf.TablesDiff, error) {
+	tables, err := conf.LoadTables(confPath)
+	if err != nil {
+		return nil, err
+	}
+	prev := ss.tables.Swap(tables)
+	return conf.Diff(prev, tables), nil
+}
+
 // Start 服务启动时调用，启用 RPC 后开始处理请求。
 func (ss *Zone) Start(_ any) {
 	ss.Init()
 	ss.Infof("zone service starting")
+	// 配表存在任何问题都不启动区域服务（不启用 RPC、不启动帧循环），避免带着错误数据运行
+	if err := ss.loadTables(); err != nil {
+		ss.Errorf("zone config load failed, zone service not started: %v", err)
+		return
+	}
 	ss.EnableRpc()
+	ss.startLoop()
 	ss.Infof("zone service started")
 }
 
 // Stop 服务关闭时调用，与消息处理同线程。
 func (ss *Zone) Stop(_ *sync.WaitGroup) {
 	ss.Infof("zone service stopping")
+	ss.stopLoop()
 }
 
 // AfterStop 服务完全关闭后调用，此时不再处理任何消息。
@@ -62,6 +201,18 @@ func (ss *Zone) AfterStop() {
 	ss.Infof("zone service stopped")
 }
 
+// RpcReloadConfig 热更战斗配表，返回差异摘要或失败原因。
+func (ss *Zone) RpcReloadConfig(ctx node.IRpcContext) {
+	diff, err := ss.ReloadTables()
+	if err != nil {
+		ss.Errorf("zone config reload failed: %v", err)
+		ctx.Return("Zone.ReloadFailed: " + err.Error())
+		return
+	}
+	ss.Infof("zone config reloaded: %s", diff)
+	ctx.Return("Zone.Reloaded: " + diff.String())
+}
+
 // RpcStatus 可选：覆写默认状态 RPC，用于健康检查。
 func (ss *Zone) RpcStatus(ctx node.IRpcContext) {
 	ctx.Return("Zone.OK")
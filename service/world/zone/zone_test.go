@@ -0,0 +1,105 @@
+package zone
+
+import (
+	"testing"
+	"time"
+
+	"server/data"
+	"server/data/enum"
+	"server/lib/uid"
+	"server/pb"
+	"server/service/world/zone/clock"
+	"server/service/world/zone/izone"
+)
+
+// countEntity 记录 Update 调用的测试实体。
+type countEntity struct {
+	id      uid.Uid
+	updates []int64
+}
+
+func (e *countEntity) Init(izone.IZone, data.EntityInitData)    {}
+func (e *countEntity) Update(duration int64)                    { e.updates = append(e.updates, duration) }
+func (e *countEntity) GetZone() izone.IZone                     { return nil }
+func (e *countEntity) GetType() enum.EntityType                 { return enum.EntityType_Npc }
+func (e *countEntity) GetId() uid.Uid                           { return e.id }
+func (e *countEntity) GetPos() *pb.Vector                       { return nil }
+func (e *countEntity) SetPos(*pb.Vector)                        {}
+func (e *countEntity) GetDir() int32                            { return 0 }
+func (e *countEntity) SetDir(int32)                             {}
+func (e *countEntity) GetModule(izone.ModuleType) izone.IModule { return nil }
+
+func TestZone_Tick(t *testing.T) {
+	z := &Zone{}
+	z.SetTickHz(10)
+	z.Init()
+	a := &countEntity{id: 1}
+	z.AddEntity(a)
+	if _, ok := z.GetEntity(a.id); !ok {
+		t.Fatal("Expected entity added immediately")
+	}
+
+	start := z.NowMs()
+	z.GetLoop().Step(2)
+	if z.NowMs()-start != 200 {
+		t.Errorf("Expected clock to advance 200ms, got %d", z.NowMs()-start)
+	}
+	if len(a.updates) != 2 || a.updates[0] != 100 || a.updates[1] != 100 {
+		t.Errorf("Expected two 100ms updates, got %v", a.updates)
+	}
+	if s := z.GetLoop().Stats(); s.Frames != 2 {
+		t.Errorf("Expected 2 frames, got %d", s.Frames)
+	}
+}
+
+func TestZone_FakeClock(t *testing.T) {
+	fake := clock.NewFake(1000)
+	z := &Zone{}
+	z.SetClock(fake)
+	z.SetTickHz(10)
+	z.Init()
+
+	z.GetLoop().Step(1)
+	if z.NowMs() != 1100 {
+		t.Errorf("Expected injected clock advanced by the loop, got %d", z.NowMs())
+	}
+
+	fake.Set(5000)
+	z.GetLoop().Step(1)
+	if z.NowMs() != 5100 {
+		t.Errorf("Expected zone time to follow the fake clock, got %d", z.NowMs())
+	}
+}
+
+func TestZone_StartLoop(t *testing.T) {
+	z := &Zone{}
+	z.SetTickHz(10)
+	z.Init()
+	a := &countEntity{id: 1}
+	z.AddEntity(a)
+
+	z.startLoop()
+	if !z.GetLoop().Running() || z.GetLoop().Step(1) {
+		t.Fatal("Expected loop running and Step rejected")
+	}
+	// 服务定时器到期时执行到期的帧
+	z.GetLoop().Poll(time.Now().Add(250 * time.Millisecond))
+	if len(a.updates) != 2 {
+		t.Errorf("Expected 2 frames after 250ms, got %v", a.updates)
+	}
+
+	z.RemoveEntity(a.id)
+	z.stopLoop()
+	if z.GetLoop().Running() || !z.GetLoop().Step(1) || len(a.updates) != 2 {
+		t.Errorf("Expected loop stopped and removed entity not updated, got %v", a.updates)
+	}
+}
+
+func TestZone_StartRefusesBadConfig(t *testing.T) {
+	// 测试工作目录下没有 conf/all.json，加载失败时不启动服务也不 panic
+	z := &Zone{}
+	z.Start(nil)
+	if z.GetTables() != nil || z.GetLoop().Running() {
+		t.Error("Expected zone service left unstarted on config error")
+	}
+}